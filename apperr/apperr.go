@@ -0,0 +1,73 @@
+// Package apperr is the application's error model. An *Error carries the
+// HTTP status, a stable machine-readable code and per-field details for the
+// response, and the underlying cause for the logs. FromDB translates
+// PostgreSQL constraint violations into errors the client can act on.
+package apperr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// Error is an error with the response it should produce
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details []models.FieldError
+	Err     error // the cause, logged but never sent to the client
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error with a status, code and message
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// NotFound is a 404 error
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, "not_found", message)
+}
+
+// Conflict is a 409 error with a specific code
+func Conflict(code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+// Invalid is a 422 error listing the fields that were rejected
+func Invalid(message string, details ...models.FieldError) *Error {
+	return &Error{Status: http.StatusUnprocessableEntity, Code: "validation_failed", Message: message, Details: details}
+}
+
+// Internal is a 500 error hiding its cause from the client
+func Internal(message string, cause error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: "internal_server_error", Message: message, Err: cause}
+}
+
+// WithField adds a detail about one field
+func (e *Error) WithField(field, code, message string) *Error {
+	e.Details = append(e.Details, models.FieldError{Field: field, Code: code, Message: message})
+	return e
+}
+
+// From returns err as an *Error: itself if it is or wraps one, its
+// translation if it is a constraint violation, and nil otherwise
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return FromDB(err)
+}
@@ -0,0 +1,137 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// PostgreSQL error codes for the constraint violations a client can cause
+const (
+	notNullViolation    = "23502"
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+// constraint is how the violation of a named constraint is reported
+type constraint struct {
+	field   string
+	code    string
+	message string
+}
+
+// constraints gives friendlier messages and codes for the violations clients
+// run into most. Constraints that aren't listed get a generic code per kind
+// of violation.
+var constraints = map[string]constraint{
+	"users_email_key":                          {"email", "email_taken", "Email already registered"},
+	"users_student_id_key":                     {"student_id", "student_id_taken", "Student ID already registered"},
+	"clubs_club_name_key":                      {"club_name", "club_name_taken", "A club with this name already exists"},
+	"clubs_club_code_key":                      {"club_code", "club_code_taken", "A club with this code already exists"},
+	"club_members_club_id_fkey":                {"club_id", "club_not_found", "Club not found"},
+	"club_members_user_id_club_id_key":         {"club_id", "already_member", "Already a member of this club"},
+	"club_moderators_user_id_club_id_key":      {"user_id", "already_moderator", "The user already moderates this club"},
+	"club_moderators_user_id_fkey":             {"user_id", "user_not_found", "User not found"},
+	"events_club_id_fkey":                      {"club_id", "club_not_found", "Club not found"},
+	"news_club_id_fkey":                        {"club_id", "club_not_found", "Club not found"},
+	"event_feedback_event_id_fkey":             {"event_id", "event_not_found", "Event not found"},
+	"event_registrations_event_id_user_id_key": {"event_id", "already_registered", "Already registered for this event"},
+	"event_feedback_rating_check":              {"rating", "invalid_rating", "Rating must be between 1 and 5"},
+	"events_capacity_check":                    {"capacity", "invalid_capacity", "Capacity can't be negative"},
+	"event_gallery_event_id_fkey":              {"event_id", "event_not_found", "Event not found"},
+	"news_media_news_id_fkey":                  {"news_id", "news_not_found", "News post not found"},
+	"users_role_check":                         {"role", "invalid_role", "Role must be student, club_moderator or system_admin"},
+}
+
+// keyPattern picks the columns out of a violation's detail, which reads like
+// `Key (event_id, user_id)=(1, 2) already exists.`
+var keyPattern = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// FromDB translates a PostgreSQL constraint violation into an *Error:
+// duplicates are 409 conflicts, references to missing rows are 404s, rows
+// that are still referenced are 409s, and values rejected by a check or not
+// null constraint are 422s. It returns nil for any other error.
+func FromDB(err error) *Error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return nil
+	}
+
+	var field string
+	switch pqErr.Code {
+	case checkViolation:
+		field = checkField(pqErr)
+	case notNullViolation:
+		field = pqErr.Column
+	default:
+		field = keyField(pqErr)
+	}
+
+	var appErr *Error
+	stillReferenced := false
+	switch pqErr.Code {
+	case uniqueViolation:
+		appErr = New(http.StatusConflict, "already_exists", "A record with this "+orValue(field, "value")+" already exists")
+	case foreignKeyViolation:
+		if strings.Contains(pqErr.Detail, "is still referenced") {
+			stillReferenced = true
+			appErr = New(http.StatusConflict, "still_referenced", "The record is still in use")
+		} else {
+			appErr = New(http.StatusNotFound, "reference_not_found", "The referenced "+orValue(field, "record")+" does not exist")
+		}
+	case checkViolation:
+		appErr = New(http.StatusUnprocessableEntity, "invalid_value", orValue(field, "A value")+" is not allowed")
+	case notNullViolation:
+		appErr = New(http.StatusUnprocessableEntity, "missing_value", orValue(field, "A value")+" is required")
+	default:
+		return nil
+	}
+	appErr.Err = err
+
+	// The listed foreign keys describe a missing reference, not a delete of
+	// the row they point to
+	if known, ok := constraints[pqErr.Constraint]; ok && !stillReferenced {
+		appErr.Code = known.code
+		appErr.Message = known.message
+		field = known.field
+	}
+	if field != "" {
+		appErr.Details = []models.FieldError{{Field: field, Code: appErr.Code, Message: appErr.Message}}
+	}
+
+	return appErr
+}
+
+// keyField returns the column named in a unique or foreign key violation,
+// or the first one for a key over several columns
+func keyField(pqErr *pq.Error) string {
+	match := keyPattern.FindStringSubmatch(pqErr.Detail)
+	if match == nil {
+		return ""
+	}
+	columns := strings.Split(match[1], ", ")
+	return columns[0]
+}
+
+// checkField guesses the column of a check constraint from PostgreSQL's
+// default naming, <table>_<column>_check
+func checkField(pqErr *pq.Error) string {
+	name := strings.TrimSuffix(pqErr.Constraint, "_check")
+	if name == pqErr.Constraint || pqErr.Table == "" || !strings.HasPrefix(name, pqErr.Table+"_") {
+		return ""
+	}
+	return strings.TrimPrefix(name, pqErr.Table+"_")
+}
+
+// orValue returns s, or fallback when s is empty
+func orValue(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,163 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestFromDB(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+		field   string
+	}{
+		{
+			name:    "known unique constraint",
+			err:     &pq.Error{Code: uniqueViolation, Constraint: "users_email_key", Detail: "Key (email)=(a@b.c) already exists."},
+			status:  http.StatusConflict,
+			code:    "email_taken",
+			message: "Email already registered",
+			field:   "email",
+		},
+		{
+			name:    "unknown unique constraint names the first key column",
+			err:     &pq.Error{Code: uniqueViolation, Constraint: "widgets_a_b_key", Detail: "Key (a, b)=(1, 2) already exists."},
+			status:  http.StatusConflict,
+			code:    "already_exists",
+			message: "A record with this a already exists",
+			field:   "a",
+		},
+		{
+			name:    "unique constraint without a detail",
+			err:     &pq.Error{Code: uniqueViolation, Constraint: "widgets_a_key"},
+			status:  http.StatusConflict,
+			code:    "already_exists",
+			message: "A record with this value already exists",
+		},
+		{
+			name:    "known foreign key",
+			err:     &pq.Error{Code: foreignKeyViolation, Constraint: "events_club_id_fkey", Detail: `Key (club_id)=(9) is not present in table "clubs".`},
+			status:  http.StatusNotFound,
+			code:    "club_not_found",
+			message: "Club not found",
+			field:   "club_id",
+		},
+		{
+			name:    "unknown foreign key",
+			err:     &pq.Error{Code: foreignKeyViolation, Constraint: "widgets_owner_id_fkey", Detail: `Key (owner_id)=(9) is not present in table "users".`},
+			status:  http.StatusNotFound,
+			code:    "reference_not_found",
+			message: "The referenced owner_id does not exist",
+			field:   "owner_id",
+		},
+		{
+			name:    "row still referenced through a listed foreign key",
+			err:     &pq.Error{Code: foreignKeyViolation, Constraint: "events_club_id_fkey", Detail: `Key (club_id)=(1) is still referenced from table "events".`},
+			status:  http.StatusConflict,
+			code:    "still_referenced",
+			message: "The record is still in use",
+			field:   "club_id",
+		},
+		{
+			name:    "row still referenced",
+			err:     &pq.Error{Code: foreignKeyViolation, Constraint: "widgets_club_id_fkey", Detail: `Key (club_id)=(1) is still referenced from table "widgets".`},
+			status:  http.StatusConflict,
+			code:    "still_referenced",
+			message: "The record is still in use",
+			field:   "club_id",
+		},
+		{
+			name:    "known check constraint",
+			err:     &pq.Error{Code: checkViolation, Constraint: "event_feedback_rating_check", Table: "event_feedback"},
+			status:  http.StatusUnprocessableEntity,
+			code:    "invalid_rating",
+			message: "Rating must be between 1 and 5",
+			field:   "rating",
+		},
+		{
+			name:    "check constraint with the default name",
+			err:     &pq.Error{Code: checkViolation, Constraint: "widgets_size_check", Table: "widgets"},
+			status:  http.StatusUnprocessableEntity,
+			code:    "invalid_value",
+			message: "size is not allowed",
+			field:   "size",
+		},
+		{
+			name:    "check constraint with a custom name",
+			err:     &pq.Error{Code: checkViolation, Constraint: "sane_dates", Table: "widgets"},
+			status:  http.StatusUnprocessableEntity,
+			code:    "invalid_value",
+			message: "A value is not allowed",
+		},
+		{
+			name:    "not null",
+			err:     &pq.Error{Code: notNullViolation, Column: "title", Table: "widgets"},
+			status:  http.StatusUnprocessableEntity,
+			code:    "missing_value",
+			message: "title is required",
+			field:   "title",
+		},
+		{
+			name:    "wrapped violation",
+			err:     fmt.Errorf("insert widget: %w", &pq.Error{Code: uniqueViolation, Constraint: "clubs_club_code_key"}),
+			status:  http.StatusConflict,
+			code:    "club_code_taken",
+			message: "A club with this code already exists",
+			field:   "club_code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromDB(tt.err)
+			if got == nil {
+				t.Fatal("FromDB returned nil")
+			}
+			if got.Status != tt.status || got.Code != tt.code || got.Message != tt.message {
+				t.Errorf("got %d %s %q, want %d %s %q", got.Status, got.Code, got.Message, tt.status, tt.code, tt.message)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("the cause was dropped")
+			}
+
+			if tt.field == "" {
+				if len(got.Details) != 0 {
+					t.Errorf("got details %+v, want none", got.Details)
+				}
+				return
+			}
+			if len(got.Details) != 1 {
+				t.Fatalf("got details %+v, want one for %s", got.Details, tt.field)
+			}
+			if detail := got.Details[0]; detail.Field != tt.field || detail.Code != tt.code {
+				t.Errorf("got detail %s %s, want %s %s", detail.Field, detail.Code, tt.field, tt.code)
+			}
+		})
+	}
+}
+
+func TestFromDBIgnoresOtherErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"nil", nil},
+		{"not a database error", errors.New("connection refused")},
+		{"other database error", &pq.Error{Code: "40001", Message: "could not serialize access"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromDB(tt.err); got != nil {
+				t.Errorf("got %+v, want nil", got)
+			}
+		})
+	}
+}
@@ -3,17 +3,45 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DatabaseURL   string
-	Port          string
-	GinMode       string
-	JWTSecret     string
-	JWTExpiration time.Duration
+	DatabaseURL            string
+	Port                   string
+	GinMode                string
+	LogLevel               string
+	LogFormat              string
+	JWTSecret              string
+	JWTExpiration          time.Duration
+	RefreshTokenExpiration time.Duration
+	AppBaseURL             string
+	APIBaseURL             string
+	AllowedEmailDomains    []string
+	MailDriver             string
+	MailFrom               string
+	MailLogFile            string
+	SMTPHost               string
+	SMTPPort               string
+	SMTPUsername           string
+	SMTPPassword           string
+	RealtimeBackend        string
+	SchedulerEnabled       bool
+	StorageDriver          string
+	StorageLocalPath       string
+	MediaBaseURL           string
+	MaxImageUploadBytes    int64
+	MaxVideoUploadBytes    int64
+	S3Endpoint             string
+	S3Region               string
+	S3Bucket               string
+	S3AccessKeyID          string
+	S3SecretAccessKey      string
+	S3PathStyle            bool
 }
 
 var AppConfig *Config
@@ -22,22 +50,59 @@ func LoadConfig() error {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
+	// Access tokens are short-lived; clients renew them with a refresh token
 	jwtExp := os.Getenv("JWT_EXPIRATION")
 	if jwtExp == "" {
-		jwtExp = "24h"
+		jwtExp = "15m"
 	}
 
 	duration, err := time.ParseDuration(jwtExp)
 	if err != nil {
-		duration = 24 * time.Hour
+		duration = 15 * time.Minute
+	}
+
+	refreshExp := os.Getenv("REFRESH_TOKEN_EXPIRATION")
+	if refreshExp == "" {
+		refreshExp = "720h"
+	}
+
+	refreshDuration, err := time.ParseDuration(refreshExp)
+	if err != nil {
+		refreshDuration = 30 * 24 * time.Hour
 	}
 
 	AppConfig = &Config{
-		DatabaseURL:   os.Getenv("DATABASE_URL"),
-		Port:          os.Getenv("PORT"),
-		GinMode:       os.Getenv("GIN_MODE"),
-		JWTSecret:     os.Getenv("JWT_SECRET"),
-		JWTExpiration: duration,
+		DatabaseURL:            os.Getenv("DATABASE_URL"),
+		Port:                   os.Getenv("PORT"),
+		GinMode:                os.Getenv("GIN_MODE"),
+		LogLevel:               os.Getenv("LOG_LEVEL"),
+		LogFormat:              os.Getenv("LOG_FORMAT"),
+		JWTSecret:              os.Getenv("JWT_SECRET"),
+		JWTExpiration:          duration,
+		RefreshTokenExpiration: refreshDuration,
+		AppBaseURL:             os.Getenv("APP_BASE_URL"),
+		APIBaseURL:             os.Getenv("API_BASE_URL"),
+		AllowedEmailDomains:    splitList(os.Getenv("ALLOWED_EMAIL_DOMAINS")),
+		MailDriver:             os.Getenv("MAIL_DRIVER"),
+		MailFrom:               os.Getenv("MAIL_FROM"),
+		MailLogFile:            os.Getenv("MAIL_LOG_FILE"),
+		SMTPHost:               os.Getenv("SMTP_HOST"),
+		SMTPPort:               os.Getenv("SMTP_PORT"),
+		SMTPUsername:           os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:           os.Getenv("SMTP_PASSWORD"),
+		RealtimeBackend:        os.Getenv("REALTIME_BACKEND"),
+		SchedulerEnabled:       os.Getenv("SCHEDULER_ENABLED") != "false",
+		StorageDriver:          os.Getenv("STORAGE_DRIVER"),
+		StorageLocalPath:       os.Getenv("STORAGE_LOCAL_PATH"),
+		MediaBaseURL:           os.Getenv("MEDIA_BASE_URL"),
+		MaxImageUploadBytes:    megabytes(os.Getenv("MAX_IMAGE_UPLOAD_MB"), 10),
+		MaxVideoUploadBytes:    megabytes(os.Getenv("MAX_VIDEO_UPLOAD_MB"), 100),
+		S3Endpoint:             os.Getenv("S3_ENDPOINT"),
+		S3Region:               os.Getenv("S3_REGION"),
+		S3Bucket:               os.Getenv("S3_BUCKET"),
+		S3AccessKeyID:          os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey:      os.Getenv("S3_SECRET_ACCESS_KEY"),
+		S3PathStyle:            os.Getenv("S3_PATH_STYLE") == "true",
 	}
 
 	if AppConfig.Port == "" {
@@ -48,9 +113,74 @@ func LoadConfig() error {
 		AppConfig.GinMode = "debug"
 	}
 
+	if AppConfig.LogLevel == "" {
+		AppConfig.LogLevel = "info"
+	}
+
+	// Production log collectors expect one JSON object per line
+	if AppConfig.LogFormat == "" {
+		AppConfig.LogFormat = "text"
+		if AppConfig.GinMode == "release" {
+			AppConfig.LogFormat = "json"
+		}
+	}
+
+	if AppConfig.AppBaseURL == "" {
+		AppConfig.AppBaseURL = "http://localhost:3000"
+	}
+
+	if AppConfig.MailDriver == "" {
+		AppConfig.MailDriver = "log"
+	}
+
+	if AppConfig.MailFrom == "" {
+		AppConfig.MailFrom = "NUB Clubs Connect <no-reply@localhost>"
+	}
+
+	if AppConfig.SMTPPort == "" {
+		AppConfig.SMTPPort = "587"
+	}
+
+	if AppConfig.RealtimeBackend == "" {
+		AppConfig.RealtimeBackend = "memory"
+	}
+
+	if AppConfig.StorageDriver == "" {
+		AppConfig.StorageDriver = "local"
+	}
+
+	if AppConfig.StorageLocalPath == "" {
+		AppConfig.StorageLocalPath = "./uploads"
+	}
+
+	if AppConfig.S3Region == "" {
+		AppConfig.S3Region = "us-east-1"
+	}
+
 	if AppConfig.JWTSecret == "" {
 		return fmt.Errorf("JWT_SECRET environment variable not set")
 	}
 
 	return nil
 }
+
+// splitList parses a comma-separated environment value, dropping blanks
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// megabytes parses a size in megabytes, falling back to def when unset or invalid
+func megabytes(value string, def int64) int64 {
+	mb, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || mb <= 0 {
+		mb = def
+	}
+	return mb << 20
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"log/slog"
 
 	_ "github.com/lib/pq"
 	"github.com/nub-clubs-connect/nub_admin_api/config"
@@ -10,7 +11,31 @@ import (
 
 var DB *sql.DB
 
+// Execer is satisfied by both *sql.DB and *sql.Tx so helpers can run
+// inside or outside a transaction
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Init connects to the database and applies any pending migrations
 func Init() error {
+	if err := Connect(); err != nil {
+		return err
+	}
+
+	applied, err := MigrateUp()
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	if applied == 0 {
+		slog.Info("Database schema is up to date")
+	}
+	return nil
+}
+
+// Connect opens the connection pool without touching the schema
+func Connect() error {
 	var err error
 	DB, err = sql.Open("postgres", config.AppConfig.DatabaseURL)
 	if err != nil {
@@ -27,7 +52,7 @@ func Init() error {
 	DB.SetMaxOpenConns(25)
 	DB.SetMaxIdleConns(5)
 
-	fmt.Println("✓ Database connection established successfully")
+	slog.Info("Database connection established")
 	return nil
 }
 
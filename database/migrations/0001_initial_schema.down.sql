@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS activity_log;
+DROP TABLE IF EXISTS system_announcements;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS news_media;
+DROP TABLE IF EXISTS news;
+DROP TABLE IF EXISTS event_gallery;
+DROP TABLE IF EXISTS event_feedback;
+DROP TABLE IF EXISTS event_registrations;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS club_moderators;
+DROP TABLE IF EXISTS club_members;
+DROP TABLE IF EXISTS clubs;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,196 @@
+-- Initial schema for NUB Clubs Connect
+
+CREATE TABLE users (
+    user_id             SERIAL PRIMARY KEY,
+    student_id          VARCHAR(50)  NOT NULL UNIQUE,
+    email               VARCHAR(255) NOT NULL UNIQUE,
+    password_hash       VARCHAR(255) NOT NULL,
+    first_name          VARCHAR(100) NOT NULL,
+    last_name           VARCHAR(100) NOT NULL DEFAULT '',
+    role                VARCHAR(20)  NOT NULL DEFAULT 'student'
+                        CHECK (role IN ('student', 'club_moderator', 'system_admin')),
+    phone               VARCHAR(30)  NOT NULL DEFAULT '',
+    profile_picture_url TEXT         NOT NULL DEFAULT '',
+    is_active           BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_users_role ON users (role);
+
+CREATE TABLE clubs (
+    club_id         SERIAL PRIMARY KEY,
+    club_name       VARCHAR(150) NOT NULL UNIQUE,
+    club_code       VARCHAR(20)  NOT NULL UNIQUE,
+    description     TEXT         NOT NULL DEFAULT '',
+    logo_url        TEXT         NOT NULL DEFAULT '',
+    cover_image_url TEXT         NOT NULL DEFAULT '',
+    founded_date    DATE,
+    email           VARCHAR(255) NOT NULL DEFAULT '',
+    is_active       BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE club_members (
+    membership_id SERIAL PRIMARY KEY,
+    user_id       INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    club_id       INTEGER     NOT NULL REFERENCES clubs (club_id) ON DELETE CASCADE,
+    role          VARCHAR(50) NOT NULL DEFAULT 'member',
+    joined_date   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    is_active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    UNIQUE (user_id, club_id)
+);
+
+CREATE INDEX idx_club_members_club ON club_members (club_id) WHERE is_active;
+
+CREATE TABLE club_moderators (
+    moderator_id SERIAL PRIMARY KEY,
+    user_id      INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    club_id      INTEGER     NOT NULL REFERENCES clubs (club_id) ON DELETE CASCADE,
+    assigned_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, club_id)
+);
+
+CREATE INDEX idx_club_moderators_club ON club_moderators (club_id);
+
+CREATE TABLE events (
+    event_id              SERIAL PRIMARY KEY,
+    club_id               INTEGER      NOT NULL REFERENCES clubs (club_id) ON DELETE CASCADE,
+    created_by            INTEGER      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    title                 VARCHAR(255) NOT NULL,
+    description           TEXT         NOT NULL DEFAULT '',
+    event_type            VARCHAR(50)  NOT NULL DEFAULT '',
+    location              VARCHAR(255) NOT NULL DEFAULT '',
+    start_datetime        TIMESTAMPTZ  NOT NULL,
+    end_datetime          TIMESTAMPTZ  NOT NULL,
+    registration_deadline TIMESTAMPTZ,
+    capacity              INTEGER      NOT NULL DEFAULT 0 CHECK (capacity >= 0),
+    is_registration_open  BOOLEAN      NOT NULL DEFAULT TRUE,
+    status                VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                          CHECK (status IN ('pending', 'approved', 'rejected', 'completed', 'cancelled')),
+    banner_image_url      TEXT         NOT NULL DEFAULT '',
+    created_at            TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_events_club ON events (club_id);
+CREATE INDEX idx_events_status_start ON events (status, start_datetime);
+
+CREATE TABLE event_registrations (
+    registration_id     SERIAL PRIMARY KEY,
+    event_id            INTEGER     NOT NULL REFERENCES events (event_id) ON DELETE CASCADE,
+    user_id             INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    registration_status VARCHAR(20) NOT NULL DEFAULT 'confirmed'
+                        CHECK (registration_status IN ('confirmed', 'waitlist', 'cancelled', 'attended')),
+    registration_date   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attendance_marked   BOOLEAN     NOT NULL DEFAULT FALSE,
+    feedback_submitted  BOOLEAN     NOT NULL DEFAULT FALSE,
+    UNIQUE (event_id, user_id)
+);
+
+CREATE INDEX idx_event_registrations_user ON event_registrations (user_id);
+CREATE INDEX idx_event_registrations_event_status ON event_registrations (event_id, registration_status);
+
+CREATE TABLE event_feedback (
+    feedback_id  SERIAL PRIMARY KEY,
+    event_id     INTEGER     NOT NULL REFERENCES events (event_id) ON DELETE CASCADE,
+    user_id      INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    rating       INTEGER     NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment      TEXT        NOT NULL DEFAULT '',
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, user_id)
+);
+
+CREATE TABLE event_gallery (
+    gallery_id  SERIAL PRIMARY KEY,
+    event_id    INTEGER     NOT NULL REFERENCES events (event_id) ON DELETE CASCADE,
+    uploaded_by INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    image_url   TEXT        NOT NULL,
+    caption     TEXT        NOT NULL DEFAULT '',
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_event_gallery_event ON event_gallery (event_id);
+
+CREATE TABLE news (
+    news_id      SERIAL PRIMARY KEY,
+    club_id      INTEGER      NOT NULL REFERENCES clubs (club_id) ON DELETE CASCADE,
+    created_by   INTEGER      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    title        VARCHAR(255) NOT NULL,
+    content      TEXT         NOT NULL,
+    category     VARCHAR(50)  NOT NULL DEFAULT '',
+    is_featured  BOOLEAN      NOT NULL DEFAULT FALSE,
+    status       VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                 CHECK (status IN ('pending', 'rejected', 'published')),
+    published_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_news_club ON news (club_id);
+CREATE INDEX idx_news_status_published ON news (status, published_at DESC);
+
+CREATE TABLE news_media (
+    media_id      SERIAL PRIMARY KEY,
+    news_id       INTEGER     NOT NULL REFERENCES news (news_id) ON DELETE CASCADE,
+    media_type    VARCHAR(20) NOT NULL CHECK (media_type IN ('image', 'video')),
+    media_url     TEXT        NOT NULL,
+    caption       TEXT        NOT NULL DEFAULT '',
+    display_order INTEGER     NOT NULL DEFAULT 0,
+    uploaded_by   INTEGER     REFERENCES users (user_id) ON DELETE SET NULL,
+    uploaded_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_news_media_news ON news_media (news_id, display_order);
+
+CREATE TABLE notifications (
+    notification_id     SERIAL PRIMARY KEY,
+    user_id             INTEGER      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    title               VARCHAR(255) NOT NULL,
+    message             TEXT         NOT NULL,
+    notification_type   VARCHAR(50)  NOT NULL,
+    related_entity_type VARCHAR(50)  NOT NULL DEFAULT '',
+    related_entity_id   INTEGER      NOT NULL DEFAULT 0,
+    is_read             BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications (user_id, created_at DESC);
+CREATE INDEX idx_notifications_user_unread ON notifications (user_id) WHERE NOT is_read;
+
+CREATE TABLE system_announcements (
+    announcement_id SERIAL PRIMARY KEY,
+    created_by      INTEGER      REFERENCES users (user_id) ON DELETE SET NULL,
+    title           VARCHAR(255) NOT NULL,
+    content         TEXT         NOT NULL,
+    priority        VARCHAR(20)  NOT NULL DEFAULT 'normal',
+    is_active       BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at      TIMESTAMPTZ
+);
+
+CREATE TABLE activity_log (
+    log_id      SERIAL PRIMARY KEY,
+    user_id     INTEGER      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    action      VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50)  NOT NULL DEFAULT '',
+    entity_id   INTEGER      NOT NULL DEFAULT 0,
+    details     TEXT         NOT NULL DEFAULT '',
+    ip_address  VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_activity_log_user_created ON activity_log (user_id, created_at DESC);
+CREATE INDEX idx_activity_log_created ON activity_log (created_at DESC);
+
+CREATE TABLE password_reset_tokens (
+    token_id   SERIAL PRIMARY KEY,
+    user_id    INTEGER      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    token      VARCHAR(255) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ  NOT NULL,
+    is_used    BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_reset_tokens_user ON password_reset_tokens (user_id);
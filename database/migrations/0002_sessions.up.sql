@@ -0,0 +1,17 @@
+-- Server-side sessions backing rotating refresh tokens
+
+CREATE TABLE sessions (
+    session_id          BIGSERIAL PRIMARY KEY,
+    user_id             INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    refresh_token_hash  CHAR(64)    NOT NULL UNIQUE,
+    previous_token_hash CHAR(64),
+    user_agent          TEXT        NOT NULL DEFAULT '',
+    ip_address          VARCHAR(64) NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at        TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at          TIMESTAMPTZ NOT NULL,
+    revoked_at          TIMESTAMPTZ
+);
+
+CREATE INDEX idx_sessions_user_active ON sessions (user_id) WHERE revoked_at IS NULL;
+CREATE INDEX idx_sessions_previous_token ON sessions (previous_token_hash);
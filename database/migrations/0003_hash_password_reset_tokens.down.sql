@@ -0,0 +1,5 @@
+DELETE FROM password_reset_tokens;
+
+ALTER TABLE password_reset_tokens RENAME CONSTRAINT password_reset_tokens_token_hash_key TO password_reset_tokens_token_key;
+ALTER TABLE password_reset_tokens ALTER COLUMN token_hash TYPE VARCHAR(255);
+ALTER TABLE password_reset_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,8 @@
+-- Reset tokens are now stored only as SHA-256 hashes. Tokens issued before
+-- this change were not usable secrets, so they are discarded.
+
+DELETE FROM password_reset_tokens;
+
+ALTER TABLE password_reset_tokens RENAME COLUMN token TO token_hash;
+ALTER TABLE password_reset_tokens ALTER COLUMN token_hash TYPE CHAR(64);
+ALTER TABLE password_reset_tokens RENAME CONSTRAINT password_reset_tokens_token_key TO password_reset_tokens_token_hash_key;
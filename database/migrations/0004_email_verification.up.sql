@@ -0,0 +1,18 @@
+-- Email verification for new registrations
+
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- Accounts that existed before verification was introduced are trusted
+UPDATE users SET email_verified = TRUE, email_verified_at = created_at;
+
+CREATE TABLE email_verification_tokens (
+    token_id   SERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    token_hash CHAR(64)    NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_email_verification_tokens_user ON email_verification_tokens (user_id);
@@ -0,0 +1,25 @@
+-- Background job run history and reminder bookkeeping
+
+CREATE TABLE job_runs (
+    run_id          BIGSERIAL PRIMARY KEY,
+    job_name        VARCHAR(100) NOT NULL,
+    trigger_type    VARCHAR(20)  NOT NULL CHECK (trigger_type IN ('schedule', 'manual')),
+    triggered_by    INTEGER      REFERENCES users (user_id) ON DELETE SET NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'running'
+                    CHECK (status IN ('running', 'succeeded', 'failed')),
+    items_processed INTEGER      NOT NULL DEFAULT 0,
+    error           TEXT         NOT NULL DEFAULT '',
+    started_at      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at     TIMESTAMPTZ
+);
+
+CREATE INDEX idx_job_runs_job_started ON job_runs (job_name, started_at DESC);
+
+-- One row per reminder sent, so each registrant gets each reminder once
+CREATE TABLE event_reminders (
+    event_id      INTEGER     NOT NULL REFERENCES events (event_id) ON DELETE CASCADE,
+    user_id       INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    reminder_type VARCHAR(20) NOT NULL CHECK (reminder_type IN ('24h', '1h', 'feedback')),
+    sent_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, user_id, reminder_type)
+);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_news_media_storage_key;
+DROP INDEX IF EXISTS idx_event_gallery_storage_key;
+
+ALTER TABLE news_media DROP COLUMN IF EXISTS file_size;
+ALTER TABLE news_media DROP COLUMN IF EXISTS content_type;
+ALTER TABLE news_media DROP COLUMN IF EXISTS storage_key;
+
+ALTER TABLE event_gallery DROP COLUMN IF EXISTS file_size;
+ALTER TABLE event_gallery DROP COLUMN IF EXISTS content_type;
+ALTER TABLE event_gallery DROP COLUMN IF EXISTS storage_key;
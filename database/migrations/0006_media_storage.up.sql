@@ -0,0 +1,13 @@
+-- Track uploaded files behind gallery images and news media. Rows that only
+-- link to an external URL keep an empty storage_key.
+
+ALTER TABLE event_gallery ADD COLUMN storage_key  TEXT         NOT NULL DEFAULT '';
+ALTER TABLE event_gallery ADD COLUMN content_type VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE event_gallery ADD COLUMN file_size    BIGINT       NOT NULL DEFAULT 0;
+
+ALTER TABLE news_media ADD COLUMN storage_key  TEXT         NOT NULL DEFAULT '';
+ALTER TABLE news_media ADD COLUMN content_type VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE news_media ADD COLUMN file_size    BIGINT       NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_event_gallery_storage_key ON event_gallery (storage_key) WHERE storage_key <> '';
+CREATE INDEX idx_news_media_storage_key ON news_media (storage_key) WHERE storage_key <> '';
@@ -0,0 +1,7 @@
+ALTER TABLE news_media DROP COLUMN IF EXISTS renditions;
+ALTER TABLE news_media DROP COLUMN IF EXISTS height;
+ALTER TABLE news_media DROP COLUMN IF EXISTS width;
+
+ALTER TABLE event_gallery DROP COLUMN IF EXISTS renditions;
+ALTER TABLE event_gallery DROP COLUMN IF EXISTS height;
+ALTER TABLE event_gallery DROP COLUMN IF EXISTS width;
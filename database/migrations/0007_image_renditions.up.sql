@@ -0,0 +1,11 @@
+-- Record the dimensions of uploaded images and the resized renditions
+-- generated for them. renditions maps a size name to its key, URL and
+-- dimensions, e.g. {"thumbnail": {"key": "...", "url": "...", "width": 320, "height": 240}}.
+
+ALTER TABLE event_gallery ADD COLUMN width      INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE event_gallery ADD COLUMN height     INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE event_gallery ADD COLUMN renditions JSONB   NOT NULL DEFAULT '{}';
+
+ALTER TABLE news_media ADD COLUMN width      INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news_media ADD COLUMN height     INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news_media ADD COLUMN renditions JSONB   NOT NULL DEFAULT '{}';
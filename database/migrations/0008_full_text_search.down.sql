@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS system_announcements_search_vector_update ON system_announcements;
+DROP TRIGGER IF EXISTS clubs_search_vector_update ON clubs;
+DROP TRIGGER IF EXISTS news_search_vector_update ON news;
+DROP TRIGGER IF EXISTS events_search_vector_update ON events;
+
+DROP FUNCTION IF EXISTS system_announcements_search_vector();
+DROP FUNCTION IF EXISTS clubs_search_vector();
+DROP FUNCTION IF EXISTS news_search_vector();
+DROP FUNCTION IF EXISTS events_search_vector();
+
+ALTER TABLE system_announcements DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE clubs DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE news DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE events DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,73 @@
+-- Full-text search over events, news, clubs and announcements. Each table
+-- keeps a weighted tsvector (titles rank above body text) that a trigger
+-- refreshes whenever a row is written.
+
+ALTER TABLE events ADD COLUMN search_vector tsvector;
+ALTER TABLE news ADD COLUMN search_vector tsvector;
+ALTER TABLE clubs ADD COLUMN search_vector tsvector;
+ALTER TABLE system_announcements ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION events_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.event_type, '') || ' ' || coalesce(NEW.location, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION news_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.category, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.content, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION clubs_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.club_name, '') || ' ' || coalesce(NEW.club_code, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION system_announcements_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.content, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER events_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, event_type, location, description ON events
+    FOR EACH ROW EXECUTE FUNCTION events_search_vector();
+
+CREATE TRIGGER news_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, category, content ON news
+    FOR EACH ROW EXECUTE FUNCTION news_search_vector();
+
+CREATE TRIGGER clubs_search_vector_update
+    BEFORE INSERT OR UPDATE OF club_name, club_code, description ON clubs
+    FOR EACH ROW EXECUTE FUNCTION clubs_search_vector();
+
+CREATE TRIGGER system_announcements_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, content ON system_announcements
+    FOR EACH ROW EXECUTE FUNCTION system_announcements_search_vector();
+
+-- Fill in existing rows; the triggers fire on these updates
+UPDATE events SET title = title;
+UPDATE news SET title = title;
+UPDATE clubs SET club_name = club_name;
+UPDATE system_announcements SET title = title;
+
+CREATE INDEX idx_events_search ON events USING GIN (search_vector);
+CREATE INDEX idx_news_search ON news USING GIN (search_vector);
+CREATE INDEX idx_clubs_search ON clubs USING GIN (search_vector);
+CREATE INDEX idx_system_announcements_search ON system_announcements USING GIN (search_vector);
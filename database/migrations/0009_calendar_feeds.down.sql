@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS calendar_tokens;
+
+DROP TRIGGER IF EXISTS events_sequence_update ON events;
+DROP FUNCTION IF EXISTS events_bump_sequence();
+
+ALTER TABLE events DROP COLUMN IF EXISTS sequence;
@@ -0,0 +1,33 @@
+-- Calendar feeds. Each event counts its revisions in sequence, which becomes
+-- the iCalendar SEQUENCE so subscribed calendars pick up changes.
+
+ALTER TABLE events ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE FUNCTION events_bump_sequence() RETURNS trigger AS $$
+BEGIN
+    IF NEW.title IS DISTINCT FROM OLD.title
+        OR NEW.description IS DISTINCT FROM OLD.description
+        OR NEW.event_type IS DISTINCT FROM OLD.event_type
+        OR NEW.location IS DISTINCT FROM OLD.location
+        OR NEW.start_datetime IS DISTINCT FROM OLD.start_datetime
+        OR NEW.end_datetime IS DISTINCT FROM OLD.end_datetime
+        OR (NEW.status = 'cancelled') <> (OLD.status = 'cancelled')
+    THEN
+        NEW.sequence := OLD.sequence + 1;
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER events_sequence_update
+    BEFORE UPDATE ON events
+    FOR EACH ROW EXECUTE FUNCTION events_bump_sequence();
+
+-- Personal feed tokens. Only the SHA-256 hash is stored; a user has at most
+-- one feed, and creating a new one revokes the old URL.
+CREATE TABLE calendar_tokens (
+    user_id      INTEGER     PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    token_hash   CHAR(64)    NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ
+);
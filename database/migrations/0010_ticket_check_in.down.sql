@@ -0,0 +1,2 @@
+ALTER TABLE event_registrations DROP COLUMN IF EXISTS checked_in_by;
+ALTER TABLE event_registrations DROP COLUMN IF EXISTS checked_in_at;
@@ -0,0 +1,4 @@
+-- Ticket check-in: when an attendee's ticket was scanned, and by whom
+
+ALTER TABLE event_registrations ADD COLUMN checked_in_at TIMESTAMPTZ;
+ALTER TABLE event_registrations ADD COLUMN checked_in_by INTEGER REFERENCES users (user_id) ON DELETE SET NULL;
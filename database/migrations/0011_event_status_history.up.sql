@@ -0,0 +1,23 @@
+-- Every change of an event's status, with who made it and why. from_status is
+-- NULL for the event's creation; changed_by is NULL for changes made by
+-- background jobs.
+
+CREATE TABLE event_status_history (
+    history_id  BIGSERIAL PRIMARY KEY,
+    event_id    INTEGER     NOT NULL REFERENCES events (event_id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status   VARCHAR(20) NOT NULL,
+    changed_by  INTEGER     REFERENCES users (user_id) ON DELETE SET NULL,
+    reason      TEXT        NOT NULL DEFAULT '',
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_event_status_history_event ON event_status_history (event_id, changed_at);
+
+-- Existing events get their creation and, if they have left review, the
+-- change to their current status
+INSERT INTO event_status_history (event_id, from_status, to_status, changed_by, changed_at)
+SELECT event_id, NULL, 'pending', created_by, created_at FROM events;
+
+INSERT INTO event_status_history (event_id, from_status, to_status, changed_at)
+SELECT event_id, 'pending', status, updated_at FROM events WHERE status <> 'pending';
@@ -0,0 +1,16 @@
+-- Review history of events and news posts: each approval or rejection, with
+-- the reviewer's reason and field comments, and each resubmission by the
+-- author
+
+CREATE TABLE content_reviews (
+    review_id   BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('event', 'news')),
+    entity_id   INTEGER     NOT NULL,
+    action      VARCHAR(20) NOT NULL CHECK (action IN ('approved', 'rejected', 'resubmitted')),
+    user_id     INTEGER     REFERENCES users (user_id) ON DELETE SET NULL,
+    reason      TEXT        NOT NULL DEFAULT '',
+    comments    JSONB       NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_content_reviews_entity ON content_reviews (entity_type, entity_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE event_registrations DROP COLUMN IF EXISTS ticket_version;
@@ -0,0 +1,4 @@
+-- Ticket versions: a ticket code names the version of its registration's
+-- ticket, so cancelling or registering again revokes codes issued before
+
+ALTER TABLE event_registrations ADD COLUMN ticket_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,54 @@
+package database
+
+import "database/sql"
+
+// Querier is satisfied by *sql.DB, *sql.Tx and *Tx for helpers that need to
+// read results as well as execute statements
+type Querier interface {
+	Execer
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Tx is a transaction that can run callbacks once it has committed, for side
+// effects such as pushing live updates that must not happen on rollback
+type Tx struct {
+	*sql.Tx
+	afterCommit []func()
+}
+
+// Begin starts a transaction on the shared connection pool
+func Begin() (*Tx, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+// AfterCommit registers fn to run after a successful commit
+func (tx *Tx) AfterCommit(fn func()) {
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+// Commit commits the transaction and then runs the registered callbacks
+func (tx *Tx) Commit() error {
+	if err := tx.Tx.Commit(); err != nil {
+		return err
+	}
+	for _, fn := range tx.afterCommit {
+		fn()
+	}
+	return nil
+}
+
+// AfterCommit runs fn once exec's transaction commits when exec is a *Tx, and
+// straight away otherwise. Helpers that may run inside a transaction should be
+// given a *Tx rather than a bare *sql.Tx.
+func AfterCommit(exec Execer, fn func()) {
+	if tx, ok := exec.(*Tx); ok {
+		tx.AfterCommit(fn)
+		return
+	}
+	fn()
+}
@@ -0,0 +1,134 @@
+package e2e
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+// authTokens are the token fields of sign in, sign up and refresh responses
+type authTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// mailToken is the token in the last link emailed
+var mailToken = regexp.MustCompile(`token=([^\s&]+)`)
+
+func lastMailToken(t *testing.T) string {
+	t.Helper()
+	matches := mailToken.FindAllStringSubmatch(env.Mail.String(), -1)
+	if len(matches) == 0 {
+		t.Fatalf("no link with a token was emailed\n%s", env.Mail.String())
+	}
+	token, err := url.QueryUnescape(matches[len(matches)-1][1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func login(t *testing.T, email, password string) *testenv.Response {
+	t.Helper()
+	return env.Do(t, testenv.Anonymous, "POST", "/api/auth/login", models.LoginRequest{Email: email, Password: password})
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	setup(t)
+
+	res := login(t, env.Fixtures.Student.Email, testenv.Password)
+	res.RequireStatus(t, http.StatusOK)
+	var first authTokens
+	res.DecodeData(t, &first)
+	if first.Token == "" || first.RefreshToken == "" || first.ExpiresIn <= 0 {
+		t.Fatalf("sign in returned %+v", first)
+	}
+
+	res = env.Do(t, testenv.Anonymous, "POST", "/api/auth/refresh", models.RefreshTokenRequest{RefreshToken: first.RefreshToken})
+	res.RequireStatus(t, http.StatusOK)
+	var second authTokens
+	res.DecodeData(t, &second)
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("the refresh token wasn't rotated: %+v", second)
+	}
+
+	// Replaying the old token revokes the session, so the new one stops working too
+	res = env.Do(t, testenv.Anonymous, "POST", "/api/auth/refresh", models.RefreshTokenRequest{RefreshToken: first.RefreshToken})
+	res.RequireError(t, http.StatusUnauthorized, "unauthorized")
+	res = env.Do(t, testenv.Anonymous, "POST", "/api/auth/refresh", models.RefreshTokenRequest{RefreshToken: second.RefreshToken})
+	res.RequireError(t, http.StatusUnauthorized, "unauthorized")
+}
+
+func TestPasswordReset(t *testing.T) {
+	setup(t)
+	email := env.Fixtures.Student.Email
+
+	res := env.Do(t, testenv.Anonymous, "POST", "/api/auth/forgot-password", map[string]string{"email": email})
+	res.RequireStatus(t, http.StatusOK)
+	token := lastMailToken(t)
+
+	reset := map[string]string{"token": token, "new_password": "a-new-password"}
+	env.Do(t, testenv.Anonymous, "POST", "/api/auth/reset-password", reset).RequireStatus(t, http.StatusOK)
+
+	login(t, email, "a-new-password").RequireStatus(t, http.StatusOK)
+	login(t, email, testenv.Password).RequireStatus(t, http.StatusUnauthorized)
+
+	// Each link works once
+	reset["new_password"] = "another-password"
+	res = env.Do(t, testenv.Anonymous, "POST", "/api/auth/reset-password", reset)
+	res.RequireError(t, http.StatusUnauthorized, "unauthorized")
+}
+
+func TestPasswordResetHidesUnknownEmails(t *testing.T) {
+	setup(t)
+
+	known := env.Do(t, testenv.Anonymous, "POST", "/api/auth/forgot-password", map[string]string{"email": env.Fixtures.Student.Email})
+	known.RequireStatus(t, http.StatusOK)
+	env.Mail.Reset()
+
+	unknown := env.Do(t, testenv.Anonymous, "POST", "/api/auth/forgot-password", map[string]string{"email": "nobody@nub.ac.bd"})
+	unknown.RequireStatus(t, http.StatusOK)
+	if got, want := unknown.Envelope(t).Message, known.Envelope(t).Message; got != want {
+		t.Errorf("got %q for an unknown email, want %q as for a known one", got, want)
+	}
+	if env.Mail.Len() != 0 {
+		t.Errorf("emailed an unknown address\n%s", env.Mail.String())
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	setup(t)
+
+	res := env.Do(t, testenv.Anonymous, "POST", "/api/auth/register", models.RegisterRequest{
+		StudentID: "2024-1-60-100",
+		Email:     "newcomer@nub.ac.bd",
+		Password:  "newcomer-password",
+		FirstName: "New",
+		LastName:  "Comer",
+	})
+	res.RequireStatus(t, http.StatusCreated)
+	var user struct {
+		authTokens
+		EmailVerified bool `json:"email_verified"`
+	}
+	res.DecodeData(t, &user)
+	if user.EmailVerified {
+		t.Fatal("a new user is already verified")
+	}
+
+	profile := func() *testenv.Response {
+		return env.DoWithHeaders(t, testenv.Anonymous, "PUT", "/api/users/profile",
+			map[string]string{"first_name": "Newer"},
+			map[string]string{"Authorization": "Bearer " + user.Token})
+	}
+	profile().RequireError(t, http.StatusForbidden, "email_not_verified")
+
+	res = env.Do(t, testenv.Anonymous, "POST", "/api/auth/verify-email", map[string]string{"token": lastMailToken(t)})
+	res.RequireStatus(t, http.StatusOK)
+	profile().RequireStatus(t, http.StatusOK)
+}
@@ -0,0 +1,5 @@
+// Package e2e holds the end-to-end tests: requests sent through the full
+// router against a throwaway database started by testenv. The tests skip
+// when no PostgreSQL server can be started, unless TEST_DATABASE_URL or
+// TEST_REQUIRE_DATABASE is set.
+package e2e
@@ -0,0 +1,212 @@
+package e2e
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/lifecycle"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+func eventIDs(t *testing.T, res *testenv.Response) []int {
+	t.Helper()
+	res.RequireStatus(t, http.StatusOK)
+	var events []struct {
+		EventID int `json:"event_id"`
+	}
+	res.DecodeData(t, &events)
+	ids := make([]int, len(events))
+	for i, event := range events {
+		ids[i] = event.EventID
+	}
+	return ids
+}
+
+func TestEventFilters(t *testing.T) {
+	setup(t)
+	upcoming := env.Fixtures.UpcomingEventID
+	register(t, testenv.Student, upcoming)
+
+	tests := []struct {
+		name  string
+		role  testenv.Role
+		query string
+		want  []int
+	}{
+		{"approved only", testenv.Anonymous, "", []int{upcoming}},
+		{"club", testenv.Anonymous, "?club_id=" + strconv.Itoa(env.Fixtures.ClubID), []int{upcoming}},
+		{"other club", testenv.Anonymous, "?club_id=" + strconv.Itoa(env.Fixtures.OtherClubID), nil},
+		{"upcoming", testenv.Anonymous, "?when=upcoming", []int{upcoming}},
+		{"past", testenv.Anonymous, "?when=past", nil},
+		{"with seats", testenv.Anonymous, "?has_seats=true", []int{upcoming}},
+		{"registered", testenv.Student, "?registered=true", []int{upcoming}},
+		{"not registered", testenv.Member, "?registered=true", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eventIDs(t, env.Do(t, tt.role, "GET", "/api/events"+tt.query, nil))
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("got events %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	env.Do(t, testenv.Anonymous, "GET", "/api/events?registered=true", nil).RequireError(t, http.StatusUnauthorized, "unauthorized")
+	env.Do(t, testenv.Anonymous, "GET", "/api/events?when=soon", nil).RequireError(t, http.StatusBadRequest, "bad_request")
+}
+
+func TestEventCalendar(t *testing.T) {
+	setup(t)
+
+	res := env.Do(t, testenv.Anonymous, "GET", eventPath(env.Fixtures.UpcomingEventID, "/ics"), nil)
+	res.RequireStatus(t, http.StatusOK)
+	if got := res.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/calendar") {
+		t.Errorf("got content type %s, want a calendar", got)
+	}
+	body := res.Body.String()
+	for _, want := range []string{"BEGIN:VCALENDAR", "BEGIN:VEVENT", "SUMMARY:Go Workshop", "LOCATION:Room 101"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("calendar is missing %q\n%s", want, body)
+		}
+	}
+
+	// Events awaiting review aren't published
+	res = env.Do(t, testenv.Anonymous, "GET", eventPath(env.Fixtures.PendingEventID, "/ics"), nil)
+	res.RequireError(t, http.StatusNotFound, "not_found")
+}
+
+func TestEventCancellation(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+	register(t, testenv.Student, eventID)
+
+	cancel := map[string]string{"reason": "Speaker unavailable"}
+	env.Do(t, testenv.Moderator, "POST", eventPath(eventID, "/cancel"), cancel).RequireStatus(t, http.StatusOK)
+	res := env.Do(t, testenv.Moderator, "POST", eventPath(eventID, "/cancel"), cancel)
+	res.RequireError(t, http.StatusConflict, "invalid_status_transition")
+
+	notified := env.QueryInt(t,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND notification_type = 'event_cancelled'`,
+		env.Fixtures.Student.UserID)
+	if notified != 1 {
+		t.Errorf("the registrant got %d cancellation notices, want 1", notified)
+	}
+}
+
+func TestStatusTransitions(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.PendingEventID
+	statuses := []string{lifecycle.Pending, lifecycle.Approved, lifecycle.Rejected, lifecycle.Completed, lifecycle.Cancelled}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			env.Exec(t, `UPDATE events SET status = $1 WHERE event_id = $2`, from, eventID)
+			env.Exec(t, `DELETE FROM event_status_history WHERE event_id = $1`, eventID)
+
+			previous, err := lifecycle.Transition(env.DB, eventID, to, nil, "test")
+			var transitionErr *lifecycle.TransitionError
+			allowed := lifecycle.CanTransition(from, to)
+			switch {
+			case previous != from:
+				t.Errorf("%s to %s: got previous status %s", from, to, previous)
+			case allowed && err != nil:
+				t.Errorf("%s to %s: %v", from, to, err)
+			case !allowed && !errors.As(err, &transitionErr):
+				t.Errorf("%s to %s: got error %v, want a transition error", from, to, err)
+			}
+
+			want, history := from, 0
+			if allowed {
+				want, history = to, 1
+			}
+			status := env.QueryInt(t, `SELECT COUNT(*) FROM events WHERE event_id = $1 AND status = $2`, eventID, want)
+			recorded := env.QueryInt(t,
+				`SELECT COUNT(*) FROM event_status_history WHERE event_id = $1 AND from_status = $2 AND to_status = $3`,
+				eventID, from, to)
+			if status != 1 || recorded != history {
+				t.Errorf("%s to %s: the event isn't %s or has %d history entries, want %d", from, to, want, recorded, history)
+			}
+		}
+	}
+
+	if _, err := lifecycle.Transition(env.DB, -1, lifecycle.Approved, nil, "test"); err != sql.ErrNoRows {
+		t.Errorf("a missing event got error %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestFinishedEventsAreCompleted(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+	env.Exec(t, `UPDATE events
+		SET start_datetime = CURRENT_TIMESTAMP - INTERVAL '3 hours', end_datetime = CURRENT_TIMESTAMP - INTERVAL '1 hour'
+		WHERE event_id = $1`, eventID)
+
+	res := env.Do(t, testenv.Admin, "POST", "/api/admin/jobs/complete_events/run", nil)
+	res.RequireStatus(t, http.StatusOK)
+	var run models.JobRun
+	res.DecodeData(t, &run)
+	if run.Status != "succeeded" || run.ItemsProcessed != 1 {
+		t.Fatalf("got run %+v, want one event completed", run)
+	}
+
+	completed := env.QueryInt(t,
+		`SELECT COUNT(*) FROM events WHERE event_id = $1 AND status = 'completed'`, eventID)
+	history := env.QueryInt(t,
+		`SELECT COUNT(*) FROM event_status_history WHERE event_id = $1 AND to_status = 'completed' AND reason = 'Event ended'`, eventID)
+	if completed != 1 || history != 1 {
+		t.Errorf("the event wasn't completed through the lifecycle: status %d, history %d", completed, history)
+	}
+}
+
+func TestRejectionIsShownToTheClub(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.PendingEventID
+
+	res := env.Do(t, testenv.Admin, "POST", eventPath(eventID, "/reject"), models.ReviewRequest{})
+	res.RequireError(t, http.StatusBadRequest, "bad_request")
+
+	env.Do(t, testenv.Admin, "POST", eventPath(eventID, "/reject"), models.ReviewRequest{
+		Reason:   "Needs a venue",
+		Comments: []models.ReviewComment{{Field: "location", Comment: "Book a room first"}},
+	}).RequireStatus(t, http.StatusOK)
+
+	latest := func(role testenv.Role) *models.ContentReview {
+		res := env.Do(t, role, "GET", eventPath(eventID, ""), nil)
+		res.RequireStatus(t, http.StatusOK)
+		var event models.Event
+		res.DecodeData(t, &event)
+		return event.LatestReview
+	}
+
+	review := latest(testenv.Moderator)
+	if review == nil || review.Action != "rejected" || review.Reason != "Needs a venue" || len(review.Comments) != 1 {
+		t.Fatalf("got review %+v, want the rejection", review)
+	}
+	if review := latest(testenv.Student); review != nil {
+		t.Errorf("a student outside the club sees review %+v", review)
+	}
+}
+
+func TestEventValidation(t *testing.T) {
+	setup(t)
+
+	res := env.Do(t, testenv.Moderator, "POST", "/api/events", map[string]interface{}{
+		"club_id":        env.Fixtures.ClubID,
+		"title":          "Backwards",
+		"capacity":       10,
+		"start_datetime": "2030-01-01T12:00:00Z",
+		"end_datetime":   "2030-01-01T10:00:00Z",
+	})
+	res.RequireError(t, http.StatusUnprocessableEntity, "validation_failed")
+
+	details := res.Envelope(t).Details
+	if len(details) != 1 || details[0].Field != "end_datetime" || details[0].Code != "too_early" {
+		t.Errorf("got details %+v, want end_datetime too_early", details)
+	}
+}
@@ -0,0 +1,95 @@
+package e2e
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+func TestClubsArePagedByCursor(t *testing.T) {
+	setup(t)
+
+	seen := make(map[int]bool)
+	path := "/api/clubs?limit=1"
+	for page := 1; ; page++ {
+		res := env.Do(t, testenv.Anonymous, "GET", path, nil)
+		res.RequireStatus(t, http.StatusOK)
+		envelope := res.Envelope(t)
+		var clubs []models.Club
+		res.DecodeData(t, &clubs)
+
+		if envelope.Meta == nil || envelope.Meta.Total != 2 || envelope.Meta.Limit != 1 {
+			t.Fatalf("page %d has meta %+v, want two clubs one at a time", page, envelope.Meta)
+		}
+		if len(clubs) != 1 || seen[clubs[0].ClubID] {
+			t.Fatalf("page %d has clubs %+v", page, clubs)
+		}
+		seen[clubs[0].ClubID] = true
+
+		if envelope.Meta.NextCursor == "" {
+			break
+		}
+		if page == 2 {
+			t.Fatal("the last page has a next cursor")
+		}
+		path = "/api/clubs?limit=1&cursor=" + url.QueryEscape(envelope.Meta.NextCursor)
+	}
+
+	if !seen[env.Fixtures.ClubID] || !seen[env.Fixtures.OtherClubID] {
+		t.Errorf("got clubs %v, want both fixtures", seen)
+	}
+
+	res := env.Do(t, testenv.Anonymous, "GET", "/api/clubs?cursor=garbage", nil)
+	res.RequireError(t, http.StatusBadRequest, "bad_request")
+}
+
+func TestSearch(t *testing.T) {
+	setup(t)
+
+	search := func(query string) []models.SearchHit {
+		res := env.Do(t, testenv.Anonymous, "GET", "/api/search?type=events&q="+url.QueryEscape(query), nil)
+		res.RequireStatus(t, http.StatusOK)
+		var results models.SearchResults
+		res.DecodeData(t, &results)
+		if results.Events == nil || results.News != nil {
+			t.Fatalf("got %+v, want only events", results)
+		}
+		return results.Events.Items
+	}
+
+	hits := search("workshop")
+	if len(hits) != 1 || hits[0].EntityID != env.Fixtures.UpcomingEventID {
+		t.Errorf("got %+v, want the workshop", hits)
+	}
+	// Events awaiting review aren't searchable
+	if hits := search("hackathon"); len(hits) != 0 {
+		t.Errorf("got %+v, want nothing", hits)
+	}
+
+	env.Do(t, testenv.Anonymous, "GET", "/api/search", nil).RequireError(t, http.StatusBadRequest, "bad_request")
+}
+
+func TestUserSearch(t *testing.T) {
+	setup(t)
+
+	search := func(query string) []models.User {
+		res := env.Do(t, testenv.Admin, "GET", "/api/admin/users?q="+url.QueryEscape(query), nil)
+		res.RequireStatus(t, http.StatusOK)
+		var users []models.User
+		res.DecodeData(t, &users)
+		return users
+	}
+
+	if users := search(env.Fixtures.Student.StudentID); len(users) != 1 || users[0].UserID != env.Fixtures.Student.UserID {
+		t.Errorf("got %+v, want the student", users)
+	}
+	// Wildcards are matched literally
+	for _, query := range []string{"%", "_", `\`} {
+		if users := search(query); len(users) != 0 {
+			t.Errorf("%q matched %d users", query, len(users))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package e2e
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+// env is shared by every test; setup resets it
+var env *testenv.Env
+
+// startErr is why env couldn't be started
+var startErr error
+
+func TestMain(m *testing.M) {
+	env, startErr = testenv.Start()
+	if startErr != nil {
+		// A server that was asked for must be reachable, and CI sets
+		// TEST_REQUIRE_DATABASE so the suite can't pass by skipping
+		if os.Getenv("TEST_DATABASE_URL") != "" || os.Getenv("TEST_REQUIRE_DATABASE") != "" {
+			log.Fatalf("End-to-end tests need PostgreSQL: %v", startErr)
+		}
+		log.Printf("Skipping end-to-end tests: %v", startErr)
+	}
+
+	code := m.Run()
+	if env != nil {
+		env.Close()
+	}
+	os.Exit(code)
+}
+
+// setup skips the test when there is no database and otherwise restores the
+// fixtures
+func setup(t *testing.T) {
+	t.Helper()
+	if env == nil {
+		t.Skipf("no PostgreSQL server: %v", startErr)
+	}
+	env.Reset(t)
+}
+
+// eventPath is the path of an event's endpoint, e.g. eventPath(1, "/register")
+func eventPath(eventID int, suffix string) string {
+	return fmt.Sprintf("/api/events/%d%s", eventID, suffix)
+}
+
+// register signs a role up for an event and returns the registration status
+func register(t *testing.T, role testenv.Role, eventID int) string {
+	t.Helper()
+	res := env.Do(t, role, "POST", eventPath(eventID, "/register"), nil)
+	res.RequireStatus(t, http.StatusCreated)
+	var registration struct {
+		Status string `json:"registration_status"`
+	}
+	res.DecodeData(t, &registration)
+	return registration.Status
+}
+
+// registrationStatus reads a user's registration status for an event
+func registrationStatus(t *testing.T, eventID, userID int) string {
+	t.Helper()
+	var status string
+	err := env.DB.QueryRow(
+		`SELECT registration_status FROM event_registrations WHERE event_id = $1 AND user_id = $2`,
+		eventID, userID,
+	).Scan(&status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
@@ -0,0 +1,99 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+func notificationTypes(t *testing.T, role testenv.Role) []string {
+	t.Helper()
+	res := env.Do(t, role, "GET", "/api/notifications", nil)
+	res.RequireStatus(t, http.StatusOK)
+	var notifications []models.Notification
+	res.DecodeData(t, &notifications)
+
+	types := make([]string, len(notifications))
+	for i, notification := range notifications {
+		types[i] = notification.NotificationType
+	}
+	return types
+}
+
+func contains(list []string, want string) bool {
+	for _, item := range list {
+		if item == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegistrationNotifies(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+
+	register(t, testenv.Student, eventID)
+	register(t, testenv.Member, eventID)
+	register(t, testenv.Admin, eventID)
+
+	if got := notificationTypes(t, testenv.Student); !contains(got, "registration_confirmed") {
+		t.Errorf("got %v, want a confirmation", got)
+	}
+	if got := notificationTypes(t, testenv.Admin); !contains(got, "registration_waitlisted") {
+		t.Errorf("got %v, want a waitlist notice", got)
+	}
+}
+
+func TestNotificationStream(t *testing.T) {
+	setup(t)
+	register(t, testenv.Student, env.Fixtures.UpcomingEventID)
+
+	// The stream stays open until the client leaves
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest("GET", "/api/notifications/stream?access_token="+env.Token(t, testenv.Student), nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "0")
+	rec := httptest.NewRecorder()
+	env.Router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/event-stream") {
+		t.Fatalf("got content type %s, want an event stream\n%s", got, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"event: notification", "registration_confirmed", "event: unread_count"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stream is missing %q\n%s", want, body)
+		}
+	}
+}
+
+func TestEventReminders(t *testing.T) {
+	setup(t)
+	register(t, testenv.Student, env.Fixtures.UpcomingEventID)
+
+	run := func() models.JobRun {
+		res := env.Do(t, testenv.Admin, "POST", "/api/admin/jobs/event_reminders/run", nil)
+		res.RequireStatus(t, http.StatusOK)
+		var run models.JobRun
+		res.DecodeData(t, &run)
+		return run
+	}
+
+	// The event starts tomorrow, inside the 24 hour window
+	if got := run(); got.Status != "succeeded" || got.ItemsProcessed != 1 {
+		t.Fatalf("got run %+v, want one reminder", got)
+	}
+	if got := run(); got.ItemsProcessed != 0 {
+		t.Errorf("a second run sent %d reminders again", got.ItemsProcessed)
+	}
+	if got := notificationTypes(t, testenv.Student); !contains(got, "event_reminder") {
+		t.Errorf("got %v, want a reminder", got)
+	}
+}
@@ -0,0 +1,78 @@
+package e2e
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/middleware"
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+func TestMigrationsAreApplied(t *testing.T) {
+	setup(t)
+
+	statuses, err := database.GetMigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) == 0 {
+		t.Fatal("no migrations were found")
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Errorf("migration %04d_%s wasn't applied", status.Version, status.Name)
+		}
+	}
+
+	// Running them again does nothing
+	if applied, err := database.MigrateUp(); err != nil || applied != 0 {
+		t.Errorf("a second run applied %d migrations, error %v", applied, err)
+	}
+}
+
+func TestConstraintErrors(t *testing.T) {
+	setup(t)
+
+	res := env.Do(t, testenv.Admin, "POST", "/api/clubs", map[string]string{
+		"club_name": "Another Programming Club",
+		"club_code": "NUBPC",
+	})
+	res.RequireError(t, http.StatusConflict, "club_code_taken")
+
+	envelope := res.Envelope(t)
+	if len(envelope.Details) != 1 || envelope.Details[0].Field != "club_code" {
+		t.Errorf("got details %+v, want club_code", envelope.Details)
+	}
+	if envelope.RequestID == "" || envelope.RequestID != res.Header().Get(middleware.RequestIDHeader) {
+		t.Errorf("got request ID %q, want the one in the header", envelope.RequestID)
+	}
+}
+
+func TestRequestsAreLoggedWithTheirID(t *testing.T) {
+	setup(t)
+
+	res := env.DoWithHeaders(t, testenv.Student, "GET", "/api/users/profile", nil,
+		map[string]string{middleware.RequestIDHeader: "e2e-trace-1"})
+	res.RequireStatus(t, http.StatusOK)
+	if got := res.Header().Get(middleware.RequestIDHeader); got != "e2e-trace-1" {
+		t.Errorf("got request ID %q, want the client's", got)
+	}
+
+	var line string
+	for _, entry := range strings.Split(env.Log.String(), "\n") {
+		if strings.Contains(entry, "request_id=e2e-trace-1") && strings.Contains(entry, "msg=Request") {
+			line = entry
+		}
+	}
+	if line == "" {
+		t.Fatalf("the request wasn't logged with its ID\n%s", env.Log.String())
+	}
+	for _, want := range []string{"status=200", "route=/api/users/profile", "user_id=" + strconv.Itoa(env.Fixtures.Student.UserID)} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log entry is missing %s\n%s", want, line)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package e2e
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/handlers"
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+// registrationRow is an entry of an event's registrations list
+type registrationRow struct {
+	UserID             int    `json:"user_id"`
+	RegistrationStatus string `json:"registration_status"`
+	AttendanceMarked   bool   `json:"attendance_marked"`
+	FeedbackSubmitted  bool   `json:"feedback_submitted"`
+}
+
+func registrations(t *testing.T, eventID int) map[int]registrationRow {
+	t.Helper()
+	res := env.Do(t, testenv.Moderator, "GET", eventPath(eventID, "/registrations"), nil)
+	res.RequireStatus(t, http.StatusOK)
+	var rows []registrationRow
+	res.DecodeData(t, &rows)
+
+	byUser := make(map[int]registrationRow)
+	for _, row := range rows {
+		byUser[row.UserID] = row
+	}
+	return byUser
+}
+
+func TestWaitlistPromotion(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+
+	for _, role := range []testenv.Role{testenv.Student, testenv.Member} {
+		if got := register(t, role, eventID); got != "confirmed" {
+			t.Fatalf("%s got %s, want confirmed", role, got)
+		}
+	}
+	if got := register(t, testenv.Admin, eventID); got != "waitlist" {
+		t.Fatalf("the third registration got %s on an event for two, want waitlist", got)
+	}
+
+	env.Do(t, testenv.Student, "DELETE", eventPath(eventID, "/register"), nil).RequireStatus(t, http.StatusOK)
+
+	if got := registrationStatus(t, eventID, env.Fixtures.Admin.UserID); got != "confirmed" {
+		t.Errorf("the waitlisted registration is %s after a cancellation, want confirmed", got)
+	}
+}
+
+func TestConcurrentRegistrationsRespectCapacity(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+
+	contenders := []testenv.Role{testenv.Student, testenv.Member, testenv.Moderator, testenv.Admin}
+	requests := make([]*http.Request, len(contenders))
+	for i, role := range contenders {
+		// Sign in first so the registrations race each other rather than the logins
+		requests[i] = httptest.NewRequest("POST", eventPath(eventID, "/register"), nil)
+		requests[i].Header.Set("Authorization", "Bearer "+env.Token(t, role))
+	}
+
+	// The requests are served directly; env.Do waits for background work,
+	// which can't be done from several goroutines at once
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, len(contenders))
+	for i := range requests {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			env.Router.ServeHTTP(recorders[i], requests[i])
+		}(i)
+	}
+	wg.Wait()
+	handlers.WaitForBackground()
+
+	for i, rec := range recorders {
+		if rec.Code != http.StatusCreated {
+			t.Errorf("%s got status %d\n%s", contenders[i], rec.Code, rec.Body.String())
+		}
+	}
+	confirmed := env.QueryInt(t,
+		`SELECT COUNT(*) FROM event_registrations WHERE event_id = $1 AND registration_status = 'confirmed'`, eventID)
+	if confirmed != 2 {
+		t.Errorf("%d registrations were confirmed for two seats", confirmed)
+	}
+}
+
+func TestRegistrationsListIsScopedToTheClub(t *testing.T) {
+	setup(t)
+
+	rows := registrations(t, env.Fixtures.PastEventID)
+	if row, ok := rows[env.Fixtures.Member.UserID]; !ok || row.RegistrationStatus != "attended" {
+		t.Errorf("got %+v, want Member's attendance", rows)
+	}
+
+	// Moderator doesn't moderate the other club
+	res := env.Do(t, testenv.Moderator, "POST", "/api/events", map[string]interface{}{
+		"club_id":        env.Fixtures.OtherClubID,
+		"title":          "Debate night",
+		"capacity":       10,
+		"start_datetime": "2030-01-01T10:00:00Z",
+		"end_datetime":   "2030-01-01T12:00:00Z",
+	})
+	res.RequireError(t, http.StatusForbidden, "forbidden")
+}
+
+func TestTicketCheckIn(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+	register(t, testenv.Student, eventID)
+
+	ticket := func() string {
+		res := env.Do(t, testenv.Student, "GET", eventPath(eventID, "/ticket"), nil)
+		res.RequireStatus(t, http.StatusOK)
+		var ticket struct {
+			Code string `json:"ticket_code"`
+		}
+		res.DecodeData(t, &ticket)
+		return ticket.Code
+	}
+	checkIn := func(code string) *testenv.Response {
+		return env.Do(t, testenv.Moderator, "POST", eventPath(eventID, "/check-in"), map[string]string{"ticket_code": code})
+	}
+
+	first := ticket()
+	checkIn(first).RequireStatus(t, http.StatusOK)
+	checkIn(first).RequireError(t, http.StatusConflict, "already_checked_in")
+
+	env.Do(t, testenv.Student, "DELETE", eventPath(eventID, "/register"), nil).RequireStatus(t, http.StatusOK)
+	checkIn(first).RequireError(t, http.StatusConflict, "registration_cancelled")
+
+	// Registering again issues a new ticket and revokes the old one
+	register(t, testenv.Student, eventID)
+	second := ticket()
+	checkIn(first).RequireError(t, http.StatusConflict, "ticket_revoked")
+	checkIn(second).RequireStatus(t, http.StatusOK)
+
+	env.Exec(t, `UPDATE events SET status = 'cancelled' WHERE event_id = $1`, eventID)
+	checkIn(second).RequireError(t, http.StatusConflict, "event_not_approved")
+}
+
+func TestMarkAttendance(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+	register(t, testenv.Student, eventID)
+	register(t, testenv.Member, eventID)
+	if got := register(t, testenv.Admin, eventID); got != "waitlist" {
+		t.Fatalf("got %s, want the third registrant waitlisted", got)
+	}
+
+	mark := func(userID int) *testenv.Response {
+		return env.Do(t, testenv.Moderator, "POST", eventPath(eventID, "/attendance"), map[string]int{"user_id": userID})
+	}
+
+	mark(env.Fixtures.Student.UserID).RequireStatus(t, http.StatusOK)
+	if !registrations(t, eventID)[env.Fixtures.Student.UserID].AttendanceMarked {
+		t.Error("attendance wasn't marked")
+	}
+	mark(env.Fixtures.Admin.UserID).RequireError(t, http.StatusNotFound, "not_found")
+
+	env.Exec(t, `UPDATE events SET status = 'cancelled' WHERE event_id = $1`, eventID)
+	mark(env.Fixtures.Member.UserID).RequireError(t, http.StatusConflict, "event_not_approved")
+}
+
+func TestBulkAttendance(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.UpcomingEventID
+	register(t, testenv.Student, eventID)
+
+	res := env.Do(t, testenv.Moderator, "POST", eventPath(eventID, "/attendance/bulk"), map[string][]string{
+		"identifiers": {
+			env.Fixtures.Student.StudentID,
+			env.Fixtures.Student.Email,
+			"2024-1-60-999",
+			env.Fixtures.Admin.StudentID,
+		},
+	})
+	res.RequireStatus(t, http.StatusOK)
+	var report struct {
+		Rows []struct {
+			Result string `json:"result"`
+		} `json:"rows"`
+	}
+	res.DecodeData(t, &report)
+
+	want := []string{"marked", "duplicate", "unknown_user", "not_registered"}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("got %+v, want %v", report.Rows, want)
+	}
+	for i, row := range report.Rows {
+		if row.Result != want[i] {
+			t.Errorf("row %d got %s, want %s", i+1, row.Result, want[i])
+		}
+	}
+	if !registrations(t, eventID)[env.Fixtures.Student.UserID].AttendanceMarked {
+		t.Error("attendance wasn't marked")
+	}
+
+	res = env.Do(t, testenv.Moderator, "GET", eventPath(eventID, "/registrations.csv"), nil)
+	res.RequireStatus(t, http.StatusOK)
+	if got := res.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("got content type %s, want CSV", got)
+	}
+	if !strings.Contains(res.Body.String(), env.Fixtures.Student.StudentID) {
+		t.Errorf("the export is missing the registrant\n%s", res.Body.String())
+	}
+
+	// An email differing only in case matches both accounts
+	env.Exec(t, `INSERT INTO users (student_id, email, password_hash, first_name)
+		VALUES ('2024-1-60-998', upper($1), '-', 'Twin')`, env.Fixtures.Member.Email)
+	res = env.Do(t, testenv.Moderator, "POST", eventPath(eventID, "/attendance/bulk")+"?dry_run=true",
+		map[string][]string{"identifiers": {env.Fixtures.Member.Email}})
+	res.RequireStatus(t, http.StatusOK)
+	res.DecodeData(t, &report)
+	if len(report.Rows) != 1 || report.Rows[0].Result != "ambiguous" {
+		t.Errorf("got %+v, want the email reported as ambiguous", report.Rows)
+	}
+
+	env.Exec(t, `UPDATE events SET status = 'cancelled' WHERE event_id = $1`, eventID)
+	res = env.Do(t, testenv.Moderator, "POST", eventPath(eventID, "/attendance/bulk"),
+		map[string][]string{"identifiers": {env.Fixtures.Student.StudentID}})
+	res.RequireError(t, http.StatusConflict, "event_not_approved")
+}
+
+func TestFeedbackIsFlaggedOnTheRegistration(t *testing.T) {
+	setup(t)
+	eventID := env.Fixtures.PastEventID
+
+	res := env.Do(t, testenv.Member, "POST", eventPath(eventID, "/feedback"), map[string]interface{}{"rating": 5})
+	res.RequireStatus(t, http.StatusCreated)
+
+	if !registrations(t, eventID)[env.Fixtures.Member.UserID].FeedbackSubmitted {
+		t.Error("the registration isn't flagged as having feedback")
+	}
+}
@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+var roles = []testenv.Role{testenv.Anonymous, testenv.Student, testenv.Member, testenv.Moderator, testenv.Admin}
+
+func TestSignInAsEachRole(t *testing.T) {
+	setup(t)
+
+	tests := []struct {
+		role testenv.Role
+		user testenv.FixtureUser
+		want string
+	}{
+		{testenv.Student, env.Fixtures.Student, "student"},
+		{testenv.Member, env.Fixtures.Member, "student"},
+		{testenv.Moderator, env.Fixtures.Moderator, "club_moderator"},
+		{testenv.Admin, env.Fixtures.Admin, "system_admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			res := env.Do(t, tt.role, "GET", "/api/users/profile", nil)
+			res.RequireStatus(t, http.StatusOK)
+
+			var profile struct {
+				UserID int    `json:"user_id"`
+				Email  string `json:"email"`
+				Role   string `json:"role"`
+			}
+			res.DecodeData(t, &profile)
+			if profile.UserID != tt.user.UserID || profile.Email != tt.user.Email || profile.Role != tt.want {
+				t.Errorf("got %+v, want user %d %s as %s", profile, tt.user.UserID, tt.user.Email, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteAccess(t *testing.T) {
+	setup(t)
+
+	tests := []struct {
+		name string
+		path string
+		want map[testenv.Role]int // missing roles get 200
+	}{
+		{"public", "/health", nil},
+		{"public API", "/api/events", nil},
+		{"authenticated", "/api/users/profile", map[testenv.Role]int{
+			testenv.Anonymous: http.StatusUnauthorized,
+		}},
+		{"moderator", eventPath(env.Fixtures.UpcomingEventID, "/registrations"), map[testenv.Role]int{
+			testenv.Anonymous: http.StatusUnauthorized,
+			testenv.Student:   http.StatusForbidden,
+			testenv.Member:    http.StatusForbidden,
+		}},
+		{"admin", "/api/admin/dashboard", map[testenv.Role]int{
+			testenv.Anonymous: http.StatusUnauthorized,
+			testenv.Student:   http.StatusForbidden,
+			testenv.Member:    http.StatusForbidden,
+			testenv.Moderator: http.StatusForbidden,
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, role := range roles {
+			name := string(role)
+			if name == "" {
+				name = "anonymous"
+			}
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				want, ok := tt.want[role]
+				if !ok {
+					want = http.StatusOK
+				}
+				env.Do(t, role, "GET", tt.path, nil).RequireStatus(t, want)
+			})
+		}
+	}
+}
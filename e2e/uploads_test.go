@@ -0,0 +1,90 @@
+package e2e
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/testenv"
+)
+
+// uploadForm is a multipart form with a file and a caption
+func uploadForm(t *testing.T, name string, data []byte) (*bytes.Buffer, map[string]string) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	w.WriteField("caption", "Opening talk")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, map[string]string{"Content-Type": w.FormDataContentType()}
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGalleryUpload(t *testing.T) {
+	setup(t)
+	path := eventPath(env.Fixtures.UpcomingEventID, "/gallery")
+
+	body, headers := uploadForm(t, "talk.png", testPNG(t, 640, 400))
+	res := env.DoWithHeaders(t, testenv.Moderator, "POST", path, body, headers)
+	res.RequireStatus(t, http.StatusCreated)
+	var uploaded struct {
+		GalleryID int              `json:"gallery_id"`
+		ImageURL  string           `json:"image_url"`
+		Images    *models.ImageSet `json:"images"`
+	}
+	res.DecodeData(t, &uploaded)
+	if uploaded.Images == nil {
+		t.Fatalf("got %+v, want the image's renditions", uploaded)
+	}
+	// Only sizes smaller than the original are made
+	var thumbnail *models.ImageSource
+	for i, source := range uploaded.Images.Sources {
+		if source.Name == "thumbnail" {
+			thumbnail = &uploaded.Images.Sources[i]
+		}
+	}
+	if len(uploaded.Images.Sources) != 2 || thumbnail == nil || thumbnail.Width != 320 || thumbnail.Height != 200 {
+		t.Errorf("got sources %+v, want the original and a 320x200 thumbnail", uploaded.Images.Sources)
+	}
+
+	// The stored file is served back
+	res = env.Do(t, testenv.Anonymous, "GET", uploaded.ImageURL, nil)
+	res.RequireStatus(t, http.StatusOK)
+	if got := res.Header().Get("Content-Type"); !strings.HasPrefix(got, "image/") {
+		t.Errorf("got content type %s, want an image", got)
+	}
+
+	body, headers = uploadForm(t, "notes.txt", []byte("not an image"))
+	res = env.DoWithHeaders(t, testenv.Moderator, "POST", path, body, headers)
+	res.RequireError(t, http.StatusUnsupportedMediaType, "unsupported_media_type")
+
+	body, headers = uploadForm(t, "talk.png", testPNG(t, 8, 8))
+	res = env.DoWithHeaders(t, testenv.Member, "POST", path, body, headers)
+	res.RequireError(t, http.StatusForbidden, "forbidden")
+}
@@ -6,10 +6,42 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
+// activityList is how activity logs can be sorted and filtered
+var activityList = listing.Spec{
+	Key: "log_id",
+	Sorts: map[string]listing.Sort{
+		"created_at": {Column: "created_at", Type: "timestamptz"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"action":      {Column: "action", Kind: listing.Equals},
+		"entity_type": {Column: "entity_type", Kind: listing.Equals},
+		"from":        {Column: "created_at", Kind: listing.From},
+		"to":          {Column: "created_at", Kind: listing.Until},
+	},
+	DefaultLimit: 50,
+}
+
+// allActivityList adds a user filter to activityList for the admin view
+var allActivityList = listing.Spec{
+	Key:         activityList.Key,
+	Sorts:       activityList.Sorts,
+	DefaultSort: activityList.DefaultSort,
+	Filters: map[string]listing.Filter{
+		"action":      {Column: "action", Kind: listing.Equals},
+		"entity_type": {Column: "entity_type", Kind: listing.Equals},
+		"from":        {Column: "created_at", Kind: listing.From},
+		"to":          {Column: "created_at", Kind: listing.Until},
+		"user_id":     {Column: "user_id", Kind: listing.IntEquals},
+	},
+	DefaultLimit: activityList.DefaultLimit,
+}
+
 // GetUserActivityLog retrieves activity log for a specific user
 func GetUserActivityLog(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
@@ -18,34 +50,13 @@ func GetUserActivityLog(c *gin.Context) {
 		return
 	}
 
-	rows, err := database.DB.Query(
-		`SELECT log_id, action, entity_type, entity_id, details, ip_address, created_at
-		 FROM activity_log
-		 WHERE user_id = $1
-		 ORDER BY created_at DESC
-		 LIMIT 100`,
-		userID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch activity log")
+	page := parsePage(c, &activityList)
+	if page == nil {
 		return
 	}
-	defer rows.Close()
-
-	var logs []models.ActivityLog
-
-	for rows.Next() {
-		var log models.ActivityLog
-		err := rows.Scan(&log.LogID, &log.Action, &log.EntityType, &log.EntityID, &log.Details, &log.IPAddress, &log.CreatedAt)
-		if err != nil {
-			continue
-		}
-		log.UserID = userID
-		logs = append(logs, log)
-	}
+	page.Where("user_id = " + page.Arg(userID))
 
-	utils.SuccessResponse(c, http.StatusOK, "Activity log retrieved", logs)
+	listActivity(c, page, "Activity log retrieved")
 }
 
 // GetCurrentUserActivityLog retrieves activity log for the current authenticated user
@@ -56,34 +67,13 @@ func GetCurrentUserActivityLog(c *gin.Context) {
 		return
 	}
 
-	rows, err := database.DB.Query(
-		`SELECT log_id, action, entity_type, entity_id, details, ip_address, created_at
-		 FROM activity_log
-		 WHERE user_id = $1
-		 ORDER BY created_at DESC
-		 LIMIT 100`,
-		userID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch activity log")
+	page := parsePage(c, &activityList)
+	if page == nil {
 		return
 	}
-	defer rows.Close()
-
-	var logs []models.ActivityLog
-
-	for rows.Next() {
-		var log models.ActivityLog
-		err := rows.Scan(&log.LogID, &log.Action, &log.EntityType, &log.EntityID, &log.Details, &log.IPAddress, &log.CreatedAt)
-		if err != nil {
-			continue
-		}
-		log.UserID = userID.(int)
-		logs = append(logs, log)
-	}
+	page.Where("user_id = " + page.Arg(userID))
 
-	utils.SuccessResponse(c, http.StatusOK, "Activity log retrieved", logs)
+	listActivity(c, page, "Activity log retrieved")
 }
 
 // GetAllActivityLogs retrieves all activity logs (admin only)
@@ -94,29 +84,52 @@ func GetAllActivityLogs(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &allActivityList)
+	if page == nil {
+		return
+	}
+
+	listActivity(c, page, "Activity logs retrieved")
+}
+
+// listActivity responds with a page of the activity log
+func listActivity(c *gin.Context, page *listing.Page, message string) {
+	total, err := page.Count(database.DB, "activity_log")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch activity log")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
-		`SELECT log_id, user_id, action, entity_type, entity_id, details, ip_address, created_at
-		 FROM activity_log
-		 ORDER BY created_at DESC
-		 LIMIT 500`,
+		`SELECT log_id, user_id, action, entity_type, entity_id, details, ip_address, created_at,
+			`+page.CursorColumns()+`
+		 FROM activity_log`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch activity logs")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch activity log")
 		return
 	}
 	defer rows.Close()
 
-	var logs []models.ActivityLog
+	logs := make([]models.ActivityLog, 0)
 
 	for rows.Next() {
 		var log models.ActivityLog
-		err := rows.Scan(&log.LogID, &log.UserID, &log.Action, &log.EntityType, &log.EntityID, &log.Details, &log.IPAddress, &log.CreatedAt)
+		var cursor listing.Cursor
+		err := rows.Scan(&log.LogID, &log.UserID, &log.Action, &log.EntityType, &log.EntityID, &log.Details, &log.IPAddress, &log.CreatedAt,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch activity log")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		logs = append(logs, log)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Activity logs retrieved", logs)
+	utils.PageResponse(c, http.StatusOK, message, logs, page.Meta(total))
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
@@ -30,7 +31,7 @@ func GetDashboardStats(c *gin.Context) {
 	).Scan(&stats.TotalStudents, &stats.TotalClubs, &stats.TotalEvents, &stats.TotalRegistrations, &stats.TotalNews)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch dashboard statistics")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch dashboard statistics")
 		return
 	}
 
@@ -63,7 +64,7 @@ func GetClubActivityMetrics(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch club metrics")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club metrics")
 		return
 	}
 	defer rows.Close()
@@ -74,7 +75,8 @@ func GetClubActivityMetrics(c *gin.Context) {
 		var metric models.ClubActivityMetrics
 		err := rows.Scan(&metric.ClubID, &metric.ClubName, &metric.MemberCount, &metric.TotalEvents, &metric.TotalRegistrations, &metric.TotalNews)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch club metrics")
+			return
 		}
 		metrics = append(metrics, metric)
 	}
@@ -107,7 +109,7 @@ func GetUserEngagementStats(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch user engagement stats")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user engagement stats")
 		return
 	}
 	defer rows.Close()
@@ -118,7 +120,8 @@ func GetUserEngagementStats(c *gin.Context) {
 		var stat models.UserEngagementStats
 		err := rows.Scan(&stat.UserID, &stat.FirstName, &stat.LastName, &stat.Email, &stat.ClubsJoined, &stat.EventsAttended, &stat.FeedbackGiven)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch user engagement stats")
+			return
 		}
 		stats = append(stats, stat)
 	}
@@ -145,7 +148,7 @@ func GetRegistrationTrends(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch registration trends")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch registration trends")
 		return
 	}
 	defer rows.Close()
@@ -162,7 +165,8 @@ func GetRegistrationTrends(c *gin.Context) {
 		var month sql.NullTime
 		err := rows.Scan(&month, &trend.Registrations)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch registration trends")
+			return
 		}
 		if month.Valid {
 			trend.Month = month.Time.Format("2006-01")
@@ -191,7 +195,7 @@ func GetMostPopularEvents(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch popular events")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch popular events")
 		return
 	}
 	defer rows.Close()
@@ -211,7 +215,8 @@ func GetMostPopularEvents(c *gin.Context) {
 		var avgRating sql.NullFloat64
 		err := rows.Scan(&event.EventID, &event.Title, &event.ClubName, &event.RegistrationCount, &avgRating)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch popular events")
+			return
 		}
 		if avgRating.Valid {
 			event.AverageRating = &avgRating.Float64
@@ -222,6 +227,21 @@ func GetMostPopularEvents(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Popular events retrieved", events)
 }
 
+// recentActivityList is how the admin activity feed can be sorted and filtered
+var recentActivityList = listing.Spec{
+	Key: "al.log_id",
+	Sorts: map[string]listing.Sort{
+		"created_at": {Column: "al.created_at", Type: "timestamptz"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"action":      {Column: "al.action", Kind: listing.Equals},
+		"entity_type": {Column: "al.entity_type", Kind: listing.Equals},
+		"user_id":     {Column: "al.user_id", Kind: listing.IntEquals},
+	},
+	DefaultLimit: 50,
+}
+
 // GetRecentActivity retrieves recent activity logs
 func GetRecentActivity(c *gin.Context) {
 	role, _ := c.Get("role")
@@ -230,19 +250,31 @@ func GetRecentActivity(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &recentActivityList)
+	if page == nil {
+		return
+	}
+
+	total, err := page.Count(database.DB, "activity_log al")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch activity logs")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			al.log_id, al.action, al.entity_type, al.created_at,
 			u.first_name || ' ' || u.last_name as user_name,
-			al.details
+			al.details,
+			`+page.CursorColumns()+`
 		 FROM activity_log al
-		 JOIN users u ON al.user_id = u.user_id
-		 ORDER BY al.created_at DESC
-		 LIMIT 50`,
+		 JOIN users u ON al.user_id = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch activity logs")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch activity logs")
 		return
 	}
 	defer rows.Close()
@@ -261,9 +293,15 @@ func GetRecentActivity(c *gin.Context) {
 	for rows.Next() {
 		var activity ActivityEntry
 		var details sql.NullString
-		err := rows.Scan(&activity.LogID, &activity.Action, &activity.EntityType, &activity.CreatedAt, &activity.UserName, &details)
+		var cursor listing.Cursor
+		err := rows.Scan(&activity.LogID, &activity.Action, &activity.EntityType, &activity.CreatedAt, &activity.UserName, &details,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch activity logs")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		if details.Valid {
 			activity.Details = details.String
@@ -271,7 +309,20 @@ func GetRecentActivity(c *gin.Context) {
 		activities = append(activities, activity)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Recent activity retrieved", activities)
+	utils.PageResponse(c, http.StatusOK, "Recent activity retrieved", activities, page.Meta(total))
+}
+
+// eventSearchList is how event search results can be sorted and filtered
+var eventSearchList = listing.Spec{
+	Key: "e.event_id",
+	Sorts: map[string]listing.Sort{
+		"start_datetime": {Column: "e.start_datetime", Type: "timestamptz"},
+		"title":          {Column: "e.title", Type: "text"},
+	},
+	DefaultSort: "start_datetime",
+	Filters: map[string]listing.Filter{
+		"club_id": {Column: "e.club_id", Kind: listing.IntEquals},
+	},
 }
 
 // SearchEvents searches for events by keyword
@@ -282,23 +333,33 @@ func SearchEvents(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &eventSearchList)
+	if page == nil {
+		return
+	}
+	pattern := page.Arg("%" + keyword + "%")
+	page.Where("e.status = 'approved'")
+	page.Where("(e.title ILIKE " + pattern + " OR e.description ILIKE " + pattern + ")")
+
+	total, err := page.Count(database.DB, "events e")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to search events")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			e.event_id, e.title, e.description, e.start_datetime,
-			c.club_name, c.club_code
+			c.club_name, c.club_code,
+			`+page.CursorColumns()+`
 		 FROM events e
-		 JOIN clubs c ON e.club_id = c.club_id
-		 WHERE e.status = 'approved' 
-		     AND (
-		         e.title ILIKE '%' || $1 || '%' 
-		         OR e.description ILIKE '%' || $1 || '%'
-		     )
-		 ORDER BY e.start_datetime`,
-		keyword,
+		 JOIN clubs c ON e.club_id = c.club_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to search events")
+		utils.InternalServerErrorResponse(c, err, "Failed to search events")
 		return
 	}
 	defer rows.Close()
@@ -316,14 +377,34 @@ func SearchEvents(c *gin.Context) {
 
 	for rows.Next() {
 		var result SearchResult
-		err := rows.Scan(&result.EventID, &result.Title, &result.Description, &result.StartDatetime, &result.ClubName, &result.ClubCode)
+		var cursor listing.Cursor
+		err := rows.Scan(&result.EventID, &result.Title, &result.Description, &result.StartDatetime, &result.ClubName, &result.ClubCode,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to search events")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		results = append(results, result)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Search results retrieved", results)
+	utils.PageResponse(c, http.StatusOK, "Search results retrieved", results, page.Meta(total))
+}
+
+// newsSearchList is how news search results can be sorted and filtered
+var newsSearchList = listing.Spec{
+	Key: "n.news_id",
+	Sorts: map[string]listing.Sort{
+		"published_at": {Column: "COALESCE(n.published_at, n.created_at)", Type: "timestamptz"},
+		"title":        {Column: "n.title", Type: "text"},
+	},
+	DefaultSort: "-published_at",
+	Filters: map[string]listing.Filter{
+		"club_id":  {Column: "n.club_id", Kind: listing.IntEquals},
+		"category": {Column: "n.category", Kind: listing.Equals},
+	},
 }
 
 // SearchNews searches for news by keyword
@@ -334,23 +415,33 @@ func SearchNews(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &newsSearchList)
+	if page == nil {
+		return
+	}
+	pattern := page.Arg("%" + keyword + "%")
+	page.Where("n.status = 'published'")
+	page.Where("(n.title ILIKE " + pattern + " OR n.content ILIKE " + pattern + ")")
+
+	total, err := page.Count(database.DB, "news n")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to search news")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			n.news_id, n.title, n.content, n.published_at,
-			c.club_name
+			c.club_name,
+			`+page.CursorColumns()+`
 		 FROM news n
-		 JOIN clubs c ON n.club_id = c.club_id
-		 WHERE n.status = 'published'
-		     AND (
-		         n.title ILIKE '%' || $1 || '%' 
-		         OR n.content ILIKE '%' || $1 || '%'
-		     )
-		 ORDER BY n.published_at DESC`,
-		keyword,
+		 JOIN clubs c ON n.club_id = c.club_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to search news")
+		utils.InternalServerErrorResponse(c, err, "Failed to search news")
 		return
 	}
 	defer rows.Close()
@@ -367,12 +458,18 @@ func SearchNews(c *gin.Context) {
 
 	for rows.Next() {
 		var result SearchResult
-		err := rows.Scan(&result.NewsID, &result.Title, &result.Content, &result.PublishedAt, &result.ClubName)
+		var cursor listing.Cursor
+		err := rows.Scan(&result.NewsID, &result.Title, &result.Content, &result.PublishedAt, &result.ClubName,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to search news")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		results = append(results, result)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Search results retrieved", results)
+	utils.PageResponse(c, http.StatusOK, "Search results retrieved", results, page.Meta(total))
 }
@@ -7,7 +7,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/notifier"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
@@ -33,12 +35,19 @@ func CreateSystemAnnouncement(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create announcement")
+		return
+	}
+	defer tx.Rollback()
+
 	var announcementID int
-	err := database.DB.QueryRow(
+	err = tx.QueryRow(
 		`INSERT INTO system_announcements (created_by, title, content, priority, expires_at)
 		 VALUES ($1, $2, $3, $4, $5)
 		 RETURNING announcement_id`,
@@ -46,7 +55,27 @@ func CreateSystemAnnouncement(c *gin.Context) {
 	).Scan(&announcementID)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create announcement")
+		utils.AppErrorResponse(c, err, "Failed to create announcement")
+		return
+	}
+
+	// Every active user gets the announcement in their notifications
+	err = notifier.ToAllUsers(tx, notifier.Notification{
+		Kind:       notifier.SystemAnnouncement,
+		EntityType: "announcement",
+		EntityID:   announcementID,
+		Data: map[string]interface{}{
+			"Title":   req.Title,
+			"Content": req.Content,
+		},
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to send notifications")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create announcement")
 		return
 	}
 
@@ -58,32 +87,63 @@ func CreateSystemAnnouncement(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Announcement created successfully", response)
 }
 
-// GetSystemAnnouncements retrieves all active system announcements
+// announcementList is how the active announcements can be sorted and filtered
+var announcementList = listing.Spec{
+	Key: "sa.announcement_id",
+	Sorts: map[string]listing.Sort{
+		"created_at": {Column: "sa.created_at", Type: "timestamptz"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"priority": {Column: "sa.priority", Kind: listing.Equals},
+	},
+}
+
+// GetSystemAnnouncements retrieves the active system announcements
 func GetSystemAnnouncements(c *gin.Context) {
+	page := parsePage(c, &announcementList)
+	if page == nil {
+		return
+	}
+	page.Where("sa.is_active = TRUE AND (sa.expires_at IS NULL OR sa.expires_at > CURRENT_TIMESTAMP)")
+
+	total, err := page.Count(database.DB, "system_announcements sa")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch announcements")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			sa.announcement_id, sa.title, sa.content, sa.priority, sa.created_at, sa.expires_at,
-			u.first_name || ' ' || u.last_name as created_by_name
+			u.first_name || ' ' || u.last_name as created_by_name,
+			`+page.CursorColumns()+`
 		 FROM system_announcements sa
-		 LEFT JOIN users u ON sa.created_by = u.user_id
-		 WHERE sa.is_active = TRUE AND (sa.expires_at IS NULL OR sa.expires_at > CURRENT_TIMESTAMP)
-		 ORDER BY sa.created_at DESC`,
+		 LEFT JOIN users u ON sa.created_by = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch announcements")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch announcements")
 		return
 	}
 	defer rows.Close()
 
-	var announcements []models.SystemAnnouncement
+	announcements := make([]models.SystemAnnouncement, 0)
 
 	for rows.Next() {
 		var ann models.SystemAnnouncement
 		var createdByName sql.NullString
-		err := rows.Scan(&ann.AnnouncementID, &ann.Title, &ann.Content, &ann.Priority, &ann.CreatedAt, &ann.ExpiresAt, &createdByName)
+		var cursor listing.Cursor
+		err := rows.Scan(&ann.AnnouncementID, &ann.Title, &ann.Content, &ann.Priority, &ann.CreatedAt, &ann.ExpiresAt, &createdByName,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch announcements")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		if createdByName.Valid {
 			ann.CreatedByName = createdByName.String
@@ -91,7 +151,7 @@ func GetSystemAnnouncements(c *gin.Context) {
 		announcements = append(announcements, ann)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "System announcements retrieved", announcements)
+	utils.PageResponse(c, http.StatusOK, "System announcements retrieved", announcements, page.Meta(total))
 }
 
 // GetAnnouncementDetails retrieves a specific announcement
@@ -120,7 +180,7 @@ func GetAnnouncementDetails(c *gin.Context) {
 			utils.NotFoundResponse(c, "Announcement not found")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch announcement")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch announcement")
 		return
 	}
 
@@ -154,7 +214,7 @@ func UpdateSystemAnnouncement(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -170,7 +230,7 @@ func UpdateSystemAnnouncement(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update announcement")
+		utils.AppErrorResponse(c, err, "Failed to update announcement")
 		return
 	}
 
@@ -197,7 +257,7 @@ func DeleteSystemAnnouncement(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete announcement")
+		utils.InternalServerErrorResponse(c, err, "Failed to delete announcement")
 		return
 	}
 
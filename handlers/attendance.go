@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/lifecycle"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+const (
+	// maxAttendanceImportBytes bounds the size of a bulk attendance upload
+	maxAttendanceImportBytes = 2 << 20
+	// maxAttendanceImportRows bounds the number of attendees in one import
+	maxAttendanceImportRows = 5000
+)
+
+// Outcomes of a bulk attendance row
+const (
+	attendanceMarked        = "marked"
+	attendanceAlreadyMarked = "already_marked"
+	attendanceDuplicate     = "duplicate"
+	attendanceUnknownUser   = "unknown_user"
+	attendanceAmbiguous     = "ambiguous"
+	attendanceNotRegistered = "not_registered"
+	attendanceCancelled     = "cancelled"
+	attendanceWaitlisted    = "waitlisted"
+	attendanceBlank         = "blank"
+)
+
+// attendanceRow is one attendee of a bulk attendance import and what became
+// of it
+type attendanceRow struct {
+	Row        int    `json:"row"`
+	Identifier string `json:"identifier"`
+	Result     string `json:"result"`
+	UserID     *int   `json:"user_id,omitempty"`
+	StudentID  string `json:"student_id,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+// BulkMarkAttendance marks attendance for a list of attendees identified by
+// student ID or email. The list is a CSV file, sent as the body or as the
+// "file" field of a multipart form, or JSON of the form
+// {"identifiers": [...]}. With dry_run=true nothing is changed and the report
+// shows what would happen.
+func BulkMarkAttendance(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			utils.BadRequestResponse(c, "dry_run must be true or false")
+			return
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAttendanceImportBytes)
+	rows, err := readAttendanceRows(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("The attendance list exceeds the %d MB limit", maxAttendanceImportBytes>>20), "file_too_large")
+			return
+		}
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		utils.BadRequestResponse(c, "The attendance list is empty")
+		return
+	}
+	if len(rows) > maxAttendanceImportRows {
+		utils.BadRequestResponse(c, fmt.Sprintf("The attendance list can have at most %d rows", maxAttendanceImportRows))
+		return
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+		return
+	}
+	defer tx.Rollback()
+
+	// The event's row is locked so its status can't change during the import
+	var eventStatus string
+	err = tx.QueryRow(`SELECT status FROM events WHERE event_id = $1 FOR UPDATE`, eventID).Scan(&eventStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Event not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+		return
+	}
+	if !lifecycle.TakesAttendance(eventStatus) {
+		utils.ErrorResponse(c, http.StatusConflict, "This event is "+eventStatus+", not open for attendance", "event_not_approved")
+		return
+	}
+
+	identifiers := make([]string, len(rows))
+	for i, row := range rows {
+		identifiers[i] = row.Identifier
+	}
+
+	// Identifiers containing @ are emails, the rest student IDs
+	matches, err := tx.Query(
+		`SELECT i.n, u.user_id, u.student_id, u.first_name || ' ' || u.last_name,
+			er.registration_id, er.registration_status, er.attendance_marked
+		 FROM unnest($1::text[]) WITH ORDINALITY AS i(identifier, n)
+		 JOIN users u ON CASE WHEN position('@' IN i.identifier) > 0
+			THEN lower(u.email) = lower(i.identifier)
+			ELSE u.student_id = i.identifier END
+		 LEFT JOIN event_registrations er ON er.user_id = u.user_id AND er.event_id = $2
+		 ORDER BY i.n`,
+		pq.Array(identifiers), eventID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+		return
+	}
+
+	type match struct {
+		userID         int
+		studentID      string
+		name           string
+		registrationID *int
+		status         *string
+		marked         *bool
+	}
+	found := make(map[int]match)
+	// Emails are compared case-insensitively, so one can match several users
+	ambiguous := make(map[int]bool)
+	for matches.Next() {
+		var n int
+		var m match
+		if err := matches.Scan(&n, &m.userID, &m.studentID, &m.name, &m.registrationID, &m.status, &m.marked); err != nil {
+			matches.Close()
+			utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+			return
+		}
+		if _, ok := found[n-1]; ok {
+			ambiguous[n-1] = true
+		}
+		found[n-1] = m
+	}
+	matches.Close()
+	if err := matches.Err(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+		return
+	}
+
+	summary := make(map[string]int)
+	seen := make(map[int]bool)
+	var toMark []int64
+	for i := range rows {
+		row := &rows[i]
+		m, ok := found[i]
+		switch {
+		case row.Identifier == "":
+			row.Result = attendanceBlank
+		case !ok:
+			row.Result = attendanceUnknownUser
+		case ambiguous[i]:
+			row.Result = attendanceAmbiguous
+		default:
+			userID := m.userID
+			row.UserID = &userID
+			row.StudentID = m.studentID
+			row.Name = m.name
+
+			switch {
+			case seen[m.userID]:
+				row.Result = attendanceDuplicate
+			case m.registrationID == nil:
+				row.Result = attendanceNotRegistered
+			case *m.status == "cancelled":
+				row.Result = attendanceCancelled
+			case *m.status == "waitlist":
+				row.Result = attendanceWaitlisted
+			case *m.marked:
+				row.Result = attendanceAlreadyMarked
+			default:
+				row.Result = attendanceMarked
+				toMark = append(toMark, int64(*m.registrationID))
+			}
+			seen[m.userID] = true
+		}
+		summary[row.Result]++
+	}
+
+	if !dryRun && len(toMark) > 0 {
+		_, err = tx.Exec(
+			`UPDATE event_registrations SET attendance_marked = TRUE
+			 WHERE registration_id = ANY($1) AND registration_status IN ('confirmed', 'attended')`,
+			pq.Array(toMark),
+		)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+			return
+		}
+
+		err = logActivity(tx, userID.(int), "attendance_imported", "event", eventID, gin.H{
+			"marked": len(toMark),
+			"rows":   len(rows),
+		})
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to import attendance")
+			return
+		}
+	}
+
+	response := gin.H{
+		"dry_run": dryRun,
+		"total":   len(rows),
+		"summary": summary,
+		"rows":    rows,
+	}
+
+	message := "Attendance imported"
+	if dryRun {
+		message = "Attendance import checked; nothing was changed"
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, response)
+}
+
+// readAttendanceRows reads the attendee identifiers of a bulk attendance
+// request. Errors other than size limits are safe to show to clients.
+func readAttendanceRows(c *gin.Context) ([]attendanceRow, error) {
+	switch c.ContentType() {
+	case "application/json":
+		var req struct {
+			Identifiers []string `json:"identifiers" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+			return nil, errors.New("Invalid request body")
+		}
+		rows := make([]attendanceRow, len(req.Identifiers))
+		for i, identifier := range req.Identifiers {
+			rows[i] = attendanceRow{Row: i + 1, Identifier: strings.TrimSpace(identifier)}
+		}
+		return rows, nil
+
+	case "multipart/form-data":
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+			return nil, errors.New("A CSV file must be uploaded in the \"file\" field")
+		}
+		defer file.Close()
+		return readAttendanceCSV(file)
+
+	case "text/csv", "text/plain":
+		return readAttendanceCSV(c.Request.Body)
+
+	default:
+		return nil, errors.New("Send the attendance list as text/csv, a multipart file or JSON")
+	}
+}
+
+// readAttendanceCSV reads identifiers from a CSV file. When the first row is
+// a header naming a student_id or email column, identifiers are taken from
+// that column (student ID first when both are present); otherwise from the
+// first column. Rows are numbered as lines of the file.
+func readAttendanceCSV(r io.Reader) ([]attendanceRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("Invalid CSV on line %d", parseErr.StartLine)
+		}
+		return nil, errors.New("Invalid CSV")
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	studentCol, emailCol := -1, -1
+	for i, cell := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))) {
+		case "student_id", "student id":
+			studentCol = i
+		case "email":
+			emailCol = i
+		}
+	}
+
+	first := 0
+	if studentCol >= 0 || emailCol >= 0 {
+		first = 1
+	} else {
+		studentCol = 0
+	}
+
+	cell := func(record []string, col int) string {
+		if col < 0 || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(strings.TrimPrefix(record[col], "\ufeff"))
+	}
+
+	rows := make([]attendanceRow, 0, len(records)-first)
+	for i := first; i < len(records); i++ {
+		identifier := cell(records[i], studentCol)
+		if identifier == "" {
+			identifier = cell(records[i], emailCol)
+		}
+		rows = append(rows, attendanceRow{Row: i + 1, Identifier: identifier})
+	}
+	return rows, nil
+}
+
+// ExportEventRegistrations downloads an event's registrations as CSV,
+// including attendance and feedback
+func ExportEventRegistrations(c *gin.Context) {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	rows, err := database.DB.Query(
+		`SELECT
+			er.registration_id, u.user_id, u.student_id, u.first_name, u.last_name, u.email,
+			er.registration_status, er.registration_date, er.attendance_marked, er.checked_in_at,
+			er.feedback_submitted
+		 FROM event_registrations er
+		 JOIN users u ON er.user_id = u.user_id
+		 WHERE er.event_id = $1
+		 ORDER BY er.registration_date, er.registration_id`,
+		eventID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to export registrations")
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d-registrations.csv"`, eventID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"registration_id", "user_id", "student_id", "first_name", "last_name", "email",
+		"registration_status", "registration_date", "attendance_marked", "checked_in_at", "feedback_submitted",
+	})
+
+	for rows.Next() {
+		var registrationID, userID int
+		var studentID, firstName, lastName, email, status string
+		var registrationDate time.Time
+		var checkedInAt *time.Time
+		var attendanceMarked, feedbackSubmitted bool
+		err := rows.Scan(&registrationID, &userID, &studentID, &firstName, &lastName, &email,
+			&status, &registrationDate, &attendanceMarked, &checkedInAt, &feedbackSubmitted)
+		if err != nil {
+			// The response has started, so the export can only be cut short
+			break
+		}
+
+		checkedIn := ""
+		if checkedInAt != nil {
+			checkedIn = checkedInAt.UTC().Format(time.RFC3339)
+		}
+		w.Write([]string{
+			strconv.Itoa(registrationID), strconv.Itoa(userID),
+			csvSafe(studentID), csvSafe(firstName), csvSafe(lastName), csvSafe(email),
+			status, registrationDate.UTC().Format(time.RFC3339),
+			strconv.FormatBool(attendanceMarked), checkedIn, strconv.FormatBool(feedbackSubmitted),
+		})
+	}
+	w.Flush()
+}
+
+// csvSafe stops spreadsheet apps from running user-provided text as a
+// formula
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
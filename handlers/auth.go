@@ -3,11 +3,17 @@ package handlers
 import (
 	"database/sql"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/config"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/logging"
+	"github.com/nub-clubs-connect/nub_admin_api/mailer"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
@@ -16,14 +22,20 @@ func Register(c *gin.Context) {
 	var req models.RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	// Only addresses from allowed domains may register
+	if !utils.EmailDomainAllowed(req.Email, config.AppConfig.AllowedEmailDomains) {
+		utils.BadRequestResponse(c, "Registration is limited to "+strings.Join(config.AppConfig.AllowedEmailDomains, ", ")+" email addresses")
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to process password")
+		utils.InternalServerErrorResponse(c, err, "Failed to process password")
 		return
 	}
 
@@ -44,18 +56,19 @@ func Register(c *gin.Context) {
 	).Scan(&userID, &studentID, &email, &firstName, &lastName, &createdAt, &updatedAt)
 
 	if err != nil {
-		if err.Error() == "pq: duplicate key value violates unique constraint \"users_email_key\"" {
-			utils.ConflictResponse(c, "Email already registered")
-			return
-		}
-		utils.InternalServerErrorResponse(c, "Failed to register user")
+		utils.AppErrorResponse(c, err, "Failed to register user")
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(userID, email, "student")
+	// Email the verification link; the user can request another if this fails
+	if err := sendVerificationEmail(userID, email, firstName); err != nil {
+		logging.From(c).Error("Failed to send verification email", "user_id", userID, "error", err)
+	}
+
+	// Start a session and issue tokens
+	tokens, err := startSession(c, userID, email, "student")
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to generate token")
+		utils.InternalServerErrorResponse(c, err, "Failed to generate token")
 		return
 	}
 
@@ -66,9 +79,12 @@ func Register(c *gin.Context) {
 		"first_name": firstName,
 		"last_name": lastName,
 		"role": "student",
+		"email_verified": false,
 		"created_at": createdAt,
 		"updated_at": updatedAt,
-		"token": token,
+	}
+	for key, value := range tokens.responseFields() {
+		response[key] = value
 	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "User registered successfully", response)
@@ -79,25 +95,25 @@ func Login(c *gin.Context) {
 	var req models.LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
 	var user models.User
 
 	err := database.DB.QueryRow(
-		`SELECT user_id, student_id, email, password_hash, first_name, last_name, role, is_active, created_at, updated_at
+		`SELECT user_id, student_id, email, password_hash, first_name, last_name, role, is_active, email_verified, created_at, updated_at
 		 FROM users
 		 WHERE email = $1`,
 		req.Email,
-	).Scan(&user.UserID, &user.StudentID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.UserID, &user.StudentID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.IsActive, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.UnauthorizedResponse(c, "Invalid email or password")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to authenticate user")
+		utils.InternalServerErrorResponse(c, err, "Failed to authenticate user")
 		return
 	}
 
@@ -112,10 +128,10 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.UserID, user.Email, user.Role)
+	// Start a session and issue tokens
+	tokens, err := startSession(c, user.UserID, user.Email, user.Role)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to generate token")
+		utils.InternalServerErrorResponse(c, err, "Failed to generate token")
 		return
 	}
 
@@ -126,9 +142,12 @@ func Login(c *gin.Context) {
 		"first_name": user.FirstName,
 		"last_name": user.LastName,
 		"role": user.Role,
+		"email_verified": user.EmailVerified,
 		"created_at": user.CreatedAt,
 		"updated_at": user.UpdatedAt,
-		"token": token,
+	}
+	for key, value := range tokens.responseFields() {
+		response[key] = value
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Login successful", response)
@@ -142,21 +161,13 @@ func GetProfile(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-
-	err := database.DB.QueryRow(
-		`SELECT user_id, student_id, email, first_name, last_name, role, phone, profile_picture_url, is_active, created_at, updated_at
-		 FROM users
-		 WHERE user_id = $1`,
-		userID,
-	).Scan(&user.UserID, &user.StudentID, &user.Email, &user.FirstName, &user.LastName, &user.Role, &user.Phone, &user.ProfilePictureURL, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
-
+	user, err := stores.Users.Get(userID.(int))
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == store.ErrNotFound {
 			utils.NotFoundResponse(c, "User not found")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch user profile")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user profile")
 		return
 	}
 
@@ -174,29 +185,21 @@ func UpdateProfile(c *gin.Context) {
 	var req models.UpdateProfileRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	_, err := database.DB.Exec(
-		`UPDATE users
-		 SET first_name = COALESCE(NULLIF($1, ''), first_name),
-		     last_name = COALESCE(NULLIF($2, ''), last_name),
-		     phone = COALESCE(NULLIF($3, ''), phone),
-		     profile_picture_url = COALESCE(NULLIF($4, ''), profile_picture_url),
-		     updated_at = CURRENT_TIMESTAMP
-		 WHERE user_id = $5`,
-		req.FirstName, req.LastName, req.Phone, req.ProfilePictureURL, userID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update profile")
+	if err := stores.Users.UpdateProfile(userID.(int), req); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update profile")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Profile updated successfully", nil)
 }
 
+// passwordResetTTL is how long a password reset link stays valid
+const passwordResetTTL = time.Hour
+
 // ForgotPassword handles password reset request
 func ForgotPassword(c *gin.Context) {
 	var req struct {
@@ -204,15 +207,16 @@ func ForgotPassword(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
 	var userID int
+	var firstName string
 	err := database.DB.QueryRow(
-		`SELECT user_id FROM users WHERE email = $1`,
+		`SELECT user_id, first_name FROM users WHERE email = $1 AND is_active = TRUE`,
 		req.Email,
-	).Scan(&userID)
+	).Scan(&userID, &firstName)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -220,88 +224,93 @@ func ForgotPassword(c *gin.Context) {
 			utils.SuccessResponse(c, http.StatusOK, "If email exists, password reset link has been sent", nil)
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to process request")
+		utils.InternalServerErrorResponse(c, err, "Failed to process request")
 		return
 	}
 
 	// Generate reset token
 	token, err := utils.GenerateResetToken()
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to generate reset token")
+		utils.InternalServerErrorResponse(c, err, "Failed to generate reset token")
 		return
 	}
 
-	// Store token in database
+	// Store only the token's hash
 	_, err = database.DB.Exec(
-		`INSERT INTO password_reset_tokens (user_id, token, expires_at)
-		 VALUES ($1, $2, CURRENT_TIMESTAMP + INTERVAL '1 hour')`,
-		userID, token,
+		`INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		 VALUES ($1, $2, $3)`,
+		userID, utils.HashToken(token), time.Now().Add(passwordResetTTL),
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create reset token")
+		utils.InternalServerErrorResponse(c, err, "Failed to create reset token")
 		return
 	}
 
-	// In production, send email with reset link
+	// The email is sent in the background: waiting for the mail server would
+	// make this response measurably slower than the unknown-email one, which
+	// would reveal that the address is registered
+	resetURL := strings.TrimRight(config.AppConfig.AppBaseURL, "/") + "/reset-password?token=" + url.QueryEscape(token)
+	logger := logging.From(c)
+	email := req.Email
+	runInBackground(func() {
+		err := mailer.SendTemplate(email, "password_reset", gin.H{
+			"FirstName": firstName,
+			"ResetURL":  resetURL,
+			"ExpiresIn": "1 hour",
+		})
+		if err != nil {
+			logger.Error("Failed to send password reset email", "user_id", userID, "error", err)
+		}
+	})
+
 	utils.SuccessResponse(c, http.StatusOK, "If email exists, password reset link has been sent", nil)
 }
 
-// ResetPassword handles password reset
+// ResetPassword handles password reset. On success every outstanding reset
+// token for the user is invalidated and all sessions are revoked.
 func ResetPassword(c *gin.Context) {
-	var req struct {
-		Token       string `json:"token" binding:"required"`
-		NewPassword string `json:"new_password" binding:"required,min=6"`
-	}
+	var req models.ResetPasswordRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	// Validate token
-	var userID int
-	err := database.DB.QueryRow(
-		`SELECT user_id FROM password_reset_tokens
-		 WHERE token = $1 AND expires_at > CURRENT_TIMESTAMP AND is_used = FALSE`,
-		req.Token,
-	).Scan(&userID)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			utils.UnauthorizedResponse(c, "Invalid or expired reset token")
-			return
-		}
-		utils.InternalServerErrorResponse(c, "Failed to validate token")
-		return
-	}
-
-	// Hash new password
+	// Hash the new password before taking the token's lock
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to process password")
+		utils.InternalServerErrorResponse(c, err, "Failed to process password")
 		return
 	}
 
-	// Update password
-	_, err = database.DB.Exec(
-		`UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2`,
-		hashedPassword, userID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update password")
-		return
-	}
-
-	// Mark token as used
-	_, err = database.DB.Exec(
-		`UPDATE password_reset_tokens SET is_used = TRUE WHERE token = $1`,
-		req.Token,
-	)
+	// The password change, the spent tokens and the revoked sessions are saved
+	// together or not at all
+	err = stores.WithTx(func(tx *store.Stores) error {
+		userID, err := tx.Users.ClaimResetToken(utils.HashToken(req.Token))
+		if err != nil {
+			return err
+		}
+		if err := tx.Users.SetPassword(userID, hashedPassword); err != nil {
+			return err
+		}
+		// Spend this and every other outstanding token
+		if err := tx.Users.UseResetTokens(userID); err != nil {
+			return err
+		}
+		// Sign the user out everywhere, since the old password may be compromised
+		if err := tx.Users.RevokeSessions(userID); err != nil {
+			return err
+		}
+		return tx.Users.LogActivity(userID, "password_reset", "user", userID, nil)
+	})
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to mark token as used")
+		if err == store.ErrNotFound {
+			utils.UnauthorizedResponse(c, "Invalid or expired reset token")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to reset password")
 		return
 	}
 
@@ -0,0 +1,23 @@
+package handlers
+
+import "sync"
+
+// background tracks work handlers hand off so they can respond without
+// waiting for it
+var background sync.WaitGroup
+
+// runInBackground runs fn after the handler has returned. fn must not use
+// the request's gin context, which is reused once the response is written.
+func runInBackground(fn func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		fn()
+	}()
+}
+
+// WaitForBackground waits for the work handed off by handlers to finish, so
+// tests can see its effects and the server can drain it on shutdown
+func WaitForBackground() {
+	background.Wait()
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/ical"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// calendarStatuses are the event statuses published in calendars. Cancelled
+// events stay in feeds so subscribed calendars mark them cancelled.
+const calendarStatuses = "('approved', 'completed', 'cancelled')"
+
+// calendarHistory limits feeds to events that ended recently or are still to
+// come, so long-running subscriptions stay small
+const calendarHistory = "e.end_datetime > CURRENT_TIMESTAMP - INTERVAL '180 days'"
+
+const calendarEventColumns = `e.event_id, e.title, e.description, e.event_type, e.location,
+			e.start_datetime, e.end_datetime, e.status, e.sequence, e.created_at, e.updated_at,
+			c.club_name, c.club_code`
+
+// queryCalendarEvents fetches events for a calendar. The query selects
+// calendarEventColumns.
+func queryCalendarEvents(query string, args ...interface{}) ([]models.Event, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]models.Event, 0)
+	for rows.Next() {
+		var event models.Event
+		err := rows.Scan(&event.EventID, &event.Title, &event.Description, &event.EventType, &event.Location,
+			&event.StartDatetime, &event.EndDatetime, &event.Status, &event.Sequence, &event.CreatedAt, &event.UpdatedAt,
+			&event.ClubName, &event.ClubCode)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// writeCalendar sends the events as an iCalendar document
+func writeCalendar(c *gin.Context, name, filename string, events []models.Event) {
+	cal := ical.Calendar{
+		Name:   name,
+		Events: events,
+		EventURL: func(event models.Event) string {
+			return strings.TrimRight(config.AppConfig.AppBaseURL, "/") + "/events/" + strconv.Itoa(event.EventID)
+		},
+	}
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, ical.ContentType, cal.Bytes())
+}
+
+// GetEventCalendar returns a single event as an iCalendar file
+func GetEventCalendar(c *gin.Context) {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	events, err := queryCalendarEvents(
+		`SELECT `+calendarEventColumns+`
+		 FROM events e
+		 JOIN clubs c ON e.club_id = c.club_id
+		 WHERE e.event_id = $1 AND e.status IN `+calendarStatuses,
+		eventID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch event")
+		return
+	}
+	if len(events) == 0 {
+		utils.NotFoundResponse(c, "Event not found")
+		return
+	}
+
+	writeCalendar(c, events[0].Title, "event-"+strconv.Itoa(eventID)+".ics", events)
+}
+
+// GetClubCalendar returns a club's events as an iCalendar feed
+func GetClubCalendar(c *gin.Context) {
+	clubID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid club ID")
+		return
+	}
+
+	var clubName, clubCode string
+	err = database.DB.QueryRow(
+		`SELECT club_name, club_code FROM clubs WHERE club_id = $1`,
+		clubID,
+	).Scan(&clubName, &clubCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Club not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club")
+		return
+	}
+
+	events, err := queryCalendarEvents(
+		`SELECT `+calendarEventColumns+`
+		 FROM events e
+		 JOIN clubs c ON e.club_id = c.club_id
+		 WHERE e.club_id = $1 AND e.status IN `+calendarStatuses+` AND `+calendarHistory+`
+		 ORDER BY e.start_datetime`,
+		clubID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club events")
+		return
+	}
+
+	writeCalendar(c, clubName, strings.ToLower(clubCode)+".ics", events)
+}
+
+// GetPersonalCalendar returns the events a user is registered for as an
+// iCalendar feed. The feed is authenticated by the token in its URL, since
+// calendar apps can't send an Authorization header.
+func GetPersonalCalendar(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	var userID int
+	err := database.DB.QueryRow(
+		`UPDATE calendar_tokens ct SET last_used_at = CURRENT_TIMESTAMP
+		 FROM users u
+		 WHERE ct.token_hash = $1 AND u.user_id = ct.user_id AND u.is_active = TRUE
+		 RETURNING ct.user_id`,
+		utils.HashToken(token),
+	).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Calendar feed not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch calendar feed")
+		return
+	}
+
+	events, err := queryCalendarEvents(
+		`SELECT `+calendarEventColumns+`
+		 FROM event_registrations er
+		 JOIN events e ON er.event_id = e.event_id
+		 JOIN clubs c ON e.club_id = c.club_id
+		 WHERE er.user_id = $1 AND er.registration_status IN ('confirmed', 'attended')
+		   AND e.status IN `+calendarStatuses+` AND `+calendarHistory+`
+		 ORDER BY e.start_datetime`,
+		userID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch calendar feed")
+		return
+	}
+
+	writeCalendar(c, "My NUB club events", "my-events.ics", events)
+}
+
+// CreateCalendarFeed issues a personal calendar feed URL for the current
+// user. Any earlier URL stops working.
+func CreateCalendarFeed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create calendar feed")
+		return
+	}
+
+	_, err = database.DB.Exec(
+		`INSERT INTO calendar_tokens (user_id, token_hash)
+		 VALUES ($1, $2)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP, last_used_at = NULL`,
+		userID, utils.HashToken(token),
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create calendar feed")
+		return
+	}
+
+	feedURL := apiBaseURL(c) + "/api/calendar/" + token + ".ics"
+	response := gin.H{
+		"feed_url":   feedURL,
+		"webcal_url": "webcal://" + feedURL[strings.Index(feedURL, "://")+3:],
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Calendar feed created successfully", response)
+}
+
+// DeleteCalendarFeed revokes the current user's personal calendar feed URL
+func DeleteCalendarFeed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	result, err := database.DB.Exec(`DELETE FROM calendar_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to delete calendar feed")
+		return
+	}
+
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		utils.NotFoundResponse(c, "Calendar feed not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feed deleted successfully", nil)
+}
+
+// apiBaseURL returns the public URL of this API, taken from the request when
+// API_BASE_URL is not configured
+func apiBaseURL(c *gin.Context) string {
+	if config.AppConfig.APIBaseURL != "" {
+		return strings.TrimRight(config.AppConfig.APIBaseURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -2,15 +2,16 @@ package handlers
 
 import (
 	"database/sql"
-	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/notifier"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
@@ -31,20 +32,14 @@ func CreateClub(c *gin.Context) {
 	var req models.CreateClubRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	var clubID int
-	err := database.DB.QueryRow(
-		`INSERT INTO clubs (club_name, club_code, description, logo_url, cover_image_url, founded_date, email)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7)
-		 RETURNING club_id`,
-		req.ClubName, req.ClubCode, req.Description, req.LogoURL, req.CoverImageURL, req.FoundedDate, req.Email,
-	).Scan(&clubID)
+	clubID, err := stores.Clubs.Create(req)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create club")
+		utils.AppErrorResponse(c, err, "Failed to create club")
 		return
 	}
 
@@ -60,18 +55,43 @@ func CreateClub(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Club created successfully", response)
 }
 
-// GetAllClubs retrieves all clubs
+// clubList is how the club directory can be sorted and filtered
+var clubList = listing.Spec{
+	Key: "club_id",
+	Sorts: map[string]listing.Sort{
+		"club_name":  {Column: "club_name", Type: "text"},
+		"created_at": {Column: "created_at", Type: "timestamptz"},
+	},
+	DefaultSort: "club_name",
+	Filters: map[string]listing.Filter{
+		"q": {Column: "club_name", Kind: listing.Contains},
+	},
+}
+
+// GetAllClubs retrieves active clubs a page at a time
 func GetAllClubs(c *gin.Context) {
+	page := parsePage(c, &clubList)
+	if page == nil {
+		return
+	}
+	page.Where("is_active = TRUE")
+
+	total, err := page.Count(database.DB, "clubs")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch clubs")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
-		`SELECT club_id, club_name, club_code, description, logo_url, cover_image_url, is_active, created_at, updated_at
-		 FROM clubs
-		 WHERE is_active = TRUE
-		 ORDER BY club_name`,
+		`SELECT club_id, club_name, club_code, description, logo_url, cover_image_url, is_active, created_at, updated_at,
+			`+page.CursorColumns()+`
+		 FROM clubs`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		fmt.Printf("DEBUG: Query error: %v\n", err)
-		utils.InternalServerErrorResponse(c, "Failed to fetch clubs")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch clubs")
 		return
 	}
 	defer rows.Close()
@@ -82,10 +102,15 @@ func GetAllClubs(c *gin.Context) {
 		var club models.Club
 		var logoURL sql.NullString
 		var coverImageURL sql.NullString
-		err := rows.Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &club.Description, &logoURL, &coverImageURL, &club.IsActive, &club.CreatedAt, &club.UpdatedAt)
+		var cursor listing.Cursor
+		err := rows.Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &club.Description, &logoURL, &coverImageURL, &club.IsActive, &club.CreatedAt, &club.UpdatedAt,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			fmt.Printf("DEBUG: Scan error: %v\n", err)
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch clubs")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		if logoURL.Valid {
 			club.LogoURL = logoURL.String
@@ -96,8 +121,7 @@ func GetAllClubs(c *gin.Context) {
 		clubs = append(clubs, club)
 	}
 
-	fmt.Printf("DEBUG: Clubs found: %d\n", len(clubs))
-	utils.SuccessResponse(c, http.StatusOK, "Clubs retrieved successfully", clubs)
+	utils.PageResponse(c, http.StatusOK, "Clubs retrieved successfully", clubs, page.Meta(total))
 }
 
 // GetClubDetails retrieves club details with member and event counts
@@ -108,49 +132,35 @@ func GetClubDetails(c *gin.Context) {
 		return
 	}
 
-	var club models.Club
-	var memberCount int
-	var upcomingEvents int
-	var descNS sql.NullString
-	var logoNS sql.NullString
-	var coverNS sql.NullString
-	var foundedNT sql.NullTime
-	var emailNS sql.NullString
-
-	err = database.DB.QueryRow(
-		`SELECT 
-			c.club_id, c.club_name, c.club_code, c.description, c.logo_url, c.cover_image_url, c.founded_date, c.email, c.is_active, c.created_at, c.updated_at,
-			COUNT(DISTINCT cm.user_id) as member_count,
-			COUNT(DISTINCT e.event_id) FILTER (WHERE e.status = 'approved') as upcoming_events
-		 FROM clubs c
-		 LEFT JOIN club_members cm ON c.club_id = cm.club_id AND cm.is_active = TRUE
-		 LEFT JOIN events e ON c.club_id = e.club_id
-		 WHERE c.club_id = $1
-		 GROUP BY c.club_id`,
-		clubID,
-	).Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &descNS, &logoNS, &coverNS, &foundedNT, &emailNS, &club.IsActive, &club.CreatedAt, &club.UpdatedAt, &memberCount, &upcomingEvents)
-
+	club, err := stores.Clubs.Get(clubID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == store.ErrNotFound {
 			utils.NotFoundResponse(c, "Club not found")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch club details")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club details")
 		return
 	}
 
-	club.Description = models.NullString(descNS)
-	club.LogoURL = models.NullString(logoNS)
-	club.CoverImageURL = models.NullString(coverNS)
-	club.FoundedDate = models.NullTime(foundedNT)
-	club.Email = models.NullString(emailNS)
-	club.MemberCount = memberCount
-	club.UpcomingEvents = upcomingEvents
-
 	utils.SuccessResponse(c, http.StatusOK, "Club details retrieved", club)
 }
 
-// GetClubMembers retrieves all members of a club
+// memberList is how a club's members can be sorted and filtered
+var memberList = listing.Spec{
+	Key: "cm.membership_id",
+	Sorts: map[string]listing.Sort{
+		"joined_date": {Column: "cm.joined_date", Type: "timestamptz"},
+		"first_name":  {Column: "u.first_name", Type: "text"},
+	},
+	DefaultSort: "-joined_date",
+	Filters: map[string]listing.Filter{
+		"role": {Column: "cm.role", Kind: listing.Equals},
+		"q":    {Column: "u.first_name || ' ' || u.last_name", Kind: listing.Contains},
+	},
+	DefaultLimit: 50,
+}
+
+// GetClubMembers retrieves the active members of a club
 func GetClubMembers(c *gin.Context) {
 	clubID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -158,19 +168,32 @@ func GetClubMembers(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &memberList)
+	if page == nil {
+		return
+	}
+	page.Where("cm.club_id = " + page.Arg(clubID))
+	page.Where("cm.is_active = TRUE")
+
+	total, err := page.Count(database.DB, "club_members cm JOIN users u ON cm.user_id = u.user_id")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club members")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			u.user_id, u.first_name, u.last_name, u.email, u.profile_picture_url,
-			cm.role, cm.joined_date
+			cm.role, cm.joined_date,
+			`+page.CursorColumns()+`
 		 FROM club_members cm
-		 JOIN users u ON cm.user_id = u.user_id
-		 WHERE cm.club_id = $1 AND cm.is_active = TRUE
-		 ORDER BY cm.joined_date DESC`,
-		clubID,
+		 JOIN users u ON cm.user_id = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch club members")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club members")
 		return
 	}
 	defer rows.Close()
@@ -193,9 +216,15 @@ func GetClubMembers(c *gin.Context) {
 		var emailNS sql.NullString
 		var profileNS sql.NullString
 		var joinedNT sql.NullTime
-		err := rows.Scan(&member.UserID, &member.FirstName, &lastNameNS, &emailNS, &profileNS, &member.Role, &joinedNT)
+		var cursor listing.Cursor
+		err := rows.Scan(&member.UserID, &member.FirstName, &lastNameNS, &emailNS, &profileNS, &member.Role, &joinedNT,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch club members")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		member.LastName = models.NullString(lastNameNS)
 		member.Email = models.NullString(emailNS)
@@ -208,7 +237,7 @@ func GetClubMembers(c *gin.Context) {
 		members = append(members, member)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Club members retrieved", members)
+	utils.PageResponse(c, http.StatusOK, "Club members retrieved", members, page.Meta(total))
 }
 
 // JoinClub adds a user to a club
@@ -225,15 +254,8 @@ func JoinClub(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`INSERT INTO club_members (user_id, club_id, role)
-		 VALUES ($1, $2, 'member')
-		 ON CONFLICT (user_id, club_id) DO UPDATE SET is_active = TRUE, joined_date = CURRENT_TIMESTAMP`,
-		userID, clubID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to join club")
+	if err := stores.Clubs.Join(userID.(int), clubID); err != nil {
+		utils.AppErrorResponse(c, err, "Failed to join club")
 		return
 	}
 
@@ -257,13 +279,8 @@ func LeaveClub(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`UPDATE club_members SET is_active = FALSE WHERE user_id = $1 AND club_id = $2`,
-		userID, clubID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to leave club")
+	if err := stores.Clubs.Leave(userID.(int), clubID); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to leave club")
 		return
 	}
 
@@ -273,7 +290,18 @@ func LeaveClub(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Successfully left club", nil)
 }
 
-// GetUserClubs retrieves all clubs a user is a member of
+// userClubList is how the clubs a user belongs to can be sorted
+var userClubList = listing.Spec{
+	Key: "cm.membership_id",
+	Sorts: map[string]listing.Sort{
+		"joined_date": {Column: "cm.joined_date", Type: "timestamptz"},
+		"club_name":   {Column: "c.club_name", Type: "text"},
+	},
+	DefaultSort: "-joined_date",
+	DefaultLimit: 50,
+}
+
+// GetUserClubs retrieves the clubs a user is a member of
 func GetUserClubs(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -281,19 +309,32 @@ func GetUserClubs(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &userClubList)
+	if page == nil {
+		return
+	}
+	page.Where("cm.user_id = " + page.Arg(userID))
+	page.Where("cm.is_active = TRUE")
+
+	total, err := page.Count(database.DB, "club_members cm")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user clubs")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			c.club_id, c.club_name, c.club_code, c.description, c.logo_url,
-			cm.role, cm.joined_date
+			cm.role, cm.joined_date,
+			`+page.CursorColumns()+`
 		 FROM club_members cm
-		 JOIN clubs c ON cm.club_id = c.club_id
-		 WHERE cm.user_id = $1 AND cm.is_active = TRUE
-		 ORDER BY cm.joined_date DESC`,
-		userID,
+		 JOIN clubs c ON cm.club_id = c.club_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch user clubs")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user clubs")
 		return
 	}
 	defer rows.Close()
@@ -312,14 +353,20 @@ func GetUserClubs(c *gin.Context) {
 
 	for rows.Next() {
 		var club ClubInfo
-		err := rows.Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &club.Description, &club.LogoURL, &club.Role, &club.JoinedDate)
+		var cursor listing.Cursor
+		err := rows.Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &club.Description, &club.LogoURL, &club.Role, &club.JoinedDate,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch user clubs")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		clubs = append(clubs, club)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "User clubs retrieved", clubs)
+	utils.PageResponse(c, http.StatusOK, "User clubs retrieved", clubs, page.Meta(total))
 }
 
 // AssignModerator assigns a moderator to a club
@@ -341,11 +388,29 @@ func AssignModerator(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to assign moderator")
+		return
+	}
+	defer tx.Rollback()
+
+	var clubName string
+	err = tx.QueryRow(`SELECT club_name FROM clubs WHERE club_id = $1`, clubID).Scan(&clubName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Club not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to assign moderator")
 		return
 	}
 
-	_, err = database.DB.Exec(
+	result, err := tx.Exec(
 		`INSERT INTO club_moderators (user_id, club_id)
 		 VALUES ($1, $2)
 		 ON CONFLICT (user_id, club_id) DO NOTHING`,
@@ -353,14 +418,43 @@ func AssignModerator(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to assign moderator")
+		utils.AppErrorResponse(c, err, "Failed to assign moderator")
+		return
+	}
+
+	// Only notify on a new assignment, not when repeating an existing one
+	if added, _ := result.RowsAffected(); added > 0 {
+		err = notifier.ToUser(tx, req.UserID, notifier.Notification{
+			Kind:       notifier.ModeratorAssigned,
+			EntityType: "club",
+			EntityID:   clubID,
+			Data:       map[string]interface{}{"ClubName": clubName},
+		})
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to assign moderator")
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to assign moderator")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Moderator assigned successfully", nil)
 }
 
-// GetClubModerators retrieves all moderators for a club
+// moderatorList is how a club's moderators can be sorted
+var moderatorList = listing.Spec{
+	Key: "cm.moderator_id",
+	Sorts: map[string]listing.Sort{
+		"assigned_at": {Column: "cm.assigned_at", Type: "timestamptz"},
+	},
+	DefaultSort:  "assigned_at",
+	DefaultLimit: 50,
+}
+
+// GetClubModerators retrieves the active moderators of a club
 func GetClubModerators(c *gin.Context) {
 	clubID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -368,16 +462,30 @@ func GetClubModerators(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &moderatorList)
+	if page == nil {
+		return
+	}
+	page.Where("cm.club_id = " + page.Arg(clubID))
+	page.Where("u.is_active = TRUE")
+
+	total, err := page.Count(database.DB, "club_moderators cm JOIN users u ON cm.user_id = u.user_id")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch moderators")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
-		`SELECT u.user_id, u.first_name, u.last_name, u.email, cm.assigned_at
+		`SELECT u.user_id, u.first_name, u.last_name, u.email, cm.assigned_at,
+			`+page.CursorColumns()+`
 		 FROM club_moderators cm
-		 JOIN users u ON cm.user_id = u.user_id
-		 WHERE cm.club_id = $1 AND u.is_active = TRUE`,
-		clubID,
+		 JOIN users u ON cm.user_id = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch moderators")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch moderators")
 		return
 	}
 	defer rows.Close()
@@ -390,16 +498,21 @@ func GetClubModerators(c *gin.Context) {
 		AssignedAt string `json:"assigned_at"`
 	}
 
-	var moderators []Moderator
+	moderators := make([]Moderator, 0)
 
 	for rows.Next() {
 		var mod Moderator
 		var lastNameNS sql.NullString
 		var emailNS sql.NullString
 		var assignedNT sql.NullTime
-		err := rows.Scan(&mod.UserID, &mod.FirstName, &lastNameNS, &emailNS, &assignedNT)
+		var cursor listing.Cursor
+		err := rows.Scan(&mod.UserID, &mod.FirstName, &lastNameNS, &emailNS, &assignedNT, &cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch moderators")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		mod.LastName = models.NullString(lastNameNS)
 		mod.Email = models.NullString(emailNS)
@@ -411,7 +524,7 @@ func GetClubModerators(c *gin.Context) {
 		moderators = append(moderators, mod)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Club moderators retrieved", moderators)
+	utils.PageResponse(c, http.StatusOK, "Club moderators retrieved", moderators, page.Meta(total))
 }
 
 // ActivateClub sets a club to active (admin only)
@@ -434,12 +547,9 @@ func ActivateClub(c *gin.Context) {
         return
     }
 
-    _, err = database.DB.Exec(
-        `UPDATE clubs SET is_active = TRUE, updated_at = CURRENT_TIMESTAMP WHERE club_id = $1`,
-        clubID,
-    )
+    err = stores.Clubs.SetActive(clubID, true)
     if err != nil {
-        utils.InternalServerErrorResponse(c, "Failed to activate club")
+        utils.InternalServerErrorResponse(c, err, "Failed to activate club")
         return
     }
 
@@ -468,12 +578,9 @@ func DeactivateClub(c *gin.Context) {
         return
     }
 
-    _, err = database.DB.Exec(
-        `UPDATE clubs SET is_active = FALSE, updated_at = CURRENT_TIMESTAMP WHERE club_id = $1`,
-        clubID,
-    )
+    err = stores.Clubs.SetActive(clubID, false)
     if err != nil {
-        utils.InternalServerErrorResponse(c, "Failed to deactivate club")
+        utils.InternalServerErrorResponse(c, err, "Failed to deactivate club")
         return
     }
 
@@ -482,6 +589,21 @@ func DeactivateClub(c *gin.Context) {
     utils.SuccessResponse(c, http.StatusOK, "Club deactivated successfully", nil)
 }
 
+// adminClubList is how the admin club list can be sorted and filtered
+var adminClubList = listing.Spec{
+    Key: "club_id",
+    Sorts: map[string]listing.Sort{
+        "club_name":  {Column: "club_name", Type: "text"},
+        "created_at": {Column: "created_at", Type: "timestamptz"},
+    },
+    DefaultSort: "club_name",
+    Filters: map[string]listing.Filter{
+        "q":         {Column: "club_name", Kind: listing.Contains},
+        "is_active": {Column: "is_active", Kind: listing.BoolEquals},
+    },
+    DefaultLimit: 50,
+}
+
 // AdminGetAllClubs retrieves all clubs (active and inactive) for admin
 func AdminGetAllClubs(c *gin.Context) {
     role, _ := c.Get("role")
@@ -490,13 +612,26 @@ func AdminGetAllClubs(c *gin.Context) {
         return
     }
 
+    page := parsePage(c, &adminClubList)
+    if page == nil {
+        return
+    }
+
+    total, err := page.Count(database.DB, "clubs")
+    if err != nil {
+        utils.InternalServerErrorResponse(c, err, "Failed to fetch clubs")
+        return
+    }
+
+    where, orderLimit, args := page.PageSQL()
     rows, err := database.DB.Query(
-        `SELECT club_id, club_name, club_code, description, logo_url, cover_image_url, is_active, created_at, updated_at
-         FROM clubs
-         ORDER BY club_name`,
+        `SELECT club_id, club_name, club_code, description, logo_url, cover_image_url, is_active, created_at, updated_at,
+            `+page.CursorColumns()+`
+         FROM clubs`+where+orderLimit,
+        args...,
     )
     if err != nil {
-        utils.InternalServerErrorResponse(c, "Failed to fetch clubs")
+        utils.InternalServerErrorResponse(c, err, "Failed to fetch clubs")
         return
     }
     defer rows.Close()
@@ -506,9 +641,15 @@ func AdminGetAllClubs(c *gin.Context) {
         var club models.Club
         var logoURL sql.NullString
         var coverImageURL sql.NullString
-        err := rows.Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &club.Description, &logoURL, &coverImageURL, &club.IsActive, &club.CreatedAt, &club.UpdatedAt)
+        var cursor listing.Cursor
+        err := rows.Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &club.Description, &logoURL, &coverImageURL, &club.IsActive, &club.CreatedAt, &club.UpdatedAt,
+            &cursor.Value, &cursor.Key)
         if err != nil {
-            continue
+            utils.InternalServerErrorResponse(c, err, "Failed to fetch clubs")
+            return
+        }
+        if !page.Add(cursor) {
+            break
         }
         if logoURL.Valid {
             club.LogoURL = logoURL.String
@@ -519,20 +660,16 @@ func AdminGetAllClubs(c *gin.Context) {
         clubs = append(clubs, club)
     }
 
-    utils.SuccessResponse(c, http.StatusOK, "All clubs retrieved", clubs)
+    utils.PageResponse(c, http.StatusOK, "All clubs retrieved", clubs, page.Meta(total))
 }
 
 // Helper function to log activity
 func LogActivity(userID int, action, entityType string, entityID int, details interface{}) {
-	var detailsJSON string
-	if details != nil {
-		jsonBytes, _ := json.Marshal(details)
-		detailsJSON = string(jsonBytes)
-	}
+	stores.Users.LogActivity(userID, action, entityType, entityID, details)
+}
 
-	database.DB.Exec(
-		`INSERT INTO activity_log (user_id, action, entity_type, entity_id, details)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		userID, action, entityType, entityID, detailsJSON,
-	)
+// logActivity writes an activity log entry using the given executor, which
+// lets callers record activity as part of a transaction
+func logActivity(exec database.Execer, userID int, action, entityType string, entityID int, details interface{}) error {
+	return store.LogActivity(exec, userID, action, entityType, entityID, details)
 }
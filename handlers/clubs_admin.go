@@ -26,8 +26,8 @@ func UpdateClub(c *gin.Context) {
 	}
 
 	type updateClubRequest struct {
-		ClubName      *string `json:"club_name"`
-		ClubCode      *string `json:"club_code"`
+		ClubName      *string `json:"club_name" binding:"omitempty,notblank,max=150"`
+		ClubCode      *string `json:"club_code" binding:"omitempty,club_code"`
 		Description   *string `json:"description"`
 		LogoURL       *string `json:"logo_url"`
 		CoverImageURL *string `json:"cover_image_url"`
@@ -37,7 +37,7 @@ func UpdateClub(c *gin.Context) {
 
 	var req updateClubRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -101,7 +101,7 @@ func UpdateClub(c *gin.Context) {
 
 	_, err = database.DB.Exec(query, args...)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update club")
+		utils.AppErrorResponse(c, err, "Failed to update club")
 		return
 	}
 
@@ -132,7 +132,7 @@ func RemoveModerator(c *gin.Context) {
 		userID, clubID,
 	)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to remove moderator")
+		utils.InternalServerErrorResponse(c, err, "Failed to remove moderator")
 		return
 	}
 
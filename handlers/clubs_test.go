@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/store/storetest"
+	"github.com/nub-clubs-connect/nub_admin_api/validation"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := validation.Register(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// signedIn is who a test request is sent as; the zero value is anonymous
+type signedIn struct {
+	userID int
+	role   string
+}
+
+// serve sends a request to a single handler mounted at route, as the auth
+// middleware would after signing the user in, and decodes the envelope
+func serve(t *testing.T, as signedIn, route string, handler gin.HandlerFunc, method, path, body string) (int, models.APIResponse) {
+	t.Helper()
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if as.userID != 0 {
+			c.Set("user_id", as.userID)
+			c.Set("role", as.role)
+		}
+	}, handler)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var envelope models.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("%s %s: %v\n%s", method, path, err, rec.Body.String())
+	}
+	return rec.Code, envelope
+}
+
+// useFakes points the handlers at fresh fakes for the length of a test
+func useFakes(t *testing.T) *storetest.Fakes {
+	fakes := storetest.New()
+	previous := stores
+	UseStores(fakes.Stores())
+	t.Cleanup(func() { UseStores(previous) })
+	return fakes
+}
+
+var (
+	student = signedIn{userID: 7, role: "student"}
+	admin   = signedIn{userID: 1, role: "system_admin"}
+)
+
+func TestGetClubDetails(t *testing.T) {
+	fakes := useFakes(t)
+	fakes.Clubs.Clubs[3] = &models.Club{ClubID: 3, ClubName: "Robotics Club", ClubCode: "NUBRC", IsActive: true}
+	fakes.Clubs.Members[storetest.Membership{UserID: 7, ClubID: 3}] = true
+	fakes.Clubs.Members[storetest.Membership{UserID: 8, ClubID: 3}] = false
+
+	tests := []struct {
+		name   string
+		path   string
+		err    error
+		status int
+		code   string
+	}{
+		{name: "found", path: "/clubs/3", status: http.StatusOK},
+		{name: "missing", path: "/clubs/4", status: http.StatusNotFound, code: "not_found"},
+		{name: "bad ID", path: "/clubs/three", status: http.StatusBadRequest, code: "bad_request"},
+		{name: "store failure", path: "/clubs/3", err: errors.New("connection reset"), status: http.StatusInternalServerError, code: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		fakes.Clubs.Err = tt.err
+		status, envelope := serve(t, signedIn{}, "/clubs/:id", GetClubDetails, "GET", tt.path, "")
+		if status != tt.status || envelope.Error != tt.code {
+			t.Errorf("%s: got %d %q, want %d %q", tt.name, status, envelope.Error, tt.status, tt.code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		data, _ := json.Marshal(envelope.Data)
+		var club models.Club
+		json.Unmarshal(data, &club)
+		if club.ClubCode != "NUBRC" || club.MemberCount != 1 {
+			t.Errorf("%s: got %+v, want the club with one active member", tt.name, club)
+		}
+	}
+}
+
+func TestMembership(t *testing.T) {
+	fakes := useFakes(t)
+	membership := storetest.Membership{UserID: student.userID, ClubID: 3}
+
+	if status, _ := serve(t, student, "/clubs/:id/join", JoinClub, "POST", "/clubs/3/join", ""); status != http.StatusOK {
+		t.Fatalf("joining got %d", status)
+	}
+	if !fakes.Clubs.Members[membership] {
+		t.Error("the membership wasn't made")
+	}
+
+	if status, _ := serve(t, student, "/clubs/:id/leave", LeaveClub, "POST", "/clubs/3/leave", ""); status != http.StatusOK {
+		t.Fatalf("leaving got %d", status)
+	}
+	if active, ok := fakes.Clubs.Members[membership]; !ok || active {
+		t.Error("the membership wasn't deactivated")
+	}
+
+	var actions []string
+	for _, entry := range fakes.Users.Activity {
+		actions = append(actions, entry.Action)
+	}
+	if strings.Join(actions, ",") != "club_joined,club_left" {
+		t.Errorf("got activity %v", actions)
+	}
+
+	status, envelope := serve(t, signedIn{}, "/clubs/:id/join", JoinClub, "POST", "/clubs/3/join", "")
+	if status != http.StatusUnauthorized || envelope.Error != "unauthorized" {
+		t.Errorf("joining anonymously got %d %q", status, envelope.Error)
+	}
+}
+
+func TestClubAdministration(t *testing.T) {
+	fakes := useFakes(t)
+
+	status, envelope := serve(t, admin, "/clubs", CreateClub, "POST", "/clubs", `{"club_name": "Chess Club", "club_code": "NUBCC"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("creating got %d %q", status, envelope.Message)
+	}
+	if len(fakes.Clubs.Clubs) != 1 || fakes.Clubs.Clubs[1].ClubCode != "NUBCC" {
+		t.Fatalf("got clubs %v", fakes.Clubs.Clubs)
+	}
+
+	status, envelope = serve(t, student, "/clubs", CreateClub, "POST", "/clubs", `{"club_name": "Go Club", "club_code": "NUBGC"}`)
+	if status != http.StatusForbidden || envelope.Error != "forbidden" || len(fakes.Clubs.Clubs) != 1 {
+		t.Errorf("a student creating a club got %d %q", status, envelope.Error)
+	}
+
+	if status, _ := serve(t, admin, "/clubs/:id/deactivate", DeactivateClub, "PUT", "/clubs/1/deactivate", ""); status != http.StatusOK {
+		t.Fatalf("deactivating got %d", status)
+	}
+	if fakes.Clubs.Clubs[1].IsActive {
+		t.Error("the club is still active")
+	}
+	if status, _ := serve(t, admin, "/clubs/:id/activate", ActivateClub, "PUT", "/clubs/1/activate", ""); status != http.StatusOK {
+		t.Fatalf("activating got %d", status)
+	}
+	if !fakes.Clubs.Clubs[1].IsActive {
+		t.Error("the club wasn't activated")
+	}
+}
@@ -0,0 +1,511 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/lifecycle"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/notifier"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+	"github.com/nub-clubs-connect/nub_admin_api/validation"
+)
+
+// eventTransitionErrorResponse writes the response for a failed event status
+// change
+func eventTransitionErrorResponse(c *gin.Context, err error, message string) {
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case err == sql.ErrNoRows:
+		utils.NotFoundResponse(c, "Event not found")
+	case errors.As(err, &transitionErr):
+		utils.ErrorResponse(c, http.StatusConflict,
+			"The event is "+transitionErr.From+" and can't be "+transitionErr.To, "invalid_status_transition")
+	default:
+		utils.InternalServerErrorResponse(c, err, message)
+	}
+}
+
+// editableEvent is the part of an event that UpdateEvent can change
+type editableEvent struct {
+	status               string
+	title                string
+	description          string
+	eventType            string
+	location             string
+	startDatetime        time.Time
+	endDatetime          time.Time
+	registrationDeadline *time.Time
+	capacity             int
+	isRegistrationOpen   bool
+	bannerImageURL       string
+}
+
+// UpdateEvent edits an event that is still pending, approved or rejected.
+// Changes to the title, description, type or location of an approved event
+// send it back for review, as does any change to a rejected event. The time
+// of an approved event is changed with RescheduleEvent so registrants are
+// told.
+func UpdateEvent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	var req models.UpdateEventRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update event")
+		return
+	}
+	defer tx.Rollback()
+
+	var event editableEvent
+	err = tx.QueryRow(
+		`SELECT status, title, description, event_type, location, start_datetime, end_datetime,
+			registration_deadline, capacity, is_registration_open, banner_image_url
+		 FROM events
+		 WHERE event_id = $1
+		 FOR UPDATE`,
+		eventID,
+	).Scan(&event.status, &event.title, &event.description, &event.eventType, &event.location,
+		&event.startDatetime, &event.endDatetime, &event.registrationDeadline, &event.capacity,
+		&event.isRegistrationOpen, &event.bannerImageURL)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Event not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to update event")
+		return
+	}
+
+	if !lifecycle.Editable(event.status) {
+		utils.ErrorResponse(c, http.StatusConflict, "A "+event.status+" event can't be edited", "event_not_editable")
+		return
+	}
+
+	previous := event
+	var changed []string
+	setString := func(field string, value *string, target *string) {
+		if value != nil && *value != *target {
+			*target = *value
+			changed = append(changed, field)
+		}
+	}
+	setString("title", req.Title, &event.title)
+	setString("description", req.Description, &event.description)
+	setString("event_type", req.EventType, &event.eventType)
+	setString("location", req.Location, &event.location)
+	setString("banner_image_url", req.BannerImageURL, &event.bannerImageURL)
+	if req.StartDatetime != nil && !req.StartDatetime.Equal(event.startDatetime) {
+		event.startDatetime = *req.StartDatetime
+		changed = append(changed, "start_datetime")
+	}
+	if req.EndDatetime != nil && !req.EndDatetime.Equal(event.endDatetime) {
+		event.endDatetime = *req.EndDatetime
+		changed = append(changed, "end_datetime")
+	}
+	if req.RegistrationDeadline != nil &&
+		(event.registrationDeadline == nil || !req.RegistrationDeadline.Equal(*event.registrationDeadline)) {
+		event.registrationDeadline = req.RegistrationDeadline
+		changed = append(changed, "registration_deadline")
+	}
+	if req.Capacity != nil && *req.Capacity != event.capacity {
+		event.capacity = *req.Capacity
+		changed = append(changed, "capacity")
+	}
+	if req.IsRegistrationOpen != nil && *req.IsRegistrationOpen != event.isRegistrationOpen {
+		event.isRegistrationOpen = *req.IsRegistrationOpen
+		changed = append(changed, "is_registration_open")
+	}
+
+	// The request's fields were validated as they were bound; these checks
+	// compare them with the fields left as they were
+	switch {
+	case !event.endDatetime.After(event.startDatetime):
+		utils.AppErrorResponse(c, validation.Field("end_datetime", "too_early", "must be after start_datetime"), "Failed to update event")
+		return
+	case event.registrationDeadline != nil && event.registrationDeadline.After(event.startDatetime):
+		utils.AppErrorResponse(c, validation.Field("registration_deadline", "too_late", "must not be after start_datetime"), "Failed to update event")
+		return
+	case event.status == lifecycle.Approved &&
+		(!event.startDatetime.Equal(previous.startDatetime) || !event.endDatetime.Equal(previous.endDatetime)):
+		utils.ErrorResponse(c, http.StatusConflict,
+			"Use the reschedule endpoint to change the time of an approved event", "use_reschedule")
+		return
+	}
+
+	if len(changed) == 0 {
+		utils.SuccessResponse(c, http.StatusOK, "No changes to save", gin.H{
+			"event_id": eventID,
+			"status":   event.status,
+			"changed":  []string{},
+		})
+		return
+	}
+
+	_, err = tx.Exec(
+		`UPDATE events
+		 SET title = $1, description = $2, event_type = $3, location = $4, start_datetime = $5, end_datetime = $6,
+			 registration_deadline = $7, capacity = $8, is_registration_open = $9, banner_image_url = $10,
+			 updated_at = CURRENT_TIMESTAMP
+		 WHERE event_id = $11`,
+		event.title, event.description, event.eventType, event.location, event.startDatetime, event.endDatetime,
+		event.registrationDeadline, event.capacity, event.isRegistrationOpen, event.bannerImageURL, eventID,
+	)
+	if err != nil {
+		utils.AppErrorResponse(c, err, "Failed to update event")
+		return
+	}
+
+	// Significant edits to a published event, and any revision of a rejected
+	// one, need another review
+	significant := event.title != previous.title || event.description != previous.description ||
+		event.eventType != previous.eventType || event.location != previous.location
+	status := event.status
+	if (status == lifecycle.Approved && significant) || status == lifecycle.Rejected {
+		editorID := userID.(int)
+		reason := "Edited " + strings.Join(changed, ", ")
+		if _, err := lifecycle.Transition(tx, eventID, lifecycle.Pending, &editorID, reason); err != nil {
+			eventTransitionErrorResponse(c, err, "Failed to update event")
+			return
+		}
+		if err := recordReview(tx, "event", eventID, reviewResubmitted, editorID, reason, nil); err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to update event")
+			return
+		}
+		status = lifecycle.Pending
+	}
+
+	if event.capacity > previous.capacity && status == lifecycle.Approved {
+		if err := fillFromWaitlist(tx, eventID); err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to promote waitlisted registrations")
+			return
+		}
+	}
+
+	if err := logActivity(tx, userID.(int), "event_updated", "event", eventID, gin.H{"changed": changed}); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update event")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update event")
+		return
+	}
+
+	response := gin.H{
+		"event_id":        eventID,
+		"status":          status,
+		"changed":         changed,
+		"review_required": status == lifecycle.Pending && previous.status != lifecycle.Pending,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event updated successfully", response)
+}
+
+// CancelEvent cancels a pending or approved event, closing registration and
+// notifying everyone registered
+func CancelEvent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	var req models.CancelEventRequest
+
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel event")
+		return
+	}
+	defer tx.Rollback()
+
+	cancelledBy := userID.(int)
+	reason := strings.TrimSpace(req.Reason)
+	if _, err := lifecycle.Transition(tx, eventID, lifecycle.Cancelled, &cancelledBy, reason); err != nil {
+		eventTransitionErrorResponse(c, err, "Failed to cancel event")
+		return
+	}
+
+	var title, clubName string
+	var startDatetime time.Time
+	err = tx.QueryRow(
+		`UPDATE events e
+		 SET is_registration_open = FALSE
+		 FROM clubs cl
+		 WHERE e.event_id = $1 AND cl.club_id = e.club_id
+		 RETURNING e.title, e.start_datetime, cl.club_name`,
+		eventID,
+	).Scan(&title, &startDatetime, &clubName)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel event")
+		return
+	}
+
+	err = notifier.ToEventRegistrants(tx, eventID, notifier.Notification{
+		Kind:       notifier.EventCancelled,
+		EntityType: "event",
+		EntityID:   eventID,
+		Data: map[string]interface{}{
+			"EventTitle": title,
+			"ClubName":   clubName,
+			"StartDate":  startDatetime.Format(notifier.DateFormat),
+			"Reason":     reason,
+		},
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to send notifications")
+		return
+	}
+
+	if err := logActivity(tx, cancelledBy, "event_cancelled", "event", eventID, gin.H{"reason": reason}); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel event")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel event")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event cancelled successfully", gin.H{
+		"event_id": eventID,
+		"status":   lifecycle.Cancelled,
+	})
+}
+
+// RescheduleEvent moves a pending or approved event to a new time and,
+// optionally, a new location, notifying everyone registered. Reminders are
+// sent again for the new time.
+func RescheduleEvent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	var req models.RescheduleEventRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	if !req.StartDatetime.After(time.Now()) {
+		utils.AppErrorResponse(c, validation.Field("start_datetime", "too_early", "must be in the future"), "Failed to reschedule event")
+		return
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to reschedule event")
+		return
+	}
+	defer tx.Rollback()
+
+	var status, title, location string
+	var oldStart, oldEnd time.Time
+	err = tx.QueryRow(
+		`SELECT status, title, location, start_datetime, end_datetime
+		 FROM events
+		 WHERE event_id = $1
+		 FOR UPDATE`,
+		eventID,
+	).Scan(&status, &title, &location, &oldStart, &oldEnd)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Event not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to reschedule event")
+		return
+	}
+
+	if status != lifecycle.Pending && status != lifecycle.Approved {
+		utils.ErrorResponse(c, http.StatusConflict, "A "+status+" event can't be rescheduled", "event_not_editable")
+		return
+	}
+
+	if req.Location != nil {
+		location = *req.Location
+	}
+
+	// A deadline that was only valid for the old time is dropped unless a
+	// new one is given
+	_, err = tx.Exec(
+		`UPDATE events
+		 SET start_datetime = $1, end_datetime = $2, location = $3,
+			 registration_deadline = CASE
+				WHEN $4::timestamptz IS NOT NULL THEN $4::timestamptz
+				WHEN registration_deadline > $1 THEN NULL
+				ELSE registration_deadline END,
+			 updated_at = CURRENT_TIMESTAMP
+		 WHERE event_id = $5`,
+		req.StartDatetime, req.EndDatetime, location, req.RegistrationDeadline, eventID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to reschedule event")
+		return
+	}
+
+	// Reminders already sent were for the old time
+	_, err = tx.Exec(
+		`DELETE FROM event_reminders WHERE event_id = $1 AND reminder_type IN ('24h', '1h')`,
+		eventID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to reschedule event")
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	err = notifier.ToEventRegistrants(tx, eventID, notifier.Notification{
+		Kind:       notifier.EventRescheduled,
+		EntityType: "event",
+		EntityID:   eventID,
+		Data: map[string]interface{}{
+			"EventTitle":   title,
+			"OldStartDate": oldStart.Format(notifier.DateFormat),
+			"StartDate":    req.StartDatetime.Format(notifier.DateFormat),
+			"Location":     location,
+			"Reason":       reason,
+		},
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to send notifications")
+		return
+	}
+
+	err = logActivity(tx, userID.(int), "event_rescheduled", "event", eventID, gin.H{
+		"old_start_datetime": oldStart,
+		"old_end_datetime":   oldEnd,
+		"start_datetime":     req.StartDatetime,
+		"end_datetime":       req.EndDatetime,
+		"reason":             reason,
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to reschedule event")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to reschedule event")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event rescheduled successfully", gin.H{
+		"event_id":       eventID,
+		"start_datetime": req.StartDatetime,
+		"end_datetime":   req.EndDatetime,
+		"location":       location,
+	})
+}
+
+// statusHistoryList is how an event's status history can be sorted
+var statusHistoryList = listing.Spec{
+	Key: "h.history_id",
+	Sorts: map[string]listing.Sort{
+		"changed_at": {Column: "h.changed_at", Type: "timestamptz"},
+	},
+	DefaultSort: "changed_at",
+}
+
+// GetEventStatusHistory retrieves the status changes of an event, oldest
+// first
+func GetEventStatusHistory(c *gin.Context) {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	page := parsePage(c, &statusHistoryList)
+	if page == nil {
+		return
+	}
+	page.Where("h.event_id = " + page.Arg(eventID))
+
+	total, err := page.Count(database.DB, "event_status_history h")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch event history")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
+	rows, err := database.DB.Query(
+		`SELECT
+			h.history_id, h.from_status, h.to_status, h.changed_by,
+			COALESCE(u.first_name || ' ' || u.last_name, ''), h.reason, h.changed_at,
+			`+page.CursorColumns()+`
+		 FROM event_status_history h
+		 LEFT JOIN users u ON h.changed_by = u.user_id`+where+orderLimit,
+		args...,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch event history")
+		return
+	}
+	defer rows.Close()
+
+	history := make([]models.EventStatusChange, 0)
+	for rows.Next() {
+		var change models.EventStatusChange
+		var cursor listing.Cursor
+		err := rows.Scan(&change.HistoryID, &change.FromStatus, &change.ToStatus, &change.ChangedBy,
+			&change.ChangedByName, &change.Reason, &change.ChangedAt, &cursor.Value, &cursor.Key)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch event history")
+			return
+		}
+		if !page.Add(cursor) {
+			break
+		}
+		history = append(history, change)
+	}
+
+	utils.PageResponse(c, http.StatusOK, "Event history retrieved", history, page.Meta(total))
+}
@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"database/sql"
-	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/lifecycle"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/notifier"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
@@ -23,12 +27,19 @@ func CreateEvent(c *gin.Context) {
 	var req models.CreateEventRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create event")
+		return
+	}
+	defer tx.Rollback()
+
 	var eventID int
-	err := database.DB.QueryRow(
+	err = tx.QueryRow(
 		`INSERT INTO events (club_id, created_by, title, description, event_type, location, start_datetime, end_datetime, registration_deadline, capacity, banner_image_url)
 		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		 RETURNING event_id`,
@@ -36,12 +47,25 @@ func CreateEvent(c *gin.Context) {
 	).Scan(&eventID)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create event")
+		utils.AppErrorResponse(c, err, "Failed to create event")
 		return
 	}
 
-	// Log activity
-	LogActivity(userID.(int), "event_created", "event", eventID, nil)
+	creatorID := userID.(int)
+	if err := lifecycle.Record(tx, eventID, nil, lifecycle.Pending, &creatorID, ""); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create event")
+		return
+	}
+
+	if err := logActivity(tx, creatorID, "event_created", "event", eventID, nil); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create event")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to create event")
+		return
+	}
 
 	response := gin.H{
 		"event_id": eventID,
@@ -51,25 +75,117 @@ func CreateEvent(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Event created successfully", response)
 }
 
-// GetAllEvents retrieves all approved events
+// eventList is how the public event list can be sorted and filtered
+var eventList = listing.Spec{
+	Key: "e.event_id",
+	Sorts: map[string]listing.Sort{
+		"start_datetime": {Column: "e.start_datetime", Type: "timestamptz"},
+		"created_at":     {Column: "e.created_at", Type: "timestamptz"},
+		"title":          {Column: "e.title", Type: "text"},
+	},
+	DefaultSort: "-start_datetime",
+	Filters: map[string]listing.Filter{
+		"club_id":    {Column: "e.club_id", Kind: listing.IntEquals},
+		"event_type": {Column: "e.event_type", Kind: listing.Equals},
+		"q":          {Column: "e.title", Kind: listing.Contains},
+		"location":   {Column: "e.location", Kind: listing.Contains},
+		"from":       {Column: "e.start_datetime", Kind: listing.From},
+		"to":         {Column: "e.start_datetime", Kind: listing.Until},
+	},
+}
+
+// eventHasSeats holds when an event has fewer confirmed registrations than
+// its capacity
+const eventHasSeats = `e.capacity > (SELECT COUNT(*) FROM event_registrations r
+	 WHERE r.event_id = e.event_id AND r.registration_status = 'confirmed')`
+
+// GetAllEvents retrieves approved events a page at a time. Besides the
+// listing filters it accepts when=upcoming|past, has_seats=true|false and,
+// for signed in users, registered=true|false. Signed in users also get their
+// own registration status on each event.
 func GetAllEvents(c *gin.Context) {
+	page := parsePage(c, &eventList)
+	if page == nil {
+		return
+	}
+	page.Where("e.status = 'approved'")
+
+	switch c.Query("when") {
+	case "":
+	case "upcoming":
+		page.Where("e.end_datetime >= CURRENT_TIMESTAMP")
+	case "past":
+		page.Where("e.end_datetime < CURRENT_TIMESTAMP")
+	default:
+		utils.BadRequestResponse(c, "when must be upcoming or past")
+		return
+	}
+
+	if value := c.Query("has_seats"); value != "" {
+		hasSeats, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.BadRequestResponse(c, "has_seats must be true or false")
+			return
+		}
+		if hasSeats {
+			page.Where(eventHasSeats)
+		} else {
+			page.Where("NOT (" + eventHasSeats + ")")
+		}
+	}
+
+	userID, signedIn := c.Get("user_id")
+	if value := c.Query("registered"); value != "" {
+		registered, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.BadRequestResponse(c, "registered must be true or false")
+			return
+		}
+		if !signedIn {
+			utils.UnauthorizedResponse(c, "Sign in to filter by your registrations")
+			return
+		}
+		exists := `EXISTS (SELECT 1 FROM event_registrations r
+			 WHERE r.event_id = e.event_id AND r.user_id = ` + page.Arg(userID) + ` AND r.registration_status != 'cancelled')`
+		if registered {
+			page.Where(exists)
+		} else {
+			page.Where("NOT " + exists)
+		}
+	}
+
+	total, err := page.Count(database.DB, "events e")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch events")
+		return
+	}
+
+	// The caller's registration is only selected, not filtered on, so its
+	// argument is added after counting
+	myStatus := "''"
+	if signedIn {
+		myStatus = `COALESCE((SELECT r.registration_status FROM event_registrations r
+			 WHERE r.event_id = e.event_id AND r.user_id = ` + page.Arg(userID) + `), '')`
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			e.event_id, e.title, e.description, e.event_type, e.location,
 			e.start_datetime, e.end_datetime, e.registration_deadline, e.capacity, e.banner_image_url,
 			c.club_name, c.club_code,
-			COUNT(er.registration_id) FILTER (WHERE er.registration_status = 'confirmed') as registered_count
+			COUNT(er.registration_id) FILTER (WHERE er.registration_status = 'confirmed') as registered_count,
+			`+myStatus+` as my_registration_status,
+			`+page.CursorColumns()+`
 		 FROM events e
 		 JOIN clubs c ON e.club_id = c.club_id
-		 LEFT JOIN event_registrations er ON e.event_id = er.event_id
-		 WHERE e.status = 'approved'
-		 GROUP BY e.event_id, c.club_id
-		 ORDER BY e.start_datetime DESC`,
+		 LEFT JOIN event_registrations er ON e.event_id = er.event_id`+where+`
+		 GROUP BY e.event_id, c.club_id`+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		fmt.Printf("DEBUG: Query error: %v\n", err)
-		utils.InternalServerErrorResponse(c, "Failed to fetch events")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch events")
 		return
 	}
 	defer rows.Close()
@@ -79,12 +195,16 @@ func GetAllEvents(c *gin.Context) {
 	for rows.Next() {
 		var event models.Event
 		var bannerImageURL sql.NullString
+		var cursor listing.Cursor
 		err := rows.Scan(&event.EventID, &event.Title, &event.Description, &event.EventType, &event.Location,
 			&event.StartDatetime, &event.EndDatetime, &event.RegistrationDeadline, &event.Capacity, &bannerImageURL,
-			&event.ClubName, &event.ClubCode, &event.RegisteredCount)
+			&event.ClubName, &event.ClubCode, &event.RegisteredCount, &event.MyRegistrationStatus, &cursor.Value, &cursor.Key)
 		if err != nil {
-			fmt.Printf("DEBUG: Scan error: %v\n", err)
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch events")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		if bannerImageURL.Valid {
 			event.BannerImageURL = bannerImageURL.String
@@ -92,8 +212,7 @@ func GetAllEvents(c *gin.Context) {
 		events = append(events, event)
 	}
 
-	fmt.Printf("DEBUG: Events found: %d\n", len(events))
-	utils.SuccessResponse(c, http.StatusOK, "Events retrieved successfully", events)
+	utils.PageResponse(c, http.StatusOK, "Events retrieved successfully", events, page.Meta(total))
 }
 
 // GetEventDetails retrieves detailed information about an event
@@ -104,46 +223,36 @@ func GetEventDetails(c *gin.Context) {
 		return
 	}
 
-	var event models.Event
-
-	err = database.DB.QueryRow(
-		`SELECT 
-			e.event_id, e.title, e.description, e.event_type, e.location,
-			e.start_datetime, e.end_datetime, e.registration_deadline, e.capacity, e.banner_image_url, e.status,
-			c.club_name, c.club_code,
-			u.first_name || ' ' || u.last_name as created_by_name,
-			COUNT(er.registration_id) FILTER (WHERE er.registration_status = 'confirmed') as confirmed_count,
-			COUNT(er.registration_id) FILTER (WHERE er.registration_status = 'waitlist') as waitlist_count,
-			AVG(ef.rating) as average_rating,
-			COUNT(ef.feedback_id) as feedback_count,
-			e.club_id, e.created_by
-		 FROM events e
-		 JOIN clubs c ON e.club_id = c.club_id
-		 JOIN users u ON e.created_by = u.user_id
-		 LEFT JOIN event_registrations er ON e.event_id = er.event_id
-		 LEFT JOIN event_feedback ef ON e.event_id = ef.event_id
-		 WHERE e.event_id = $1
-		 GROUP BY e.event_id, c.club_id, u.user_id`,
-		eventID,
-	).Scan(&event.EventID, &event.Title, &event.Description, &event.EventType, &event.Location,
-		&event.StartDatetime, &event.EndDatetime, &event.RegistrationDeadline, &event.Capacity, &event.BannerImageURL, &event.Status,
-		&event.ClubName, &event.ClubCode, &event.CreatedByName,
-		&event.ConfirmedCount, &event.WaitlistCount, &event.AverageRating, &event.FeedbackCount,
-		&event.ClubID, &event.CreatedBy)
-
+	event, err := stores.Events.Get(eventID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == store.ErrNotFound {
 			utils.NotFoundResponse(c, "Event not found")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch event details")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch event details")
 		return
 	}
 
+	// The creator and the club's moderators see the outcome of the last review
+	canSee, err := canSeeReviews(c, event.ClubID, event.CreatedBy)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch event details")
+		return
+	}
+	if canSee {
+		event.LatestReview, err = latestReview("event", eventID)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch event details")
+			return
+		}
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "Event details retrieved", event)
 }
 
-// RegisterForEvent registers a user for an event with waitlist support
+// RegisterForEvent registers a user for an event with waitlist support. The
+// event row is locked for the duration of the transaction so concurrent
+// registrations can never confirm more students than the event's capacity.
 func RegisterForEvent(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -157,23 +266,78 @@ func RegisterForEvent(c *gin.Context) {
 		return
 	}
 
-	// Check event capacity and current registrations
-	var capacity int
-	var currentRegistrations int
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to register for event")
+		return
+	}
+	defer tx.Rollback()
 
-	err = database.DB.QueryRow(
-		`SELECT 
-			e.capacity,
-			COUNT(er.registration_id) FILTER (WHERE er.registration_status = 'confirmed')
-		 FROM events e
-		 LEFT JOIN event_registrations er ON e.event_id = er.event_id
-		 WHERE e.event_id = $1
-		 GROUP BY e.event_id`,
+	var capacity int
+	var title, status string
+	var isRegistrationOpen bool
+	var startDatetime time.Time
+	var registrationDeadline sql.NullTime
+
+	err = tx.QueryRow(
+		`SELECT title, capacity, status, is_registration_open, start_datetime, registration_deadline
+		 FROM events
+		 WHERE event_id = $1
+		 FOR UPDATE`,
 		eventID,
-	).Scan(&capacity, &currentRegistrations)
+	).Scan(&title, &capacity, &status, &isRegistrationOpen, &startDatetime, &registrationDeadline)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Event not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to check event capacity")
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case status != "approved":
+		utils.BadRequestResponse(c, "Event is not open for registration")
+		return
+	case !isRegistrationOpen:
+		utils.BadRequestResponse(c, "Registration is closed for this event")
+		return
+	case registrationDeadline.Valid && now.After(registrationDeadline.Time):
+		utils.BadRequestResponse(c, "Registration deadline has passed")
+		return
+	case now.After(startDatetime):
+		utils.BadRequestResponse(c, "Event has already started")
+		return
+	}
+
+	var existingStatus string
+	err = tx.QueryRow(
+		`SELECT registration_status FROM event_registrations WHERE event_id = $1 AND user_id = $2`,
+		eventID, userID,
+	).Scan(&existingStatus)
 
 	if err != nil && err != sql.ErrNoRows {
-		utils.InternalServerErrorResponse(c, "Failed to check event capacity")
+		utils.InternalServerErrorResponse(c, err, "Failed to register for event")
+		return
+	}
+	if err == nil && existingStatus != "cancelled" {
+		utils.ConflictResponse(c, "Already registered for this event")
+		return
+	}
+
+	// Check current registrations; the lock on the event row serializes this
+	// count with every other registration and cancellation for the event
+	var currentRegistrations int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM event_registrations
+		 WHERE event_id = $1 AND registration_status = 'confirmed'`,
+		eventID,
+	).Scan(&currentRegistrations)
+
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to check event capacity")
 		return
 	}
 
@@ -183,23 +347,53 @@ func RegisterForEvent(c *gin.Context) {
 		registrationStatus = "waitlist"
 	}
 
-	// Insert registration
+	// Insert registration; a re-registration after cancelling joins the back of
+	// the queue with a fresh ticket and no attendance carried over
 	var registrationID int
-	err = database.DB.QueryRow(
+	err = tx.QueryRow(
 		`INSERT INTO event_registrations (event_id, user_id, registration_status)
 		 VALUES ($1, $2, $3)
-		 ON CONFLICT (event_id, user_id) DO UPDATE SET registration_status = EXCLUDED.registration_status
+		 ON CONFLICT (event_id, user_id) DO UPDATE
+		 SET registration_status = EXCLUDED.registration_status, registration_date = CURRENT_TIMESTAMP,
+		     attendance_marked = FALSE, checked_in_at = NULL, checked_in_by = NULL,
+		     ticket_version = event_registrations.ticket_version + 1
 		 RETURNING registration_id`,
 		eventID, userID, registrationStatus,
 	).Scan(&registrationID)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to register for event")
+		utils.InternalServerErrorResponse(c, err, "Failed to register for event")
+		return
+	}
+
+	kind := notifier.RegistrationConfirmed
+	if registrationStatus == "waitlist" {
+		kind = notifier.RegistrationWaitlisted
+	}
+	err = notifier.ToUser(tx, userID.(int), notifier.Notification{
+		Kind:       kind,
+		EntityType: "event",
+		EntityID:   eventID,
+		Data: map[string]interface{}{
+			"EventTitle": title,
+			"StartDate":  startDatetime.Format(notifier.DateFormat),
+		},
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to register for event")
 		return
 	}
 
 	// Log activity
-	LogActivity(userID.(int), "event_registered", "event", eventID, nil)
+	if err := logActivity(tx, userID.(int), "event_registered", "event", eventID, nil); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to register for event")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to register for event")
+		return
+	}
 
 	response := gin.H{
 		"registration_id":     registrationID,
@@ -209,7 +403,8 @@ func RegisterForEvent(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Successfully registered for event", response)
 }
 
-// CancelEventRegistration cancels a user's event registration
+// CancelEventRegistration cancels a user's event registration. When a confirmed
+// seat is freed, the oldest waitlisted registration is promoted in the same transaction.
 func CancelEventRegistration(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -223,25 +418,172 @@ func CancelEventRegistration(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel registration")
+		return
+	}
+	defer tx.Rollback()
+
+	// Lock the event first, in the same order as RegisterForEvent, so seat
+	// counts stay consistent between concurrent registrations and cancellations
+	var lockedEventID int
+	err = tx.QueryRow(`SELECT event_id FROM events WHERE event_id = $1 FOR UPDATE`, eventID).Scan(&lockedEventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Event not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel registration")
+		return
+	}
+
+	var previousStatus string
+	err = tx.QueryRow(
+		`SELECT registration_status FROM event_registrations
+		 WHERE event_id = $1 AND user_id = $2
+		 FOR UPDATE`,
+		eventID, userID,
+	).Scan(&previousStatus)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Registration not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel registration")
+		return
+	}
+
+	if previousStatus == "cancelled" {
+		utils.SuccessResponse(c, http.StatusOK, "Registration cancelled successfully", nil)
+		return
+	}
+
+	// Bumping the ticket version revokes the ticket
+	_, err = tx.Exec(
 		`UPDATE event_registrations
-		 SET registration_status = 'cancelled'
+		 SET registration_status = 'cancelled', ticket_version = ticket_version + 1
 		 WHERE event_id = $1 AND user_id = $2`,
 		eventID, userID,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to cancel registration")
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel registration")
 		return
 	}
 
+	if previousStatus == "confirmed" {
+		if _, err := promoteFromWaitlist(tx, eventID); err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to promote waitlisted registration")
+			return
+		}
+	}
+
 	// Log activity
-	LogActivity(userID.(int), "event_registration_cancelled", "event", eventID, nil)
+	if err := logActivity(tx, userID.(int), "event_registration_cancelled", "event", eventID, nil); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel registration")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to cancel registration")
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Registration cancelled successfully", nil)
 }
 
-// GetUserRegisteredEvents retrieves all events a user is registered for
+// promoteFromWaitlist confirms the oldest waitlisted registration for an event
+// if a seat is available, notifying the promoted student. It reports whether a
+// registration was promoted.
+func promoteFromWaitlist(tx *database.Tx, eventID int) (bool, error) {
+	var capacity, confirmed int
+	var title string
+	err := tx.QueryRow(
+		`SELECT e.title, e.capacity,
+			(SELECT COUNT(*) FROM event_registrations er
+			 WHERE er.event_id = e.event_id AND er.registration_status = 'confirmed')
+		 FROM events e
+		 WHERE e.event_id = $1`,
+		eventID,
+	).Scan(&title, &capacity, &confirmed)
+
+	if err != nil {
+		return false, err
+	}
+
+	if confirmed >= capacity {
+		return false, nil
+	}
+
+	var registrationID, promotedUserID int
+	err = tx.QueryRow(
+		`SELECT registration_id, user_id
+		 FROM event_registrations
+		 WHERE event_id = $1 AND registration_status = 'waitlist'
+		 ORDER BY registration_date, registration_id
+		 LIMIT 1
+		 FOR UPDATE`,
+		eventID,
+	).Scan(&registrationID, &promotedUserID)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE event_registrations SET registration_status = 'confirmed' WHERE registration_id = $1`,
+		registrationID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	err = notifier.ToUser(tx, promotedUserID, notifier.Notification{
+		Kind:       notifier.WaitlistPromoted,
+		EntityType: "event",
+		EntityID:   eventID,
+		Data:       map[string]interface{}{"EventTitle": title},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	err = logActivity(tx, promotedUserID, "waitlist_promoted", "event", eventID, gin.H{
+		"registration_id": registrationID,
+	})
+	return err == nil, err
+}
+
+// fillFromWaitlist promotes waitlisted registrations until the event is full
+// or the waitlist is empty
+func fillFromWaitlist(tx *database.Tx, eventID int) error {
+	for {
+		promoted, err := promoteFromWaitlist(tx, eventID)
+		if err != nil || !promoted {
+			return err
+		}
+	}
+}
+
+// userEventList is how a user's registered events can be sorted and filtered
+var userEventList = listing.Spec{
+	Key: "er.registration_id",
+	Sorts: map[string]listing.Sort{
+		"start_datetime":    {Column: "e.start_datetime", Type: "timestamptz"},
+		"registration_date": {Column: "er.registration_date", Type: "timestamptz"},
+	},
+	DefaultSort: "-start_datetime",
+	Filters: map[string]listing.Filter{
+		"status": {Column: "er.registration_status", Kind: listing.Equals},
+	},
+}
+
+// GetUserRegisteredEvents retrieves the events a user is registered for
 func GetUserRegisteredEvents(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -249,54 +591,90 @@ func GetUserRegisteredEvents(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &userEventList)
+	if page == nil {
+		return
+	}
+	page.Where("er.user_id = " + page.Arg(userID))
+	page.Where("er.registration_status != 'cancelled'")
+
+	total, err := page.Count(database.DB, "event_registrations er")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user events")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			e.event_id, e.title, e.start_datetime, e.location, e.banner_image_url,
 			c.club_name, c.club_code,
-			er.registration_status, er.registration_date, er.attendance_marked
+			er.registration_status, er.registration_date, er.attendance_marked, er.checked_in_at, er.feedback_submitted,
+			`+page.CursorColumns()+`
 		 FROM event_registrations er
 		 JOIN events e ON er.event_id = e.event_id
-		 JOIN clubs c ON e.club_id = c.club_id
-		 WHERE er.user_id = $1 AND er.registration_status != 'cancelled'
-		 ORDER BY e.start_datetime DESC`,
-		userID,
+		 JOIN clubs c ON e.club_id = c.club_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch user events")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user events")
 		return
 	}
 	defer rows.Close()
 
 	type UserEvent struct {
-		EventID            int    `json:"event_id"`
-		Title              string `json:"title"`
-		StartDatetime      string `json:"start_datetime"`
-		Location           string `json:"location"`
-		BannerImageURL     string `json:"banner_image_url"`
-		ClubName           string `json:"club_name"`
-		ClubCode           string `json:"club_code"`
-		RegistrationStatus string `json:"registration_status"`
-		RegistrationDate   string `json:"registration_date"`
-		AttendanceMarked   bool   `json:"attendance_marked"`
+		EventID            int        `json:"event_id"`
+		Title              string     `json:"title"`
+		StartDatetime      string     `json:"start_datetime"`
+		Location           string     `json:"location"`
+		BannerImageURL     string     `json:"banner_image_url"`
+		ClubName           string     `json:"club_name"`
+		ClubCode           string     `json:"club_code"`
+		RegistrationStatus string     `json:"registration_status"`
+		RegistrationDate   string     `json:"registration_date"`
+		AttendanceMarked   bool       `json:"attendance_marked"`
+		CheckedInAt        *time.Time `json:"checked_in_at"`
+		FeedbackSubmitted  bool       `json:"feedback_submitted"`
 	}
 
 	events := make([]UserEvent, 0)
 
 	for rows.Next() {
 		var event UserEvent
+		var cursor listing.Cursor
 		err := rows.Scan(&event.EventID, &event.Title, &event.StartDatetime, &event.Location, &event.BannerImageURL,
-			&event.ClubName, &event.ClubCode, &event.RegistrationStatus, &event.RegistrationDate, &event.AttendanceMarked)
+			&event.ClubName, &event.ClubCode, &event.RegistrationStatus, &event.RegistrationDate, &event.AttendanceMarked,
+			&event.CheckedInAt, &event.FeedbackSubmitted, &cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch user events")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		events = append(events, event)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "User events retrieved", events)
+	utils.PageResponse(c, http.StatusOK, "User events retrieved", events, page.Meta(total))
+}
+
+// registrationList is how an event's registrations can be sorted and filtered
+var registrationList = listing.Spec{
+	Key: "er.registration_id",
+	Sorts: map[string]listing.Sort{
+		"registration_date": {Column: "er.registration_date", Type: "timestamptz"},
+		"last_name":         {Column: "u.last_name", Type: "text"},
+	},
+	DefaultSort: "registration_date",
+	Filters: map[string]listing.Filter{
+		"status":            {Column: "er.registration_status", Kind: listing.Equals},
+		"attendance_marked": {Column: "er.attendance_marked", Kind: listing.BoolEquals},
+	},
+	DefaultLimit: 50,
 }
 
-// GetEventRegistrations retrieves all registrations for an event
+// GetEventRegistrations retrieves the registrations for an event
 func GetEventRegistrations(c *gin.Context) {
 	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -304,47 +682,66 @@ func GetEventRegistrations(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &registrationList)
+	if page == nil {
+		return
+	}
+	page.Where("er.event_id = " + page.Arg(eventID))
+
+	total, err := page.Count(database.DB, "event_registrations er")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch registrations")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			u.user_id, u.student_id, u.first_name, u.last_name, u.email,
-			er.registration_status, er.registration_date, er.attendance_marked
+			er.registration_status, er.registration_date, er.attendance_marked, er.checked_in_at, er.feedback_submitted,
+			`+page.CursorColumns()+`
 		 FROM event_registrations er
-		 JOIN users u ON er.user_id = u.user_id
-		 WHERE er.event_id = $1
-		 ORDER BY er.registration_date`,
-		eventID,
+		 JOIN users u ON er.user_id = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch registrations")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch registrations")
 		return
 	}
 	defer rows.Close()
 
 	type Registration struct {
-		UserID             int    `json:"user_id"`
-		StudentID       string `json:"student_id"`
-		FirstName          string `json:"first_name"`
-		LastName           string `json:"last_name"`
-		Email              string `json:"email"`
-		RegistrationStatus string `json:"registration_status"`
-		RegistrationDate   string `json:"registration_date"`
-		AttendanceMarked   bool   `json:"attendance_marked"`
+		UserID             int        `json:"user_id"`
+		StudentID          string     `json:"student_id"`
+		FirstName          string     `json:"first_name"`
+		LastName           string     `json:"last_name"`
+		Email              string     `json:"email"`
+		RegistrationStatus string     `json:"registration_status"`
+		RegistrationDate   string     `json:"registration_date"`
+		AttendanceMarked   bool       `json:"attendance_marked"`
+		CheckedInAt        *time.Time `json:"checked_in_at"`
+		FeedbackSubmitted  bool       `json:"feedback_submitted"`
 	}
 
 	registrations := make([]Registration, 0)
 
 	for rows.Next() {
 		var reg Registration
+		var cursor listing.Cursor
 		err := rows.Scan(&reg.UserID, &reg.StudentID, &reg.FirstName, &reg.LastName, &reg.Email,
-			&reg.RegistrationStatus, &reg.RegistrationDate, &reg.AttendanceMarked)
+			&reg.RegistrationStatus, &reg.RegistrationDate, &reg.AttendanceMarked, &reg.CheckedInAt, &reg.FeedbackSubmitted, &cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch registrations")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		registrations = append(registrations, reg)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Event registrations retrieved", registrations)
+	utils.PageResponse(c, http.StatusOK, "Event registrations retrieved", registrations, page.Meta(total))
 }
 
 // MarkAttendance marks a user's attendance for an event
@@ -360,19 +757,53 @@ func MarkAttendance(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	_, err = database.DB.Exec(
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to mark attendance")
+		return
+	}
+	defer tx.Rollback()
+
+	// The event's row is locked so its status can't change underneath
+	var eventStatus string
+	err = tx.QueryRow(`SELECT status FROM events WHERE event_id = $1 FOR SHARE`, eventID).Scan(&eventStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Event not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to mark attendance")
+		return
+	}
+	if !lifecycle.TakesAttendance(eventStatus) {
+		utils.ErrorResponse(c, http.StatusConflict, "This event is "+eventStatus+", not open for attendance", "event_not_approved")
+		return
+	}
+
+	// Waitlisted and cancelled registrations can't be marked
+	result, err := tx.Exec(
 		`UPDATE event_registrations
 		 SET attendance_marked = TRUE
-		 WHERE event_id = $1 AND user_id = $2`,
+		 WHERE event_id = $1 AND user_id = $2 AND registration_status IN ('confirmed', 'attended')`,
 		eventID, req.UserID,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to mark attendance")
+		utils.InternalServerErrorResponse(c, err, "Failed to mark attendance")
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.NotFoundResponse(c, "Registration not found")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to mark attendance")
 		return
 	}
 
@@ -396,38 +827,43 @@ func SubmitEventFeedback(c *gin.Context) {
 	var req models.SubmitFeedbackRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`INSERT INTO event_feedback (event_id, user_id, rating, comment)
-		 VALUES ($1, $2, $3, $4)
-		 ON CONFLICT (event_id, user_id) DO UPDATE 
-		 SET rating = EXCLUDED.rating, comment = EXCLUDED.comment, submitted_at = CURRENT_TIMESTAMP`,
-		eventID, userID, req.Rating, req.Comment,
-	)
+	// The feedback and the registration's flag are saved together
+	err = stores.WithTx(func(tx *store.Stores) error {
+		if err := tx.Events.SaveFeedback(eventID, userID.(int), req); err != nil {
+			return err
+		}
+		if err := tx.Events.MarkFeedbackSubmitted(eventID, userID.(int)); err != nil {
+			return err
+		}
+		return tx.Users.LogActivity(userID.(int), "feedback_submitted", "event", eventID, nil)
+	})
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to submit feedback")
+		utils.AppErrorResponse(c, err, "Failed to submit feedback")
 		return
 	}
 
-	// Mark feedback as submitted in registration
-	database.DB.Exec(
-		`UPDATE event_registrations
-		 SET feedback_submitted = TRUE
-		 WHERE event_id = $1 AND user_id = $2`,
-		eventID, userID,
-	)
-
-	// Log activity
-	LogActivity(userID.(int), "feedback_submitted", "event", eventID, nil)
-
 	utils.SuccessResponse(c, http.StatusCreated, "Feedback submitted successfully", nil)
 }
 
-// GetEventFeedback retrieves all feedback for an event
+// feedbackList is how an event's feedback can be sorted and filtered
+var feedbackList = listing.Spec{
+	Key: "ef.feedback_id",
+	Sorts: map[string]listing.Sort{
+		"submitted_at": {Column: "ef.submitted_at", Type: "timestamptz"},
+		"rating":       {Column: "ef.rating", Type: "integer"},
+	},
+	DefaultSort: "-submitted_at",
+	Filters: map[string]listing.Filter{
+		"rating": {Column: "ef.rating", Kind: listing.IntEquals},
+	},
+}
+
+// GetEventFeedback retrieves the feedback for an event
 func GetEventFeedback(c *gin.Context) {
 	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -435,43 +871,78 @@ func GetEventFeedback(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &feedbackList)
+	if page == nil {
+		return
+	}
+	page.Where("ef.event_id = " + page.Arg(eventID))
+
+	total, err := page.Count(database.DB, "event_feedback ef")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch feedback")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			ef.feedback_id, ef.rating, ef.comment, ef.submitted_at,
-			u.first_name, u.last_name, u.profile_picture_url
+			u.first_name, u.last_name, u.profile_picture_url,
+			`+page.CursorColumns()+`
 		 FROM event_feedback ef
-		 JOIN users u ON ef.user_id = u.user_id
-		 WHERE ef.event_id = $1
-		 ORDER BY ef.submitted_at DESC`,
-		eventID,
+		 JOIN users u ON ef.user_id = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch feedback")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch feedback")
 		return
 	}
 	defer rows.Close()
 
-	var feedbacks []models.EventFeedback
+	feedbacks := make([]models.EventFeedback, 0)
 
 	for rows.Next() {
 		var feedback models.EventFeedback
+		var cursor listing.Cursor
 		err := rows.Scan(&feedback.FeedbackID, &feedback.Rating, &feedback.Comment, &feedback.SubmittedAt,
-			&feedback.FirstName, &feedback.LastName, &feedback.ProfilePictureURL)
+			&feedback.FirstName, &feedback.LastName, &feedback.ProfilePictureURL, &cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch feedback")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		feedbacks = append(feedbacks, feedback)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Event feedback retrieved", feedbacks)
+	utils.PageResponse(c, http.StatusOK, "Event feedback retrieved", feedbacks, page.Meta(total))
 }
 
-// ApproveEvent approves a pending event
+// ApproveEvent approves a pending event, notifying its creator and the
+// members of the hosting club. The reviewer may add a note and comments.
 func ApproveEvent(c *gin.Context) {
+	setEventReviewStatus(c, "approved")
+}
+
+// RejectEvent rejects a pending event with a reason and optional comments on
+// its fields, notifying its creator
+func RejectEvent(c *gin.Context) {
+	setEventReviewStatus(c, "rejected")
+}
+
+// setEventReviewStatus records an admin's approval or rejection of an event
+// and adds it to the event's review history
+func setEventReviewStatus(c *gin.Context, status string) {
+	action := "approve"
+	if status == "rejected" {
+		action = "reject"
+	}
+
 	role, _ := c.Get("role")
 	if role != "system_admin" {
-		utils.ForbiddenResponse(c, "Only system admins can approve events")
+		utils.ForbiddenResponse(c, "Only system admins can "+action+" events")
 		return
 	}
 
@@ -481,51 +952,100 @@ func ApproveEvent(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`UPDATE events
-		 SET status = 'approved', updated_at = CURRENT_TIMESTAMP
-		 WHERE event_id = $1`,
-		eventID,
-	)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+	adminID := userID.(int)
 
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to approve event")
+	review, ok := bindReview(c, "event", status == "rejected")
+	if !ok {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Event approved successfully", nil)
-}
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" event")
+		return
+	}
+	defer tx.Rollback()
 
-// RejectEvent rejects a pending event
-func RejectEvent(c *gin.Context) {
-	role, _ := c.Get("role")
-	if role != "system_admin" {
-		utils.ForbiddenResponse(c, "Only system admins can reject events")
+	if _, err := lifecycle.Transition(tx, eventID, status, &adminID, review.Reason); err != nil {
+		eventTransitionErrorResponse(c, err, "Failed to "+action+" event")
 		return
 	}
 
-	eventID, err := strconv.Atoi(c.Param("id"))
+	err = recordReview(tx, "event", eventID, status, adminID, review.Reason, review.Comments)
 	if err != nil {
-		utils.BadRequestResponse(c, "Invalid event ID")
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" event")
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`UPDATE events
-		 SET status = 'rejected', updated_at = CURRENT_TIMESTAMP
-		 WHERE event_id = $1`,
+	var clubID, createdBy int
+	var title, clubName string
+	var startDatetime time.Time
+	err = tx.QueryRow(
+		`SELECT e.club_id, e.created_by, e.title, e.start_datetime, cl.club_name
+		 FROM events e
+		 JOIN clubs cl ON cl.club_id = e.club_id
+		 WHERE e.event_id = $1`,
 		eventID,
-	)
+	).Scan(&clubID, &createdBy, &title, &startDatetime, &clubName)
+
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" event")
+		return
+	}
+
+	data := map[string]interface{}{
+		"EventTitle": title,
+		"ClubName":   clubName,
+		"StartDate":  startDatetime.Format(notifier.DateFormat),
+		"Reason":     review.Reason,
+	}
 
+	if status == "approved" {
+		err = notifier.ToUser(tx, createdBy, notifier.Notification{
+			Kind: notifier.EventApproved, EntityType: "event", EntityID: eventID, Data: data,
+		})
+		if err == nil {
+			err = notifier.ToClubMembers(tx, clubID, notifier.Notification{
+				Kind: notifier.EventPublished, EntityType: "event", EntityID: eventID, Data: data,
+			}, createdBy)
+		}
+	} else {
+		err = notifier.ToUser(tx, createdBy, notifier.Notification{
+			Kind: notifier.EventRejected, EntityType: "event", EntityID: eventID, Data: data,
+		})
+	}
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to reject event")
+		utils.InternalServerErrorResponse(c, err, "Failed to send notifications")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Event rejected successfully", nil)
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" event")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event "+status+" successfully", nil)
+}
+
+// pendingEventList is how the events awaiting review can be sorted and filtered
+var pendingEventList = listing.Spec{
+	Key: "e.event_id",
+	Sorts: map[string]listing.Sort{
+		"created_at":     {Column: "e.created_at", Type: "timestamptz"},
+		"start_datetime": {Column: "e.start_datetime", Type: "timestamptz"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"club_id": {Column: "e.club_id", Kind: listing.IntEquals},
+	},
 }
 
-// GetPendingEvents retrieves all pending events for admin approval
+// GetPendingEvents retrieves the pending events awaiting admin approval
 func GetPendingEvents(c *gin.Context) {
 	role, _ := c.Get("role")
 	if role != "system_admin" {
@@ -533,19 +1053,32 @@ func GetPendingEvents(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &pendingEventList)
+	if page == nil {
+		return
+	}
+	page.Where("e.status = 'pending'")
+
+	total, err := page.Count(database.DB, "events e")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch pending events")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			e.event_id, e.title, e.start_datetime, e.created_at,
-			c.club_name, u.first_name || ' ' || u.last_name as created_by
+			c.club_name, u.first_name || ' ' || u.last_name as created_by,
+			`+page.CursorColumns()+`
 		 FROM events e
 		 JOIN clubs c ON e.club_id = c.club_id
-		 JOIN users u ON e.created_by = u.user_id
-		 WHERE e.status = 'pending'
-		 ORDER BY e.created_at DESC`,
+		 JOIN users u ON e.created_by = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch pending events")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch pending events")
 		return
 	}
 	defer rows.Close()
@@ -559,16 +1092,22 @@ func GetPendingEvents(c *gin.Context) {
 		CreatedBy     string `json:"created_by"`
 	}
 
-	var events []PendingEvent
+	events := make([]PendingEvent, 0)
 
 	for rows.Next() {
 		var event PendingEvent
-		err := rows.Scan(&event.EventID, &event.Title, &event.StartDatetime, &event.CreatedAt, &event.ClubName, &event.CreatedBy)
+		var cursor listing.Cursor
+		err := rows.Scan(&event.EventID, &event.Title, &event.StartDatetime, &event.CreatedAt, &event.ClubName, &event.CreatedBy,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch pending events")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		events = append(events, event)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Pending events retrieved", events)
+	utils.PageResponse(c, http.StatusOK, "Pending events retrieved", events, page.Meta(total))
 }
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/store/storetest"
+)
+
+func TestSubmitEventFeedback(t *testing.T) {
+	fakes := useFakes(t)
+	registration := storetest.Registration{EventID: 5, UserID: student.userID}
+
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+		code   string
+		saved  bool
+	}{
+		{name: "saved", body: `{"rating": 4, "comment": "Great talk"}`, status: http.StatusCreated, saved: true},
+		{name: "rating out of range", body: `{"rating": 6}`, status: http.StatusUnprocessableEntity, code: "validation_failed"},
+		{name: "store failure", body: `{"rating": 4}`, err: errors.New("connection reset"), status: http.StatusInternalServerError, code: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		delete(fakes.Events.Feedback, registration)
+		delete(fakes.Events.FeedbackSubmitted, registration)
+		fakes.Events.Err = tt.err
+
+		status, envelope := serve(t, student, "/events/:id/feedback", SubmitEventFeedback, "POST", "/events/5/feedback", tt.body)
+		if status != tt.status || envelope.Error != tt.code {
+			t.Errorf("%s: got %d %q, want %d %q", tt.name, status, envelope.Error, tt.status, tt.code)
+		}
+		_, saved := fakes.Events.Feedback[registration]
+		if saved != tt.saved || fakes.Events.FeedbackSubmitted[registration] != tt.saved {
+			t.Errorf("%s: feedback saved %v, flagged %v, want %v", tt.name, saved, fakes.Events.FeedbackSubmitted[registration], tt.saved)
+		}
+	}
+}
@@ -7,7 +7,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
+	"github.com/nub-clubs-connect/nub_admin_api/middleware"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/storage"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
@@ -25,38 +28,67 @@ func UploadEventGallery(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		ImageURL string `json:"image_url" binding:"required"`
-		Caption  string `json:"caption"`
-	}
+	// Photos are uploaded as multipart forms; a JSON body can still link an
+	// image hosted elsewhere
+	var imageURL, caption string
+	var upload *mediaUpload
+	if isMultipart(c) {
+		upload = receiveUpload(c, storage.ImageTypes)
+		if upload == nil {
+			return
+		}
+		imageURL = storage.URL(upload.Key)
+		caption = c.PostForm("caption")
+	} else {
+		var req struct {
+			ImageURL string `json:"image_url" binding:"required"`
+			Caption  string `json:"caption"`
+		}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
-		return
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindErrorResponse(c, err)
+			return
+		}
+		imageURL, caption = req.ImageURL, req.Caption
+		upload = &mediaUpload{Upload: &storage.Upload{}}
 	}
 
 	var galleryID int
 	err = database.DB.QueryRow(
-		`INSERT INTO event_gallery (event_id, uploaded_by, image_url, caption)
-		 VALUES ($1, $2, $3, $4)
+		`INSERT INTO event_gallery (event_id, uploaded_by, image_url, caption, storage_key, content_type, file_size, width, height, renditions)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		 RETURNING gallery_id`,
-		eventID, userID, req.ImageURL, req.Caption,
+		eventID, userID, imageURL, caption, upload.Key, upload.ContentType, upload.Size, upload.Width, upload.Height, upload.Renditions,
 	).Scan(&galleryID)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to upload gallery image")
+		upload.release()
+		utils.AppErrorResponse(c, err, "Failed to upload gallery image")
 		return
 	}
 
 	response := gin.H{
 		"gallery_id": galleryID,
-		"image_url":  req.ImageURL,
+		"image_url":  imageURL,
+	}
+	if images := models.NewImageSet(imageURL, upload.Width, upload.Height, upload.Renditions); images != nil {
+		response["images"] = images
 	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Gallery image uploaded successfully", response)
 }
 
-// GetEventGallery retrieves all photos from an event
+// galleryList is how an event's gallery can be sorted
+var galleryList = listing.Spec{
+	Key: "eg.gallery_id",
+	Sorts: map[string]listing.Sort{
+		"uploaded_at": {Column: "eg.uploaded_at", Type: "timestamptz"},
+	},
+	DefaultSort:  "-uploaded_at",
+	DefaultLimit: 50,
+}
+
+// GetEventGallery retrieves the photos from an event
 func GetEventGallery(c *gin.Context) {
 	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -64,39 +96,58 @@ func GetEventGallery(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &galleryList)
+	if page == nil {
+		return
+	}
+	page.Where("eg.event_id = " + page.Arg(eventID))
+
+	total, err := page.Count(database.DB, "event_gallery eg")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch gallery")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
-			eg.gallery_id, eg.image_url, eg.caption, eg.uploaded_at, eg.uploaded_by,
-			u.first_name || ' ' || u.last_name as uploaded_by_name
+			eg.gallery_id, eg.image_url, eg.caption, eg.content_type, eg.file_size, eg.width, eg.height, eg.renditions, eg.uploaded_at, eg.uploaded_by,
+			u.first_name || ' ' || u.last_name as uploaded_by_name,
+			`+page.CursorColumns()+`
 		 FROM event_gallery eg
-		 LEFT JOIN users u ON eg.uploaded_by = u.user_id
-		 WHERE eg.event_id = $1
-		 ORDER BY eg.uploaded_at DESC`,
-		eventID,
+		 LEFT JOIN users u ON eg.uploaded_by = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch gallery")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch gallery")
 		return
 	}
 	defer rows.Close()
 
-	var gallery []models.EventGallery
+	gallery := make([]models.EventGallery, 0)
 
 	for rows.Next() {
 		var item models.EventGallery
 		var uploadedByName sql.NullString
-		err := rows.Scan(&item.GalleryID, &item.ImageURL, &item.Caption, &item.UploadedAt, &item.UploadedBy, &uploadedByName)
+		var cursor listing.Cursor
+		err := rows.Scan(&item.GalleryID, &item.ImageURL, &item.Caption, &item.ContentType, &item.FileSize, &item.Width, &item.Height, &item.Renditions, &item.UploadedAt, &item.UploadedBy, &uploadedByName,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch gallery")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
+		item.Images = models.NewImageSet(item.ImageURL, item.Width, item.Height, item.Renditions)
 		if uploadedByName.Valid {
 			item.UploadedByName = uploadedByName.String
 		}
 		gallery = append(gallery, item)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Event gallery retrieved", gallery)
+	utils.PageResponse(c, http.StatusOK, "Event gallery retrieved", gallery, page.Meta(total))
 }
 
 // DeleteGalleryImage deletes a photo from event gallery
@@ -107,32 +158,50 @@ func DeleteGalleryImage(c *gin.Context) {
 		return
 	}
 
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
 	galleryID, err := strconv.Atoi(c.Param("galleryId"))
 	if err != nil {
 		utils.BadRequestResponse(c, "Invalid gallery ID")
 		return
 	}
 
-	// Check if user is the uploader or admin
+	// Check if user is the uploader, a moderator of the event's club, or admin
 	var uploadedBy int
+	var clubID int
+	var storageKey string
+	var renditions models.ImageRenditions
 	err = database.DB.QueryRow(
-		`SELECT uploaded_by FROM event_gallery WHERE gallery_id = $1`,
-		galleryID,
-	).Scan(&uploadedBy)
+		`SELECT eg.uploaded_by, e.club_id, eg.storage_key, eg.renditions
+		 FROM event_gallery eg
+		 JOIN events e ON eg.event_id = e.event_id
+		 WHERE eg.gallery_id = $1 AND eg.event_id = $2`,
+		galleryID, eventID,
+	).Scan(&uploadedBy, &clubID, &storageKey, &renditions)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.NotFoundResponse(c, "Gallery image not found")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to verify ownership")
+		utils.InternalServerErrorResponse(c, err, "Failed to verify ownership")
 		return
 	}
 
-	role, _ := c.Get("role")
-	if uploadedBy != userID.(int) && role != "system_admin" {
-		utils.ForbiddenResponse(c, "You can only delete your own uploads")
-		return
+	if uploadedBy != userID.(int) {
+		canModerate, err := middleware.CanModerateClub(c, clubID)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to verify ownership")
+			return
+		}
+		if !canModerate {
+			utils.ForbiddenResponse(c, "You can only delete your own uploads")
+			return
+		}
 	}
 
 	_, err = database.DB.Exec(
@@ -141,9 +210,11 @@ func DeleteGalleryImage(c *gin.Context) {
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete gallery image")
+		utils.InternalServerErrorResponse(c, err, "Failed to delete gallery image")
 		return
 	}
 
+	releaseStoredFiles(storageKey, renditions)
+
 	utils.SuccessResponse(c, http.StatusOK, "Gallery image deleted successfully", nil)
 }
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/scheduler"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// manualJobTimeout bounds a job run triggered from the admin API
+const manualJobTimeout = 5 * time.Minute
+
+// AdminListJobs lists the background jobs with their latest runs
+func AdminListJobs(c *gin.Context) {
+	jobs := []models.ScheduledJob{}
+	for _, job := range scheduler.Default.Jobs() {
+		lastRun, err := scheduler.LastRun(job.Name)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch jobs")
+			return
+		}
+		jobs = append(jobs, models.ScheduledJob{
+			Name:        job.Name,
+			Description: job.Description,
+			Interval:    job.Interval.String(),
+			LastRun:     lastRun,
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Jobs retrieved", jobs)
+}
+
+// jobRunList is how the job run history can be sorted and filtered
+var jobRunList = listing.Spec{
+	Key: "run_id",
+	Sorts: map[string]listing.Sort{
+		"started_at": {Column: "started_at", Type: "timestamptz"},
+	},
+	DefaultSort: "-started_at",
+	Filters: map[string]listing.Filter{
+		"job":    {Column: "job_name", Kind: listing.Equals},
+		"status": {Column: "status", Kind: listing.Equals},
+	},
+	DefaultLimit: 50,
+}
+
+// AdminListJobRuns returns the run history, optionally filtered by job
+func AdminListJobRuns(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName != "" {
+		if _, ok := scheduler.Default.Job(jobName); !ok {
+			utils.NotFoundResponse(c, "Job not found")
+			return
+		}
+	}
+
+	page := parsePage(c, &jobRunList)
+	if page == nil {
+		return
+	}
+
+	runs, total, err := scheduler.RecentRuns(page)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch job runs")
+		return
+	}
+
+	utils.PageResponse(c, http.StatusOK, "Job runs retrieved", runs, page.Meta(total))
+}
+
+// AdminTriggerJob runs a job immediately and returns the recorded run
+func AdminTriggerJob(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	// The run is recorded even if the admin disconnects, so don't tie it to the request
+	ctx, cancel := context.WithTimeout(context.Background(), manualJobTimeout)
+	defer cancel()
+
+	run, err := scheduler.Default.Trigger(ctx, c.Param("name"), userID.(int))
+	switch {
+	case err == scheduler.ErrUnknownJob:
+		utils.NotFoundResponse(c, "Job not found")
+		return
+	case err == scheduler.ErrJobRunning:
+		utils.ConflictResponse(c, "Job is already running")
+		return
+	case run == nil:
+		utils.InternalServerErrorResponse(c, err, "Failed to run job")
+		return
+	}
+
+	LogActivity(userID.(int), "job_triggered", "job_run", int(run.RunID), gin.H{"job": run.JobName})
+
+	// A failed run is still reported as a run; its status and error say what went wrong
+	message := "Job completed"
+	if run.Status == "failed" {
+		message = "Job finished with errors"
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, run)
+}
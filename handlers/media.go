@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"database/sql"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/storage"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
@@ -24,40 +28,96 @@ func UploadNewsMedia(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		MediaType    string `json:"media_type" binding:"required"`
-		MediaURL     string `json:"media_url" binding:"required"`
-		Caption      string `json:"caption"`
-		DisplayOrder int    `json:"display_order"`
-	}
+	// Files are uploaded as multipart forms; a JSON body can still link media
+	// hosted elsewhere, such as a video on a streaming site
+	var mediaType, mediaURL, caption string
+	var displayOrder int
+	upload := &mediaUpload{Upload: &storage.Upload{}}
+	if isMultipart(c) {
+		allowed := make(map[string]string, len(storage.ImageTypes)+len(storage.VideoTypes))
+		for contentType, ext := range storage.ImageTypes {
+			allowed[contentType] = ext
+		}
+		for contentType, ext := range storage.VideoTypes {
+			allowed[contentType] = ext
+		}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
-		return
+		// The form is parsed, under the size limit, while receiving the file
+		upload = receiveUpload(c, allowed)
+		if upload == nil {
+			return
+		}
+
+		if order := c.PostForm("display_order"); order != "" {
+			displayOrder, err = strconv.Atoi(order)
+			if err != nil {
+				upload.release()
+				utils.BadRequestResponse(c, "Invalid display order")
+				return
+			}
+		}
+		mediaType = "image"
+		if strings.HasPrefix(upload.ContentType, "video/") {
+			mediaType = "video"
+		}
+		mediaURL = storage.URL(upload.Key)
+		caption = c.PostForm("caption")
+	} else {
+		var req struct {
+			MediaType    string `json:"media_type" binding:"required,oneof=image video"`
+			MediaURL     string `json:"media_url" binding:"required"`
+			Caption      string `json:"caption"`
+			DisplayOrder int    `json:"display_order"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindErrorResponse(c, err)
+			return
+		}
+		mediaType, mediaURL, caption, displayOrder = req.MediaType, req.MediaURL, req.Caption, req.DisplayOrder
 	}
 
 	var mediaID int
 	err = database.DB.QueryRow(
-		`INSERT INTO news_media (news_id, media_type, media_url, caption, display_order, uploaded_by)
-		 VALUES ($1, $2, $3, $4, $5, $6)
+		`INSERT INTO news_media (news_id, media_type, media_url, caption, display_order, uploaded_by, storage_key, content_type, file_size, width, height, renditions)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		 RETURNING media_id`,
-		newsID, req.MediaType, req.MediaURL, req.Caption, req.DisplayOrder, userID,
+		newsID, mediaType, mediaURL, caption, displayOrder, userID, upload.Key, upload.ContentType, upload.Size, upload.Width, upload.Height, upload.Renditions,
 	).Scan(&mediaID)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to upload media")
+		upload.release()
+		utils.AppErrorResponse(c, err, "Failed to upload media")
 		return
 	}
 
 	response := gin.H{
 		"media_id": mediaID,
-		"media_url": req.MediaURL,
+		"media_type": mediaType,
+		"media_url": mediaURL,
+	}
+	if images := models.NewImageSet(mediaURL, upload.Width, upload.Height, upload.Renditions); images != nil {
+		response["images"] = images
 	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Media uploaded successfully", response)
 }
 
-// GetNewsMedia retrieves all media for a news post
+// newsMediaList is how a news post's media can be sorted and filtered
+var newsMediaList = listing.Spec{
+	Key: "media_id",
+	Sorts: map[string]listing.Sort{
+		"display_order": {Column: "display_order", Type: "integer"},
+		"uploaded_at":   {Column: "uploaded_at", Type: "timestamptz"},
+	},
+	DefaultSort: "display_order",
+	Filters: map[string]listing.Filter{
+		"media_type": {Column: "media_type", Kind: listing.Equals},
+	},
+	DefaultLimit: 50,
+}
+
+// GetNewsMedia retrieves the media for a news post
 func GetNewsMedia(c *gin.Context) {
 	newsID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -65,32 +125,51 @@ func GetNewsMedia(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &newsMediaList)
+	if page == nil {
+		return
+	}
+	page.Where("news_id = " + page.Arg(newsID))
+
+	total, err := page.Count(database.DB, "news_media")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch media")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
-		`SELECT media_id, media_type, media_url, caption, display_order, uploaded_at
-		 FROM news_media
-		 WHERE news_id = $1
-		 ORDER BY display_order ASC`,
-		newsID,
+		`SELECT media_id, media_type, media_url, caption, display_order, content_type, file_size, width, height, renditions, uploaded_at,
+			`+page.CursorColumns()+`
+		 FROM news_media`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch media")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch media")
 		return
 	}
 	defer rows.Close()
 
-	var media []models.NewsMedia
+	media := make([]models.NewsMedia, 0)
 
 	for rows.Next() {
 		var item models.NewsMedia
-		err := rows.Scan(&item.MediaID, &item.MediaType, &item.MediaURL, &item.Caption, &item.DisplayOrder, &item.UploadedAt)
+		var cursor listing.Cursor
+		err := rows.Scan(&item.MediaID, &item.MediaType, &item.MediaURL, &item.Caption, &item.DisplayOrder, &item.ContentType, &item.FileSize, &item.Width, &item.Height, &item.Renditions, &item.UploadedAt,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch media")
+			return
 		}
+		if !page.Add(cursor) {
+			break
+		}
+		item.Images = models.NewImageSet(item.MediaURL, item.Width, item.Height, item.Renditions)
 		media = append(media, item)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "News media retrieved", media)
+	utils.PageResponse(c, http.StatusOK, "News media retrieved", media, page.Meta(total))
 }
 
 // DeleteNewsMedia deletes media from a news post
@@ -101,27 +180,36 @@ func DeleteNewsMedia(c *gin.Context) {
 		return
 	}
 
-	mediaID, err := strconv.Atoi(c.Param("mediaId"))
+	newsID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.BadRequestResponse(c, "Invalid media ID")
+		utils.BadRequestResponse(c, "Invalid news ID")
 		return
 	}
 
-	role, _ := c.Get("role")
-	if role != "system_admin" {
-		utils.ForbiddenResponse(c, "Only system admins can delete media")
+	mediaID, err := strconv.Atoi(c.Param("mediaId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid media ID")
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`DELETE FROM news_media WHERE media_id = $1`,
-		mediaID,
-	)
+	var storageKey string
+	var renditions models.ImageRenditions
+	err = database.DB.QueryRow(
+		`DELETE FROM news_media WHERE media_id = $1 AND news_id = $2
+		 RETURNING storage_key, renditions`,
+		mediaID, newsID,
+	).Scan(&storageKey, &renditions)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete media")
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Media not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to delete media")
 		return
 	}
 
+	releaseStoredFiles(storageKey, renditions)
+
 	utils.SuccessResponse(c, http.StatusOK, "Media deleted successfully", nil)
 }
@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"database/sql"
-	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/notifier"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
@@ -23,20 +26,14 @@ func CreateNews(c *gin.Context) {
 	var req models.CreateNewsRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	var newsID int
-	err := database.DB.QueryRow(
-		`INSERT INTO news (club_id, created_by, title, content, category, is_featured)
-		 VALUES ($1, $2, $3, $4, $5, $6)
-		 RETURNING news_id`,
-		req.ClubID, userID, req.Title, req.Content, req.Category, req.IsFeatured,
-	).Scan(&newsID)
+	newsID, err := stores.News.Create(userID.(int), req)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create news post")
+		utils.AppErrorResponse(c, err, "Failed to create news post")
 		return
 	}
 
@@ -51,24 +48,177 @@ func CreateNews(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "News post created successfully", response)
 }
 
-// GetAllNews retrieves all news posts
+// UpdateNews edits a news post. Any change to a rejected post resubmits it
+// for review, as do changes to the title, content or category of a published
+// one.
+func UpdateNews(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	newsID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid news ID")
+		return
+	}
+
+	var req models.UpdateNewsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update news post")
+		return
+	}
+	defer tx.Rollback()
+
+	var news models.News
+	err = tx.QueryRow(
+		`SELECT status, title, content, category, is_featured FROM news WHERE news_id = $1 FOR UPDATE`,
+		newsID,
+	).Scan(&news.Status, &news.Title, &news.Content, &news.Category, &news.IsFeatured)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "News post not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to update news post")
+		return
+	}
+
+	var changed []string
+	significant := false
+	setString := func(field string, value *string, target *string) {
+		if value != nil && *value != *target {
+			*target = *value
+			changed = append(changed, field)
+			significant = true
+		}
+	}
+	setString("title", req.Title, &news.Title)
+	setString("content", req.Content, &news.Content)
+	setString("category", req.Category, &news.Category)
+	if req.IsFeatured != nil && *req.IsFeatured != news.IsFeatured {
+		news.IsFeatured = *req.IsFeatured
+		changed = append(changed, "is_featured")
+	}
+
+	switch {
+	case strings.TrimSpace(news.Title) == "":
+		utils.BadRequestResponse(c, "title can't be empty")
+		return
+	case strings.TrimSpace(news.Content) == "":
+		utils.BadRequestResponse(c, "content can't be empty")
+		return
+	}
+
+	if len(changed) == 0 {
+		utils.SuccessResponse(c, http.StatusOK, "No changes to save", gin.H{
+			"news_id": newsID,
+			"status":  news.Status,
+			"changed": []string{},
+		})
+		return
+	}
+
+	previous := news.Status
+	if news.Status == "rejected" || (news.Status == "published" && significant) {
+		news.Status = "pending"
+	}
+
+	_, err = tx.Exec(
+		`UPDATE news
+		 SET title = $1, content = $2, category = $3, is_featured = $4, status = $5, updated_at = CURRENT_TIMESTAMP
+		 WHERE news_id = $6`,
+		news.Title, news.Content, news.Category, news.IsFeatured, news.Status, newsID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update news post")
+		return
+	}
+
+	if news.Status != previous {
+		reason := "Edited " + strings.Join(changed, ", ")
+		if err := recordReview(tx, "news", newsID, reviewResubmitted, userID.(int), reason, nil); err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to update news post")
+			return
+		}
+	}
+
+	if err := logActivity(tx, userID.(int), "news_updated", "news", newsID, gin.H{"changed": changed}); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update news post")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to update news post")
+		return
+	}
+
+	response := gin.H{
+		"news_id":         newsID,
+		"status":          news.Status,
+		"changed":         changed,
+		"review_required": news.Status != previous,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "News post updated successfully", response)
+}
+
+// newsList is how the published news can be sorted and filtered
+var newsList = listing.Spec{
+	Key: "n.news_id",
+	Sorts: map[string]listing.Sort{
+		"published_at": {Column: "COALESCE(n.published_at, n.created_at)", Type: "timestamptz"},
+		"title":        {Column: "n.title", Type: "text"},
+	},
+	DefaultSort: "-published_at",
+	Filters: map[string]listing.Filter{
+		"club_id":  {Column: "n.club_id", Kind: listing.IntEquals},
+		"category": {Column: "n.category", Kind: listing.Equals},
+		"featured": {Column: "n.is_featured", Kind: listing.BoolEquals},
+		"q":        {Column: "n.title", Kind: listing.Contains},
+		"from":     {Column: "n.published_at", Kind: listing.From},
+		"to":       {Column: "n.published_at", Kind: listing.Until},
+	},
+}
+
+// GetAllNews retrieves published news a page at a time
 func GetAllNews(c *gin.Context) {
+	page := parsePage(c, &newsList)
+	if page == nil {
+		return
+	}
+	page.Where("n.status = 'published'")
+
+	total, err := page.Count(database.DB, "news n")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch news")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			n.news_id, n.title, n.content, n.category, n.is_featured, n.published_at,
 			c.club_name, c.club_code, c.logo_url,
-			u.first_name || ' ' || u.last_name as author
+			u.first_name || ' ' || u.last_name as author,
+			`+page.CursorColumns()+`
 		 FROM news n
 		 JOIN clubs c ON n.club_id = c.club_id
-		 JOIN users u ON n.created_by = u.user_id
-		 WHERE n.status IN ('published')
-		 ORDER BY n.published_at DESC
-		 LIMIT 20`,
+		 JOIN users u ON n.created_by = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		fmt.Printf("DEBUG: Query error: %v\n", err)
-		utils.InternalServerErrorResponse(c, "Failed to fetch news")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch news")
 		return
 	}
 	defer rows.Close()
@@ -86,17 +236,21 @@ func GetAllNews(c *gin.Context) {
 		Author       string `json:"author"`
 	}
 
-	newsList := make([]NewsItem, 0)
+	items := make([]NewsItem, 0)
 
 	for rows.Next() {
 		var news NewsItem
 		var logoURL sql.NullString
 		var publishedAt sql.NullString
+		var cursor listing.Cursor
 		err := rows.Scan(&news.NewsID, &news.Title, &news.Content, &news.Category, &news.IsFeatured, &publishedAt,
-			&news.ClubName, &news.ClubCode, &logoURL, &news.Author)
+			&news.ClubName, &news.ClubCode, &logoURL, &news.Author, &cursor.Value, &cursor.Key)
 		if err != nil {
-			fmt.Printf("DEBUG: Scan error: %v\n", err)
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch news")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		if logoURL.Valid {
 			news.LogoURL = logoURL.String
@@ -104,29 +258,50 @@ func GetAllNews(c *gin.Context) {
 		if publishedAt.Valid {
 			news.PublishedAt = publishedAt.String
 		}
-		newsList = append(newsList, news)
+		items = append(items, news)
 	}
 
-	fmt.Printf("DEBUG: News items found: %d\n", len(newsList))
-	utils.SuccessResponse(c, http.StatusOK, "News retrieved successfully", newsList)
+	utils.PageResponse(c, http.StatusOK, "News retrieved successfully", items, page.Meta(total))
+}
+
+// featuredNewsList is how the featured news can be sorted
+var featuredNewsList = listing.Spec{
+	Key: "n.news_id",
+	Sorts: map[string]listing.Sort{
+		"published_at": {Column: "COALESCE(n.published_at, n.created_at)", Type: "timestamptz"},
+	},
+	DefaultSort:  "-published_at",
+	DefaultLimit: 5,
 }
 
 // GetFeaturedNews retrieves featured news for homepage
 func GetFeaturedNews(c *gin.Context) {
+	page := parsePage(c, &featuredNewsList)
+	if page == nil {
+		return
+	}
+	page.Where("n.status = 'published' AND n.is_featured = TRUE")
+
+	total, err := page.Count(database.DB, "news n")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch featured news")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			n.news_id, n.title, n.content, n.published_at,
 			c.club_name, c.club_code,
-			(SELECT media_url FROM news_media WHERE news_id = n.news_id AND media_type = 'image' ORDER BY display_order LIMIT 1) as featured_image
+			(SELECT media_url FROM news_media WHERE news_id = n.news_id AND media_type = 'image' ORDER BY display_order LIMIT 1) as featured_image,
+			`+page.CursorColumns()+`
 		 FROM news n
-		 JOIN clubs c ON n.club_id = c.club_id
-		 WHERE n.status = 'published' AND n.is_featured = TRUE
-		 ORDER BY n.published_at DESC
-		 LIMIT 5`,
+		 JOIN clubs c ON n.club_id = c.club_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch featured news")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch featured news")
 		return
 	}
 	defer rows.Close()
@@ -141,26 +316,33 @@ func GetFeaturedNews(c *gin.Context) {
 		FeaturedImage string `json:"featured_image"`
 	}
 
-	var newsList []FeaturedNews
+	items := make([]FeaturedNews, 0)
 
 	for rows.Next() {
 		var news FeaturedNews
 		var featuredImage sql.NullString
+		var cursor listing.Cursor
 		err := rows.Scan(&news.NewsID, &news.Title, &news.Content, &news.PublishedAt,
-			&news.ClubName, &news.ClubCode, &featuredImage)
+			&news.ClubName, &news.ClubCode, &featuredImage, &cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch featured news")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		if featuredImage.Valid {
 			news.FeaturedImage = featuredImage.String
 		}
-		newsList = append(newsList, news)
+		items = append(items, news)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Featured news retrieved", newsList)
+	utils.PageResponse(c, http.StatusOK, "Featured news retrieved", items, page.Meta(total))
 }
 
-// GetNewsDetails retrieves detailed information about a news post
+// GetNewsDetails retrieves detailed information about a news post. Posts
+// that aren't published are only shown to their author and the club's
+// moderators, along with the outcome of the last review.
 func GetNewsDetails(c *gin.Context) {
 	newsID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -168,55 +350,57 @@ func GetNewsDetails(c *gin.Context) {
 		return
 	}
 
-	var news models.News
-
-	err = database.DB.QueryRow(
-		`SELECT 
-			n.news_id, n.title, n.content, n.category, n.is_featured, n.published_at, n.created_at, n.updated_at,
-			c.club_name, c.club_code,
-			u.first_name || ' ' || u.last_name as author,
-			n.club_id, n.created_by
-		 FROM news n
-		 JOIN clubs c ON n.club_id = c.club_id
-		 JOIN users u ON n.created_by = u.user_id
-		 WHERE n.news_id = $1 AND n.status = 'published'`,
-		newsID,
-	).Scan(&news.NewsID, &news.Title, &news.Content, &news.Category, &news.IsFeatured, &news.PublishedAt, &news.CreatedAt, &news.UpdatedAt,
-		&news.ClubName, &news.ClubCode, &news.Author, &news.ClubID, &news.CreatedBy)
-
+	news, err := stores.News.Get(newsID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == store.ErrNotFound {
 			utils.NotFoundResponse(c, "News post not found")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch news details")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch news details")
 		return
 	}
 
-	// Fetch media
-	mediaRows, err := database.DB.Query(
-		`SELECT media_id, media_type, media_url, caption, display_order, uploaded_at
-		 FROM news_media
-		 WHERE news_id = $1
-		 ORDER BY display_order`,
-		newsID,
-	)
-
-	if err == nil {
-		defer mediaRows.Close()
-		for mediaRows.Next() {
-			var media models.NewsMedia
-			err := mediaRows.Scan(&media.MediaID, &media.MediaType, &media.MediaURL, &media.Caption, &media.DisplayOrder, &media.UploadedAt)
-			if err == nil {
-				news.Media = append(news.Media, media)
-			}
+	canSee, err := canSeeReviews(c, news.ClubID, news.CreatedBy)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch news details")
+		return
+	}
+	if news.Status != "published" && !canSee {
+		utils.NotFoundResponse(c, "News post not found")
+		return
+	}
+	if canSee {
+		news.LatestReview, err = latestReview("news", newsID)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch news details")
+			return
 		}
 	}
 
+	news.Media, err = stores.News.Media(newsID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch news media")
+		return
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "News details retrieved", news)
 }
 
-// GetClubNews retrieves all published news for a specific club
+// clubNewsList is how a club's published news can be sorted and filtered
+var clubNewsList = listing.Spec{
+	Key: "n.news_id",
+	Sorts: map[string]listing.Sort{
+		"published_at": {Column: "COALESCE(n.published_at, n.created_at)", Type: "timestamptz"},
+		"title":        {Column: "n.title", Type: "text"},
+	},
+	DefaultSort: "-published_at",
+	Filters: map[string]listing.Filter{
+		"category": {Column: "n.category", Kind: listing.Equals},
+		"q":        {Column: "n.title", Kind: listing.Contains},
+	},
+}
+
+// GetClubNews retrieves the published news of a specific club
 func GetClubNews(c *gin.Context) {
 	clubID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -224,19 +408,32 @@ func GetClubNews(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &clubNewsList)
+	if page == nil {
+		return
+	}
+	page.Where("n.club_id = " + page.Arg(clubID))
+	page.Where("n.status = 'published'")
+
+	total, err := page.Count(database.DB, "news n")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club news")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			n.news_id, n.title, n.content, n.category, n.published_at,
-			u.first_name || ' ' || u.last_name as author
+			u.first_name || ' ' || u.last_name as author,
+			`+page.CursorColumns()+`
 		 FROM news n
-		 JOIN users u ON n.created_by = u.user_id
-		 WHERE n.club_id = $1 AND n.status = 'published'
-		 ORDER BY n.published_at DESC`,
-		clubID,
+		 JOIN users u ON n.created_by = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch club news")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch club news")
 		return
 	}
 	defer rows.Close()
@@ -250,18 +447,24 @@ func GetClubNews(c *gin.Context) {
 		Author      string `json:"author"`
 	}
 
-	var newsList []ClubNewsItem
+	items := make([]ClubNewsItem, 0)
 
 	for rows.Next() {
 		var news ClubNewsItem
-		err := rows.Scan(&news.NewsID, &news.Title, &news.Content, &news.Category, &news.PublishedAt, &news.Author)
+		var cursor listing.Cursor
+		err := rows.Scan(&news.NewsID, &news.Title, &news.Content, &news.Category, &news.PublishedAt, &news.Author,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch club news")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
-		newsList = append(newsList, news)
+		items = append(items, news)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Club news retrieved", newsList)
+	utils.PageResponse(c, http.StatusOK, "Club news retrieved", items, page.Meta(total))
 }
 
 // AddNewsMedia adds media to a news post
@@ -273,27 +476,26 @@ func AddNewsMedia(c *gin.Context) {
 	}
 
 	var req struct {
-		MediaType    string `json:"media_type" binding:"required"`
+		MediaType    string `json:"media_type" binding:"required,oneof=image video"`
 		MediaURL     string `json:"media_url" binding:"required"`
 		Caption      string `json:"caption"`
 		DisplayOrder int    `json:"display_order"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	var mediaID int
-	err = database.DB.QueryRow(
-		`INSERT INTO news_media (news_id, media_type, media_url, caption, display_order)
-		 VALUES ($1, $2, $3, $4, $5)
-		 RETURNING media_id`,
-		newsID, req.MediaType, req.MediaURL, req.Caption, req.DisplayOrder,
-	).Scan(&mediaID)
+	mediaID, err := stores.News.AddMedia(newsID, models.NewsMedia{
+		MediaType:    req.MediaType,
+		MediaURL:     req.MediaURL,
+		Caption:      req.Caption,
+		DisplayOrder: req.DisplayOrder,
+	})
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to add media")
+		utils.InternalServerErrorResponse(c, err, "Failed to add media")
 		return
 	}
 
@@ -304,11 +506,29 @@ func AddNewsMedia(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Media added successfully", response)
 }
 
-// ApproveNews approves a pending news post
+// ApproveNews approves a pending news post, notifying its author and the
+// members of the club. The reviewer may add a note and comments.
 func ApproveNews(c *gin.Context) {
+	setNewsReviewStatus(c, "published")
+}
+
+// RejectNews rejects a pending news post with a reason and optional comments
+// on its fields, notifying its author
+func RejectNews(c *gin.Context) {
+	setNewsReviewStatus(c, "rejected")
+}
+
+// setNewsReviewStatus records an admin's approval or rejection of a pending
+// news post and adds it to the post's review history
+func setNewsReviewStatus(c *gin.Context, status string) {
+	action, done := "approve", reviewApproved
+	if status == "rejected" {
+		action, done = "reject", reviewRejected
+	}
+
 	role, _ := c.Get("role")
 	if role != "system_admin" {
-		utils.ForbiddenResponse(c, "Only system admins can approve news")
+		utils.ForbiddenResponse(c, "Only system admins can "+action+" news")
 		return
 	}
 
@@ -318,51 +538,110 @@ func ApproveNews(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`UPDATE news
-		 SET status = 'published', published_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-		 WHERE news_id = $1`,
-		newsID,
-	)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
 
+	review, ok := bindReview(c, "news", status == "rejected")
+	if !ok {
+		return
+	}
+
+	tx, err := database.Begin()
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to approve news")
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" news")
 		return
 	}
+	defer tx.Rollback()
 
-	utils.SuccessResponse(c, http.StatusOK, "News approved successfully", nil)
-}
+	var current string
+	err = tx.QueryRow(`SELECT status FROM news WHERE news_id = $1 FOR UPDATE`, newsID).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "News not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" news")
+		return
+	}
+	if current != "pending" {
+		utils.ErrorResponse(c, http.StatusConflict,
+			"The news post is "+current+" and can't be "+done, "invalid_status_transition")
+		return
+	}
 
-// RejectNews rejects a pending news post
-func RejectNews(c *gin.Context) {
-	role, _ := c.Get("role")
-	if role != "system_admin" {
-		utils.ForbiddenResponse(c, "Only system admins can reject news")
+	var clubID, createdBy int
+	var title, clubName string
+	err = tx.QueryRow(
+		`UPDATE news n
+		 SET status = $1,
+		     published_at = CASE WHEN $3 THEN CURRENT_TIMESTAMP ELSE n.published_at END,
+		     updated_at = CURRENT_TIMESTAMP
+		 FROM clubs cl
+		 WHERE n.news_id = $2 AND cl.club_id = n.club_id
+		 RETURNING n.club_id, n.created_by, n.title, cl.club_name`,
+		status, newsID, status == "published",
+	).Scan(&clubID, &createdBy, &title, &clubName)
+
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" news")
 		return
 	}
 
-	newsID, err := strconv.Atoi(c.Param("id"))
+	err = recordReview(tx, "news", newsID, done, userID.(int), review.Reason, review.Comments)
 	if err != nil {
-		utils.BadRequestResponse(c, "Invalid news ID")
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" news")
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`UPDATE news
-		 SET status = 'rejected', updated_at = CURRENT_TIMESTAMP
-		 WHERE news_id = $1`,
-		newsID,
-	)
+	data := map[string]interface{}{
+		"NewsTitle": title,
+		"ClubName":  clubName,
+		"Reason":    review.Reason,
+	}
 
+	if status == "published" {
+		err = notifier.ToUser(tx, createdBy, notifier.Notification{
+			Kind: notifier.NewsApproved, EntityType: "news", EntityID: newsID, Data: data,
+		})
+		if err == nil {
+			err = notifier.ToClubMembers(tx, clubID, notifier.Notification{
+				Kind: notifier.NewsPublished, EntityType: "news", EntityID: newsID, Data: data,
+			}, createdBy)
+		}
+	} else {
+		err = notifier.ToUser(tx, createdBy, notifier.Notification{
+			Kind: notifier.NewsRejected, EntityType: "news", EntityID: newsID, Data: data,
+		})
+	}
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to reject news")
+		utils.InternalServerErrorResponse(c, err, "Failed to send notifications")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to "+action+" news")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "News rejected successfully", nil)
+	utils.SuccessResponse(c, http.StatusOK, "News "+done+" successfully", nil)
 }
 
-// GetPendingNews retrieves all pending news for admin approval
+// pendingNewsList is how the news awaiting review can be sorted and filtered
+var pendingNewsList = listing.Spec{
+	Key: "n.news_id",
+	Sorts: map[string]listing.Sort{
+		"created_at": {Column: "n.created_at", Type: "timestamptz"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"club_id": {Column: "n.club_id", Kind: listing.IntEquals},
+	},
+}
+
+// GetPendingNews retrieves the pending news awaiting admin approval
 func GetPendingNews(c *gin.Context) {
 	role, _ := c.Get("role")
 	if role != "system_admin" {
@@ -370,19 +649,32 @@ func GetPendingNews(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &pendingNewsList)
+	if page == nil {
+		return
+	}
+	page.Where("n.status = 'pending'")
+
+	total, err := page.Count(database.DB, "news n")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch pending news")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
 		`SELECT 
 			n.news_id, n.title, n.content, n.created_at,
-			c.club_name, u.first_name || ' ' || u.last_name as author
+			c.club_name, u.first_name || ' ' || u.last_name as author,
+			`+page.CursorColumns()+`
 		 FROM news n
 		 JOIN clubs c ON n.club_id = c.club_id
-		 JOIN users u ON n.created_by = u.user_id
-		 WHERE n.status = 'pending'
-		 ORDER BY n.created_at DESC`,
+		 JOIN users u ON n.created_by = u.user_id`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch pending news")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch pending news")
 		return
 	}
 	defer rows.Close()
@@ -396,16 +688,22 @@ func GetPendingNews(c *gin.Context) {
 		Author    string `json:"author"`
 	}
 
-	var newsList []PendingNews
+	items := make([]PendingNews, 0)
 
 	for rows.Next() {
 		var news PendingNews
-		err := rows.Scan(&news.NewsID, &news.Title, &news.Content, &news.CreatedAt, &news.ClubName, &news.Author)
+		var cursor listing.Cursor
+		err := rows.Scan(&news.NewsID, &news.Title, &news.Content, &news.CreatedAt, &news.ClubName, &news.Author,
+			&cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch pending news")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
-		newsList = append(newsList, news)
+		items = append(items, news)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Pending news retrieved", newsList)
+	utils.PageResponse(c, http.StatusOK, "Pending news retrieved", items, page.Meta(total))
 }
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/middleware"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/realtime"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// streamHeartbeat is how often an idle stream sends a comment line, which
+// keeps proxies from closing the connection and lets the server notice
+// clients that went away. The session is checked again at the same time.
+const streamHeartbeat = 25 * time.Second
+
+// streamLookback is how far back each push looks again for notifications
+// below the stream's position. IDs are assigned when a row is inserted but
+// the row only becomes visible when its transaction commits, so a
+// notification can appear after one with a higher ID was already sent.
+const streamLookback = 2 * time.Minute
+
+// StreamNotifications keeps a Server-Sent Events connection open and pushes
+// "notification" events as notifications are created and "unread_count"
+// events whenever the unread count may have changed. Each notification
+// event carries its ID, so a reconnecting client that sends Last-Event-ID
+// receives the notifications it missed. The stream closes once the access
+// token expires or the session is revoked, and the client reconnects with a
+// fresh token.
+func StreamNotifications(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+	uid := userID.(int)
+
+	// Resume after the last event the client saw, or start from now
+	var lastID int
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		id, err := strconv.Atoi(header)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid Last-Event-ID")
+			return
+		}
+		lastID = id
+	} else {
+		err := database.DB.QueryRow(
+			`SELECT COALESCE(MAX(notification_id), 0) FROM notifications WHERE user_id = $1`,
+			uid,
+		).Scan(&lastID)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to open notification stream")
+			return
+		}
+	}
+
+	cursor, err := newNotificationCursor(uid, lastID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to open notification stream")
+		return
+	}
+
+	sub := realtime.LocalHub.Subscribe(uid)
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Send anything missed since Last-Event-ID along with the current count
+	if err := pushNotificationUpdates(c, uid, cursor); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case <-sub.C:
+			if err := pushNotificationUpdates(c, uid, cursor); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if err := middleware.RevalidateSession(c); err != nil {
+				return
+			}
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// streamBatchSize bounds how many notifications are read per query while
+// catching a stream up
+const streamBatchSize = 100
+
+// notificationCursor is a stream's position: the highest notification ID
+// sent, and the notifications inside the lookback window the client already
+// has, by when they were created
+type notificationCursor struct {
+	lastID int
+	sent   map[int]time.Time
+}
+
+// newNotificationCursor starts a stream after lastID. Notifications at or
+// below it that are inside the lookback window are taken as already seen.
+func newNotificationCursor(userID, lastID int) (*notificationCursor, error) {
+	cursor := &notificationCursor{lastID: lastID, sent: make(map[int]time.Time)}
+	recent, err := recentNotifications(userID, lastID)
+	if err != nil {
+		return nil, err
+	}
+	for _, notif := range recent {
+		cursor.sent[notif.NotificationID] = notif.CreatedAt
+	}
+	return cursor, nil
+}
+
+// pushNotificationUpdates writes the user's notifications the client hasn't
+// seen followed by their unread count, advancing the cursor past what was
+// sent
+func pushNotificationUpdates(c *gin.Context, userID int, cursor *notificationCursor) error {
+	if err := pushLateNotifications(c, userID, cursor); err != nil {
+		return err
+	}
+	for {
+		sent, err := pushNotificationBatch(c, userID, cursor)
+		if err != nil {
+			return err
+		}
+		if sent < streamBatchSize {
+			break
+		}
+	}
+
+	var count int
+	err := database.DB.QueryRow(
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE`,
+		userID,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if err := writeStreamEvent(c, "", "unread_count", gin.H{"unread_count": count}); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// pushLateNotifications writes notifications below the cursor that were
+// committed after it moved past them. They carry the cursor's position as
+// their event ID so a reconnecting client doesn't go back.
+func pushLateNotifications(c *gin.Context, userID int, cursor *notificationCursor) error {
+	recent, err := recentNotifications(userID, cursor.lastID)
+	if err != nil {
+		return err
+	}
+	for _, notif := range recent {
+		if _, ok := cursor.sent[notif.NotificationID]; ok {
+			continue
+		}
+		if err := writeStreamEvent(c, strconv.Itoa(cursor.lastID), "notification", notif); err != nil {
+			return err
+		}
+		cursor.sent[notif.NotificationID] = notif.CreatedAt
+	}
+
+	// Forget what has left the window, with a margin for clock differences
+	// between the server and the database
+	cutoff := time.Now().Add(-2 * streamLookback)
+	for id, createdAt := range cursor.sent {
+		if createdAt.Before(cutoff) {
+			delete(cursor.sent, id)
+		}
+	}
+	return nil
+}
+
+// recentNotifications reads the user's notifications at or below lastID that
+// were created inside the lookback window
+func recentNotifications(userID, lastID int) ([]models.Notification, error) {
+	rows, err := database.DB.Query(
+		`SELECT notification_id, user_id, title, message, notification_type, related_entity_type, related_entity_id, is_read, created_at
+		 FROM notifications
+		 WHERE user_id = $1 AND notification_id <= $2 AND created_at > CURRENT_TIMESTAMP - $3 * INTERVAL '1 second'
+		 ORDER BY notification_id`,
+		userID, lastID, int(streamLookback.Seconds()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		notif, err := scanStreamNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notif)
+	}
+	return notifications, rows.Err()
+}
+
+// pushNotificationBatch writes the next batch of notifications after the
+// cursor and reports how many were sent
+func pushNotificationBatch(c *gin.Context, userID int, cursor *notificationCursor) (int, error) {
+	rows, err := database.DB.Query(
+		`SELECT notification_id, user_id, title, message, notification_type, related_entity_type, related_entity_id, is_read, created_at
+		 FROM notifications
+		 WHERE user_id = $1 AND notification_id > $2
+		 ORDER BY notification_id
+		 LIMIT $3`,
+		userID, cursor.lastID, streamBatchSize,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	sent := 0
+	for rows.Next() {
+		notif, err := scanStreamNotification(rows)
+		if err != nil {
+			return sent, err
+		}
+		if err := writeStreamEvent(c, strconv.Itoa(notif.NotificationID), "notification", notif); err != nil {
+			return sent, err
+		}
+		cursor.lastID = notif.NotificationID
+		cursor.sent[notif.NotificationID] = notif.CreatedAt
+		sent++
+	}
+
+	return sent, rows.Err()
+}
+
+func scanStreamNotification(rows *sql.Rows) (models.Notification, error) {
+	var notif models.Notification
+	err := rows.Scan(&notif.NotificationID, &notif.UserID, &notif.Title, &notif.Message, &notif.NotificationType,
+		&notif.RelatedEntityType, &notif.RelatedEntityID, &notif.IsRead, &notif.CreatedAt)
+	return notif, err
+}
+
+// writeStreamEvent writes a single SSE event with a JSON payload
+func writeStreamEvent(c *gin.Context, id, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(c.Writer, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
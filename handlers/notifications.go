@@ -6,11 +6,27 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/realtime"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
-// GetUserNotifications retrieves all notifications for the current user
+// notificationList is how a user's notifications can be sorted and filtered
+var notificationList = listing.Spec{
+	Key: "notification_id",
+	Sorts: map[string]listing.Sort{
+		"created_at": {Column: "created_at", Type: "timestamptz"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"is_read": {Column: "is_read", Kind: listing.BoolEquals},
+		"type":    {Column: "notification_type", Kind: listing.Equals},
+	},
+	DefaultLimit: 50,
+}
+
+// GetUserNotifications retrieves the current user's notifications
 func GetUserNotifications(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -18,34 +34,50 @@ func GetUserNotifications(c *gin.Context) {
 		return
 	}
 
+	page := parsePage(c, &notificationList)
+	if page == nil {
+		return
+	}
+	page.Where("user_id = " + page.Arg(userID))
+
+	total, err := page.Count(database.DB, "notifications")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch notifications")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
 	rows, err := database.DB.Query(
-		`SELECT notification_id, title, message, notification_type, related_entity_type, related_entity_id, is_read, created_at
-		 FROM notifications
-		 WHERE user_id = $1
-		 ORDER BY created_at DESC
-		 LIMIT 50`,
-		userID,
+		`SELECT notification_id, title, message, notification_type, related_entity_type, related_entity_id, is_read, created_at,
+			`+page.CursorColumns()+`
+		 FROM notifications`+where+orderLimit,
+		args...,
 	)
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch notifications")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch notifications")
 		return
 	}
 	defer rows.Close()
 
-	var notifications []models.Notification
+	notifications := make([]models.Notification, 0)
 
 	for rows.Next() {
 		var notif models.Notification
+		var cursor listing.Cursor
 		err := rows.Scan(&notif.NotificationID, &notif.Title, &notif.Message, &notif.NotificationType,
-			&notif.RelatedEntityType, &notif.RelatedEntityID, &notif.IsRead, &notif.CreatedAt)
+			&notif.RelatedEntityType, &notif.RelatedEntityID, &notif.IsRead, &notif.CreatedAt, &cursor.Value, &cursor.Key)
 		if err != nil {
-			continue
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch notifications")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		notifications = append(notifications, notif)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Notifications retrieved", notifications)
+	utils.PageResponse(c, http.StatusOK, "Notifications retrieved", notifications, page.Meta(total))
 }
 
 // GetUnreadNotificationCount retrieves count of unread notifications
@@ -56,14 +88,9 @@ func GetUnreadNotificationCount(c *gin.Context) {
 		return
 	}
 
-	var count int
-	err := database.DB.QueryRow(
-		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE`,
-		userID,
-	).Scan(&count)
-
+	count, err := stores.Notifications.UnreadCount(userID.(int))
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to fetch notification count")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch notification count")
 		return
 	}
 
@@ -88,16 +115,14 @@ func MarkNotificationAsRead(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`UPDATE notifications SET is_read = TRUE WHERE notification_id = $1 AND user_id = $2`,
-		notificationID, userID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to mark notification as read")
+	if err := stores.Notifications.MarkRead(userID.(int), notificationID); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to mark notification as read")
 		return
 	}
 
+	// Let open streams refresh the unread count
+	realtime.NotifyUsers(userID.(int))
+
 	utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
 }
 
@@ -109,16 +134,14 @@ func MarkAllNotificationsAsRead(c *gin.Context) {
 		return
 	}
 
-	_, err := database.DB.Exec(
-		`UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND is_read = FALSE`,
-		userID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to mark notifications as read")
+	if err := stores.Notifications.MarkAllRead(userID.(int)); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to mark notifications as read")
 		return
 	}
 
+	// Let open streams refresh the unread count
+	realtime.NotifyUsers(userID.(int))
+
 	utils.SuccessResponse(c, http.StatusOK, "All notifications marked as read", nil)
 }
 
@@ -136,15 +159,13 @@ func DeleteNotification(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(
-		`DELETE FROM notifications WHERE notification_id = $1 AND user_id = $2`,
-		notificationID, userID,
-	)
-
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete notification")
+	if err := stores.Notifications.Delete(userID.(int), notificationID); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to delete notification")
 		return
 	}
 
+	// Let open streams refresh the unread count
+	realtime.NotifyUsers(userID.(int))
+
 	utils.SuccessResponse(c, http.StatusOK, "Notification deleted", nil)
 }
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// parsePage reads the pagination, sort and filter parameters of a list
+// request. On failure it writes the error response and returns nil.
+func parsePage(c *gin.Context, spec *listing.Spec) *listing.Page {
+	page, err := listing.Parse(c, spec)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return nil
+	}
+	return page
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// Review actions
+const (
+	reviewApproved    = "approved"
+	reviewRejected    = "rejected"
+	reviewResubmitted = "resubmitted"
+)
+
+// reviewFields are the fields reviewers can comment on, by entity type
+var reviewFields = map[string][]string{
+	"event": {"title", "description", "event_type", "location", "start_datetime", "end_datetime",
+		"registration_deadline", "capacity", "banner_image_url"},
+	"news": {"title", "content", "category"},
+}
+
+const (
+	maxReviewComments = 50
+	maxReviewText     = 2000
+)
+
+// bindReview reads a reviewer's decision on an event or news post. The body
+// is optional when approving; rejecting needs a reason.
+func bindReview(c *gin.Context, entityType string, rejecting bool) (models.ReviewRequest, bool) {
+	var req models.ReviewRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BindErrorResponse(c, err)
+		return req, false
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	switch {
+	case rejecting && req.Reason == "":
+		utils.BadRequestResponse(c, "A reason is required to reject")
+		return req, false
+	case len(req.Reason) > maxReviewText:
+		utils.BadRequestResponse(c, "reason must be at most "+strconv.Itoa(maxReviewText)+" characters")
+		return req, false
+	case len(req.Comments) > maxReviewComments:
+		utils.BadRequestResponse(c, "At most "+strconv.Itoa(maxReviewComments)+" comments are allowed")
+		return req, false
+	}
+
+	for i := range req.Comments {
+		comment := &req.Comments[i]
+		comment.Comment = strings.TrimSpace(comment.Comment)
+		prefix := "comments[" + strconv.Itoa(i) + "]: "
+		switch {
+		case !reviewableField(entityType, comment.Field):
+			utils.BadRequestResponse(c, prefix+"unknown field "+strconv.Quote(comment.Field))
+			return req, false
+		case comment.Comment == "":
+			utils.BadRequestResponse(c, prefix+"comment can't be empty")
+			return req, false
+		case len(comment.Comment) > maxReviewText:
+			utils.BadRequestResponse(c, prefix+"comment must be at most "+strconv.Itoa(maxReviewText)+" characters")
+			return req, false
+		case comment.Line < 0:
+			utils.BadRequestResponse(c, prefix+"line must be positive")
+			return req, false
+		}
+	}
+
+	return req, true
+}
+
+// reviewableField reports whether reviewers can comment on a field of an
+// entity type
+func reviewableField(entityType, field string) bool {
+	for _, allowed := range reviewFields[entityType] {
+		if allowed == field {
+			return true
+		}
+	}
+	return false
+}
+
+// recordReview adds an entry to the review history of an event or news post.
+// userID is the reviewer, or the author for a resubmission.
+func recordReview(exec database.Execer, entityType string, entityID int, action string, userID int, reason string, comments []models.ReviewComment) error {
+	_, err := exec.Exec(
+		`INSERT INTO content_reviews (entity_type, entity_id, action, user_id, reason, comments)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		entityType, entityID, action, userID, reason, models.ReviewComments(comments),
+	)
+	return err
+}
+
+// latestReview returns the most recent approval or rejection of an event or
+// news post, or nil if it hasn't been reviewed
+func latestReview(entityType string, entityID int) (*models.ContentReview, error) {
+	review, err := stores.Reviews.Latest(entityType, entityID)
+	if err == store.ErrNotFound {
+		return nil, nil
+	}
+	return review, err
+}
+
+// canSeeReviews reports whether the current user may see the reviews of an
+// item: its author, the club's moderators and system admins
+func canSeeReviews(c *gin.Context, clubID, createdBy int) (bool, error) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return false, nil
+	}
+	if userID.(int) == createdBy || c.GetString("role") == "system_admin" {
+		return true, nil
+	}
+	return stores.Clubs.IsModerator(userID.(int), clubID)
+}
+
+// reviewList is how the review history of an item can be sorted and filtered
+var reviewList = listing.Spec{
+	Key: "r.review_id",
+	Sorts: map[string]listing.Sort{
+		"created_at": {Column: "r.created_at", Type: "timestamptz"},
+	},
+	DefaultSort: "created_at",
+	Filters: map[string]listing.Filter{
+		"action": {Column: "r.action", Kind: listing.Equals},
+	},
+}
+
+// GetEventReviews retrieves the review history of an event, oldest first
+func GetEventReviews(c *gin.Context) {
+	getContentReviews(c, "event", "Invalid event ID")
+}
+
+// GetNewsReviews retrieves the review history of a news post, oldest first
+func GetNewsReviews(c *gin.Context) {
+	getContentReviews(c, "news", "Invalid news ID")
+}
+
+func getContentReviews(c *gin.Context, entityType, invalidID string) {
+	entityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, invalidID)
+		return
+	}
+
+	page := parsePage(c, &reviewList)
+	if page == nil {
+		return
+	}
+	page.Where("r.entity_type = " + page.Arg(entityType))
+	page.Where("r.entity_id = " + page.Arg(entityID))
+
+	total, err := page.Count(database.DB, "content_reviews r")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch reviews")
+		return
+	}
+
+	where, orderLimit, args := page.PageSQL()
+	rows, err := database.DB.Query(
+		`SELECT
+			r.review_id, r.entity_type, r.entity_id, r.action, r.user_id,
+			COALESCE(u.first_name || ' ' || u.last_name, ''), r.reason, r.comments, r.created_at,
+			`+page.CursorColumns()+`
+		 FROM content_reviews r
+		 LEFT JOIN users u ON r.user_id = u.user_id`+where+orderLimit,
+		args...,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch reviews")
+		return
+	}
+	defer rows.Close()
+
+	reviews := make([]models.ContentReview, 0)
+	for rows.Next() {
+		var review models.ContentReview
+		var cursor listing.Cursor
+		err := rows.Scan(&review.ReviewID, &review.EntityType, &review.EntityID, &review.Action, &review.UserID,
+			&review.UserName, &review.Reason, &review.Comments, &review.CreatedAt, &cursor.Value, &cursor.Key)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to fetch reviews")
+			return
+		}
+		if !page.Add(cursor) {
+			break
+		}
+		reviews = append(reviews, review)
+	}
+
+	utils.PageResponse(c, http.StatusOK, "Reviews retrieved", reviews, page.Meta(total))
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+const maxSearchQueryLength = 200
+
+// Matched terms are marked with private-use characters by ts_headline, so the
+// text can be HTML-escaped before the marks are turned into tags
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+var (
+	titleHighlight   = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=true`
+	snippetHighlight = `StartSel="` + highlightStart + `", StopSel="` + highlightStop +
+		`", MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "`
+	highlightTags = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+)
+
+// searchSource is an entity type that can be searched
+type searchSource struct {
+	// name is the type parameter value and the key of the results group
+	name       string
+	entityType string
+	// vector is the search_vector column matched against the query
+	vector string
+	spec   listing.Spec
+	// from is the FROM clause; %[1]s is the search query placeholder, bound
+	// as the tsquery q
+	from string
+	// visible limits the results to what anyone may see
+	visible string
+	// columns selects the id, title, snippet, rank, date, club name and club
+	// code; %[1]s and %[2]s are the title and snippet highlight options
+	columns string
+}
+
+// searchSpec is how results of a search source can be sorted: by relevance
+// to the query or by date
+func searchSpec(key, vector, date string) listing.Spec {
+	return listing.Spec{
+		Key: key,
+		Sorts: map[string]listing.Sort{
+			"rank": {Column: "ts_rank(" + vector + ", q)", Type: "real"},
+			"date": {Column: date, Type: "timestamptz"},
+		},
+		DefaultSort:  "-rank",
+		DefaultLimit: 10,
+	}
+}
+
+var searchSources = []searchSource{
+	{
+		name:       "events",
+		entityType: "event",
+		vector:     "e.search_vector",
+		spec:       searchSpec("e.event_id", "e.search_vector", "e.start_datetime"),
+		from: `events e
+		 JOIN clubs c ON e.club_id = c.club_id
+		 CROSS JOIN websearch_to_tsquery('english', %[1]s) q`,
+		visible: "e.status IN ('approved', 'completed')",
+		columns: `e.event_id,
+			ts_headline('english', e.title, q, %[1]s),
+			ts_headline('english', e.description, q, %[2]s),
+			ts_rank(e.search_vector, q), e.start_datetime, c.club_name, c.club_code`,
+	},
+	{
+		name:       "news",
+		entityType: "news",
+		vector:     "n.search_vector",
+		spec:       searchSpec("n.news_id", "n.search_vector", "COALESCE(n.published_at, n.created_at)"),
+		from: `news n
+		 JOIN clubs c ON n.club_id = c.club_id
+		 CROSS JOIN websearch_to_tsquery('english', %[1]s) q`,
+		visible: "n.status = 'published'",
+		columns: `n.news_id,
+			ts_headline('english', n.title, q, %[1]s),
+			ts_headline('english', n.content, q, %[2]s),
+			ts_rank(n.search_vector, q), COALESCE(n.published_at, n.created_at), c.club_name, c.club_code`,
+	},
+	{
+		name:       "clubs",
+		entityType: "club",
+		vector:     "c.search_vector",
+		spec:       searchSpec("c.club_id", "c.search_vector", "c.created_at"),
+		from: `clubs c
+		 CROSS JOIN websearch_to_tsquery('english', %[1]s) q`,
+		visible: "c.is_active = TRUE",
+		columns: `c.club_id,
+			ts_headline('english', c.club_name, q, %[1]s),
+			ts_headline('english', c.description, q, %[2]s),
+			ts_rank(c.search_vector, q), c.created_at, c.club_name, c.club_code`,
+	},
+	{
+		name:       "announcements",
+		entityType: "announcement",
+		vector:     "sa.search_vector",
+		spec:       searchSpec("sa.announcement_id", "sa.search_vector", "sa.created_at"),
+		from: `system_announcements sa
+		 CROSS JOIN websearch_to_tsquery('english', %[1]s) q`,
+		visible: "sa.is_active = TRUE AND (sa.expires_at IS NULL OR sa.expires_at > CURRENT_TIMESTAMP)",
+		columns: `sa.announcement_id,
+			ts_headline('english', sa.title, q, %[1]s),
+			ts_headline('english', sa.content, q, %[2]s),
+			ts_rank(sa.search_vector, q), sa.created_at, '', ''`,
+	},
+}
+
+// Search runs a full-text search across events, news, clubs and
+// announcements, returning the best matches of each type with highlighted
+// snippets. The type parameter limits the search to one type, which is
+// required to page through results with a cursor.
+func Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		utils.BadRequestResponse(c, "Search query is required")
+		return
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		utils.BadRequestResponse(c, fmt.Sprintf("Search query must be at most %d characters", maxSearchQueryLength))
+		return
+	}
+
+	sources := searchSources
+	if name := c.Query("type"); name != "" {
+		sources = nil
+		for _, source := range searchSources {
+			if source.name == name {
+				sources = []searchSource{source}
+			}
+		}
+		if sources == nil {
+			utils.BadRequestResponse(c, "type must be one of: events, news, clubs, announcements")
+			return
+		}
+	} else if c.Query("cursor") != "" {
+		utils.BadRequestResponse(c, "A cursor can only be used when searching a single type")
+		return
+	}
+
+	var results models.SearchResults
+	for _, source := range sources {
+		group, err := source.search(c, query)
+		if err != nil {
+			if listErr, ok := err.(*listing.Error); ok {
+				utils.BadRequestResponse(c, listErr.Message)
+				return
+			}
+			utils.InternalServerErrorResponse(c, err, "Failed to search")
+			return
+		}
+
+		switch source.name {
+		case "events":
+			results.Events = group
+		case "news":
+			results.News = group
+		case "clubs":
+			results.Clubs = group
+		case "announcements":
+			results.Announcements = group
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Search results retrieved successfully", results)
+}
+
+// search fetches a page of the source's matches for the query
+func (s *searchSource) search(c *gin.Context, query string) (*models.SearchGroup, error) {
+	page, err := listing.Parse(c, &s.spec)
+	if err != nil {
+		return nil, err
+	}
+	from := fmt.Sprintf(s.from, page.Arg(query))
+	page.Where(s.visible)
+	page.Where(s.vector + " @@ q")
+
+	total, err := page.Count(database.DB, from)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := fmt.Sprintf(s.columns, page.Arg(titleHighlight), page.Arg(snippetHighlight))
+	where, orderLimit, args := page.PageSQL()
+	rows, err := database.DB.Query(
+		`SELECT `+columns+`,
+			`+page.CursorColumns()+`
+		 FROM `+from+where+orderLimit,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := make([]models.SearchHit, 0)
+	for rows.Next() {
+		hit := models.SearchHit{EntityType: s.entityType}
+		var cursor listing.Cursor
+		err := rows.Scan(&hit.EntityID, &hit.Title, &hit.Snippet, &hit.Rank, &hit.Date, &hit.ClubName, &hit.ClubCode,
+			&cursor.Value, &cursor.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !page.Add(cursor) {
+			break
+		}
+		hit.Title = highlight(hit.Title)
+		hit.Snippet = highlight(hit.Snippet)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &models.SearchGroup{Items: hits, Meta: page.Meta(total)}, nil
+}
+
+// highlight escapes a ts_headline result for HTML and marks its matches
+func highlight(text string) string {
+	return highlightTags.Replace(html.EscapeString(text))
+}
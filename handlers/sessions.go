@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// refreshTokenBytes is the entropy of each refresh token
+const refreshTokenBytes = 32
+
+// authTokens is the token pair returned by login, registration and refresh
+type authTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// responseFields returns the token fields merged into auth responses
+func (t authTokens) responseFields() gin.H {
+	return gin.H{
+		"token":         t.AccessToken,
+		"refresh_token": t.RefreshToken,
+		"expires_in":    int(config.AppConfig.JWTExpiration.Seconds()),
+	}
+}
+
+// startSession creates a server-side session for the user and issues an
+// access token bound to it along with a fresh refresh token
+func startSession(c *gin.Context, userID int, email, role string) (authTokens, error) {
+	refreshToken, err := utils.GenerateSecureToken(refreshTokenBytes)
+	if err != nil {
+		return authTokens{}, err
+	}
+
+	var sessionID int64
+	err = database.DB.QueryRow(
+		`INSERT INTO sessions (user_id, refresh_token_hash, user_agent, ip_address, expires_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING session_id`,
+		userID, utils.HashToken(refreshToken), c.Request.UserAgent(), c.ClientIP(),
+		time.Now().Add(config.AppConfig.RefreshTokenExpiration),
+	).Scan(&sessionID)
+
+	if err != nil {
+		return authTokens{}, err
+	}
+
+	accessToken, err := utils.GenerateToken(userID, email, role, sessionID)
+	if err != nil {
+		return authTokens{}, err
+	}
+
+	return authTokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// revokeUserSessions revokes every active session belonging to a user
+func revokeUserSessions(exec database.Execer, userID int) error {
+	return store.RevokeSessions(exec, userID)
+}
+
+// RefreshToken exchanges a refresh token for a new access token, rotating the
+// refresh token. Presenting an already-rotated token revokes the session.
+func RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	tokenHash := utils.HashToken(req.RefreshToken)
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to refresh token")
+		return
+	}
+	defer tx.Rollback()
+
+	var sessionID int64
+	var userID int
+	var email, role string
+	var isActive bool
+	var expiresAt time.Time
+	var revokedAt sql.NullTime
+
+	err = tx.QueryRow(
+		`SELECT s.session_id, s.expires_at, s.revoked_at, u.user_id, u.email, u.role, u.is_active
+		 FROM sessions s
+		 JOIN users u ON s.user_id = u.user_id
+		 WHERE s.refresh_token_hash = $1
+		 FOR UPDATE OF s`,
+		tokenHash,
+	).Scan(&sessionID, &expiresAt, &revokedAt, &userID, &email, &role, &isActive)
+
+	if err == sql.ErrNoRows {
+		// A token that was already rotated away means it has been copied;
+		// revoke the session it belonged to so neither copy keeps working
+		result, err := tx.Exec(
+			`UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+			 WHERE previous_token_hash = $1 AND revoked_at IS NULL`,
+			tokenHash,
+		)
+		if err == nil {
+			if affected, _ := result.RowsAffected(); affected > 0 {
+				tx.Commit()
+			}
+		}
+		utils.UnauthorizedResponse(c, "Invalid or expired refresh token")
+		return
+	}
+
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to refresh token")
+		return
+	}
+
+	if revokedAt.Valid || time.Now().After(expiresAt) || !isActive {
+		utils.UnauthorizedResponse(c, "Invalid or expired refresh token")
+		return
+	}
+
+	newRefreshToken, err := utils.GenerateSecureToken(refreshTokenBytes)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to refresh token")
+		return
+	}
+
+	_, err = tx.Exec(
+		`UPDATE sessions
+		 SET previous_token_hash = refresh_token_hash,
+		     refresh_token_hash = $1,
+		     last_used_at = CURRENT_TIMESTAMP,
+		     expires_at = $2
+		 WHERE session_id = $3`,
+		utils.HashToken(newRefreshToken), time.Now().Add(config.AppConfig.RefreshTokenExpiration), sessionID,
+	)
+
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to refresh token")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to refresh token")
+		return
+	}
+
+	// The new access token carries the user's current role
+	accessToken, err := utils.GenerateToken(userID, email, role, sessionID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to generate token")
+		return
+	}
+
+	tokens := authTokens{AccessToken: accessToken, RefreshToken: newRefreshToken}
+	utils.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", tokens.responseFields())
+}
+
+// Logout revokes the session behind the current access token
+func Logout(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	sessionID, _ := c.Get("session_id")
+
+	_, err := database.DB.Exec(
+		`UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		 WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		sessionID, userID,
+	)
+
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to log out")
+		return
+	}
+
+	LogActivity(userID.(int), "logout", "user", userID.(int), nil)
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAllDevices revokes every session of the current user
+func LogoutAllDevices(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	if err := revokeUserSessions(database.DB, userID.(int)); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to log out")
+		return
+	}
+
+	LogActivity(userID.(int), "logout_all_devices", "user", userID.(int), nil)
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged out of all devices", nil)
+}
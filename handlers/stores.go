@@ -0,0 +1,19 @@
+package handlers
+
+import "github.com/nub-clubs-connect/nub_admin_api/store"
+
+// stores is the data access the handlers go through, set by UseStores. It
+// covers reads and writes of single records: profiles and credentials, club
+// details and membership, event details and feedback, news posts and linked
+// media, notifications and review outcomes. Paged listings, search and admin
+// reports, and the flows that lock rows and queue notifications in one
+// database.Tx (registration, moderation, attendance, tickets and uploads),
+// query the database directly.
+var stores *store.Stores
+
+// UseStores sets the stores used by the handlers. The server passes the
+// PostgreSQL stores; tests can pass fakes. The stores are shared by every
+// handler in the process, so tests that swap them must not run in parallel.
+func UseStores(s *store.Stores) {
+	stores = s
+}
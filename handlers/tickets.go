@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/lifecycle"
+	"github.com/nub-clubs-connect/nub_admin_api/tickets"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// ticketRegistration finds the current user's ticket-holding registration
+// for the event. Only confirmed registrations have tickets; one that has
+// already been used to check in still shows its ticket.
+func ticketRegistration(c *gin.Context) (registrationID, eventID, version int, ok bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return 0, 0, 0, false
+	}
+
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return 0, 0, 0, false
+	}
+
+	err = database.DB.QueryRow(
+		`SELECT registration_id, ticket_version FROM event_registrations
+		 WHERE event_id = $1 AND user_id = $2 AND registration_status IN ('confirmed', 'attended')`,
+		eventID, userID,
+	).Scan(&registrationID, &version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "You don't have a confirmed registration for this event")
+			return 0, 0, 0, false
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch ticket")
+		return 0, 0, 0, false
+	}
+
+	return registrationID, eventID, version, true
+}
+
+// GetEventTicket returns the current user's ticket code for an event
+func GetEventTicket(c *gin.Context) {
+	registrationID, eventID, version, ok := ticketRegistration(c)
+	if !ok {
+		return
+	}
+
+	response := gin.H{
+		"registration_id": registrationID,
+		"event_id":        eventID,
+		"ticket_code":     tickets.Code(registrationID, eventID, version),
+		"qr_code_url":     "/api/events/" + strconv.Itoa(eventID) + "/ticket.png",
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket retrieved", response)
+}
+
+// GetEventTicketQRCode renders the current user's ticket for an event as a QR
+// code PNG. The optional size parameter sets its width in pixels.
+func GetEventTicketQRCode(c *gin.Context) {
+	registrationID, eventID, version, ok := ticketRegistration(c)
+	if !ok {
+		return
+	}
+
+	size := tickets.DefaultQRSize
+	if value := c.Query("size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			utils.BadRequestResponse(c, "size must be a number")
+			return
+		}
+		size = n
+	}
+
+	png, err := tickets.QRCode(tickets.Code(registrationID, eventID, version), size)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to render ticket")
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// CheckInTicket checks an attendee in by the code on their ticket. The ticket
+// must be for this approved event and be the current ticket of a confirmed
+// registration that hasn't checked in yet.
+func CheckInTicket(c *gin.Context) {
+	moderatorID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid event ID")
+		return
+	}
+
+	var req struct {
+		TicketCode string `json:"ticket_code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	ticket, err := tickets.Parse(req.TicketCode)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid ticket")
+		return
+	}
+	if ticket.EventID != eventID {
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "This ticket is for a different event", "wrong_event")
+		return
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to check in")
+		return
+	}
+	defer tx.Rollback()
+
+	var attendee struct {
+		UserID             int        `json:"user_id"`
+		StudentID          string     `json:"student_id"`
+		FirstName          string     `json:"first_name"`
+		LastName           string     `json:"last_name"`
+		RegistrationStatus string     `json:"registration_status"`
+		CheckedInAt        *time.Time `json:"checked_in_at"`
+	}
+	var ticketVersion int
+	var eventStatus string
+
+	err = tx.QueryRow(
+		`SELECT u.user_id, u.student_id, u.first_name, u.last_name, er.registration_status, er.checked_in_at,
+		        er.ticket_version, e.status
+		 FROM event_registrations er
+		 JOIN users u ON er.user_id = u.user_id
+		 JOIN events e ON er.event_id = e.event_id
+		 WHERE er.registration_id = $1 AND er.event_id = $2
+		 FOR UPDATE OF er`,
+		ticket.RegistrationID, eventID,
+	).Scan(&attendee.UserID, &attendee.StudentID, &attendee.FirstName, &attendee.LastName,
+		&attendee.RegistrationStatus, &attendee.CheckedInAt, &ticketVersion, &eventStatus)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Registration not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to check in")
+		return
+	}
+
+	switch {
+	case eventStatus != lifecycle.Approved:
+		utils.ErrorResponse(c, http.StatusConflict, "This event is "+eventStatus+", not open for check-in", "event_not_approved")
+		return
+	case attendee.RegistrationStatus == "cancelled":
+		utils.ErrorResponse(c, http.StatusConflict, "This registration has been cancelled", "registration_cancelled")
+		return
+	case attendee.RegistrationStatus == "waitlist":
+		utils.ErrorResponse(c, http.StatusConflict, "This registration is still on the waitlist", "registration_waitlisted")
+		return
+	case ticket.Version != ticketVersion:
+		utils.ErrorResponse(c, http.StatusConflict, "This ticket has been revoked", "ticket_revoked")
+		return
+	case attendee.CheckedInAt != nil:
+		utils.ErrorResponse(c, http.StatusConflict,
+			"This ticket was already used at "+attendee.CheckedInAt.Format(time.RFC3339), "already_checked_in")
+		return
+	}
+
+	var checkedInAt time.Time
+	err = tx.QueryRow(
+		`UPDATE event_registrations
+		 SET attendance_marked = TRUE, checked_in_at = CURRENT_TIMESTAMP, checked_in_by = $2
+		 WHERE registration_id = $1
+		 RETURNING checked_in_at`,
+		ticket.RegistrationID, moderatorID,
+	).Scan(&checkedInAt)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to check in")
+		return
+	}
+
+	err = logActivity(tx, moderatorID.(int), "attendee_checked_in", "event", eventID, gin.H{
+		"registration_id": ticket.RegistrationID,
+		"user_id":         attendee.UserID,
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to check in")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to check in")
+		return
+	}
+
+	attendee.CheckedInAt = &checkedInAt
+	utils.SuccessResponse(c, http.StatusOK, "Checked in successfully", attendee)
+}
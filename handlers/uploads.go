@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/imaging"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/storage"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// multipartOverhead is allowed on top of the file size limit for the form's
+// other fields and boundaries
+const multipartOverhead = 1 << 20
+
+// isMultipart reports whether the request body is a multipart form
+func isMultipart(c *gin.Context) bool {
+	return strings.HasPrefix(c.ContentType(), "multipart/form-data")
+}
+
+// uploadSizeLimit returns the configured size limit for a content type
+func uploadSizeLimit(contentType string) int64 {
+	if strings.HasPrefix(contentType, "video/") {
+		return config.AppConfig.MaxVideoUploadBytes
+	}
+	return config.AppConfig.MaxImageUploadBytes
+}
+
+// mediaUpload is a stored upload. Images also carry their dimensions and the
+// renditions generated for them.
+type mediaUpload struct {
+	*storage.Upload
+	Width      int
+	Height     int
+	Renditions models.ImageRenditions
+}
+
+// release deletes the upload's files once nothing refers to them
+func (u *mediaUpload) release() {
+	releaseStoredFiles(u.Key, u.Renditions)
+}
+
+// receiveUpload stores the "file" field of a multipart request. On failure it
+// writes the error response and returns nil.
+func receiveUpload(c *gin.Context, allowedTypes map[string]string) *mediaUpload {
+	maxBody := config.AppConfig.MaxImageUploadBytes
+	for contentType := range allowedTypes {
+		if limit := uploadSizeLimit(contentType); limit > maxBody {
+			maxBody = limit
+		}
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody+multipartOverhead)
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			uploadTooLargeResponse(c, maxBody)
+			return nil
+		}
+		utils.BadRequestResponse(c, "A file must be uploaded in the \"file\" field")
+		return nil
+	}
+	defer file.Close()
+
+	upload, err := saveUpload(c.Request.Context(), file, allowedTypes)
+	switch {
+	case err == storage.ErrUnsupportedType:
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "Unsupported file type", "unsupported_media_type")
+		return nil
+	case err == storage.ErrTooLarge:
+		uploadTooLargeResponse(c, maxBody)
+		return nil
+	case err == imaging.ErrInvalidImage:
+		utils.BadRequestResponse(c, "The image could not be read")
+		return nil
+	case err != nil:
+		utils.InternalServerErrorResponse(c, err, "Failed to store file")
+		return nil
+	}
+
+	return upload
+}
+
+// saveUpload stores an uploaded file. Images are stripped of their metadata
+// and stored along with their renditions; other files are stored as sent.
+func saveUpload(ctx context.Context, file multipart.File, allowedTypes map[string]string) (*mediaUpload, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if _, ok := allowedTypes[contentType]; !ok {
+		return nil, storage.ErrUnsupportedType
+	}
+	if _, ok := storage.ImageTypes[contentType]; !ok {
+		upload, err := storage.Save(ctx, storage.Default, file, allowedTypes, uploadSizeLimit)
+		if err != nil {
+			return nil, err
+		}
+		return &mediaUpload{Upload: upload}, nil
+	}
+
+	// Images are small enough to process in memory
+	limit := uploadSizeLimit(contentType)
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, storage.ErrTooLarge
+	}
+
+	processed, err := imaging.Process(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := storage.SaveBytes(ctx, storage.Default, processed.Original.Data, processed.Original.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	upload := &mediaUpload{
+		Upload:     original,
+		Width:      processed.Original.Width,
+		Height:     processed.Original.Height,
+		Renditions: models.ImageRenditions{},
+	}
+
+	for name, rendition := range processed.Renditions {
+		stored, err := storage.SaveBytes(ctx, storage.Default, rendition.Data, rendition.ContentType)
+		if err != nil {
+			upload.release()
+			return nil, err
+		}
+		upload.Renditions[name] = models.ImageRendition{
+			Key:    stored.Key,
+			URL:    storage.URL(stored.Key),
+			Width:  rendition.Width,
+			Height: rendition.Height,
+		}
+	}
+
+	return upload, nil
+}
+
+func uploadTooLargeResponse(c *gin.Context, limit int64) {
+	utils.ErrorResponse(c, http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("File exceeds the %d MB upload limit", limit>>20), "file_too_large")
+}
+
+// releaseStoredFiles releases an upload's file and its renditions
+func releaseStoredFiles(key string, renditions models.ImageRenditions) {
+	releaseStoredFile(key)
+	for _, renditionKey := range renditions.Keys() {
+		releaseStoredFile(renditionKey)
+	}
+}
+
+// releaseStoredFile deletes a stored file once no gallery image or news
+// media row refers to it, either as the upload or as one of its renditions.
+// Identical uploads share a key, so a file can outlive the row it was
+// uploaded for.
+func releaseStoredFile(key string) {
+	if key == "" {
+		return
+	}
+
+	var inUse bool
+	err := database.DB.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM event_gallery WHERE storage_key = $1)
+		     OR EXISTS (SELECT 1 FROM news_media WHERE storage_key = $1)
+		     OR EXISTS (SELECT 1 FROM event_gallery, jsonb_each(renditions) r WHERE r.value->>'key' = $1)
+		     OR EXISTS (SELECT 1 FROM news_media, jsonb_each(renditions) r WHERE r.value->>'key' = $1)`,
+		key,
+	).Scan(&inUse)
+	if err != nil || inUse {
+		return
+	}
+
+	if err := storage.Default.Delete(context.Background(), key); err != nil {
+		slog.Error("Failed to delete stored file", "key", key, "error", err)
+	}
+}
+
+// ServeMedia serves a stored file. Keys are content addressed, so responses
+// can be cached indefinitely.
+func ServeMedia(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !storage.ValidKey(key) {
+		utils.NotFoundResponse(c, "File not found")
+		return
+	}
+
+	// Keys never change content, so the digest is a strong validator and a
+	// conditional request can be answered without touching storage
+	etag := `"` + storage.KeyDigest(key) + `"`
+	setCacheHeaders := func() {
+		header := c.Writer.Header()
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+		header.Set("ETag", etag)
+		header.Set("X-Content-Type-Options", "nosniff")
+	}
+
+	if c.GetHeader("If-None-Match") == etag {
+		setCacheHeaders()
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	obj, err := storage.Default.Open(c.Request.Context(), key)
+	if err == storage.ErrNotFound {
+		utils.NotFoundResponse(c, "File not found")
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to read file")
+		return
+	}
+	defer obj.Body.Close()
+
+	setCacheHeaders()
+	if obj.ContentType != "" {
+		c.Writer.Header().Set("Content-Type", obj.ContentType)
+	}
+
+	// Seekable bodies get range request support
+	if seeker, ok := obj.Body.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, "", obj.ModTime, seeker)
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, obj.Size, obj.ContentType, obj.Body, nil)
+}
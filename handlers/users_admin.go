@@ -9,10 +9,28 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
+// userList is how the admin user list can be sorted and filtered
+var userList = listing.Spec{
+	Key: "user_id",
+	Sorts: map[string]listing.Sort{
+		"created_at": {Column: "created_at", Type: "timestamptz"},
+		"last_name":  {Column: "last_name", Type: "text"},
+		"email":      {Column: "email", Type: "text"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"role":      {Column: "role", Kind: listing.Equals},
+		"is_active": {Column: "is_active", Kind: listing.BoolEquals},
+		"q":         {Column: "concat_ws(' ', first_name, last_name, email, student_id)", Kind: listing.Contains},
+	},
+	DefaultLimit: 50,
+}
+
 // AdminListUsers returns a paginated list of users with optional filters
 func AdminListUsers(c *gin.Context) {
 	role, _ := c.Get("role")
@@ -21,53 +39,25 @@ func AdminListUsers(c *gin.Context) {
 		return
 	}
 
-	q := strings.TrimSpace(c.Query("q"))
-	roleFilter := strings.TrimSpace(c.Query("role"))
-	isActiveStr := strings.TrimSpace(c.Query("is_active"))
-	limitStr := c.DefaultQuery("limit", "50")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 200 {
-		limit = 50
-	}
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	page := parsePage(c, &userList)
+	if page == nil {
+		return
 	}
 
-	where := []string{}
-	args := []interface{}{}
-	argIdx := 1
-
-	if q != "" {
-		where = append(where, "(LOWER(first_name) LIKE $"+strconv.Itoa(argIdx)+" OR LOWER(last_name) LIKE $"+strconv.Itoa(argIdx)+" OR LOWER(email) LIKE $"+strconv.Itoa(argIdx)+" OR LOWER(student_id) LIKE $"+strconv.Itoa(argIdx)+")")
-		args = append(args, "%"+strings.ToLower(q)+"%")
-		argIdx++
-	}
-	if roleFilter != "" {
-		where = append(where, "role = $"+strconv.Itoa(argIdx))
-		args = append(args, roleFilter)
-		argIdx++
-	}
-	if isActiveStr != "" {
-		isActive := strings.EqualFold(isActiveStr, "true") || isActiveStr == "1"
-		where = append(where, "is_active = $"+strconv.Itoa(argIdx))
-		args = append(args, isActive)
-		argIdx++
+	total, err := page.Count(database.DB, "users")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to list users")
+		return
 	}
 
-	query := `SELECT user_id, student_id, email, first_name, last_name, role, phone, profile_picture_url, is_active, created_at, updated_at
-	          FROM users`
-	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ")
-	}
-	query += " ORDER BY created_at DESC LIMIT $" + strconv.Itoa(argIdx) + " OFFSET $" + strconv.Itoa(argIdx+1)
-	args = append(args, limit, offset)
+	where, orderLimit, args := page.PageSQL()
+	query := `SELECT user_id, student_id, email, first_name, last_name, role, phone, profile_picture_url, is_active, email_verified, created_at, updated_at,
+	          ` + page.CursorColumns() + `
+	          FROM users` + where + orderLimit
 
 	rows, err := database.DB.Query(query, args...)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to list users")
+		utils.InternalServerErrorResponse(c, err, "Failed to list users")
 		return
 	}
 	defer rows.Close()
@@ -79,8 +69,13 @@ func AdminListUsers(c *gin.Context) {
 		var studentID, email, firstName, lastName, dbRole, phone, profileURL sql.NullString
 		var isActive sql.NullBool
 		var createdAt, updatedAt time.Time
-		if err := rows.Scan(&userID, &studentID, &email, &firstName, &lastName, &dbRole, &phone, &profileURL, &isActive, &createdAt, &updatedAt); err != nil {
-			continue
+		var cursor listing.Cursor
+		if err := rows.Scan(&userID, &studentID, &email, &firstName, &lastName, &dbRole, &phone, &profileURL, &isActive, &u.EmailVerified, &createdAt, &updatedAt, &cursor.Value, &cursor.Key); err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to list users")
+			return
+		}
+		if !page.Add(cursor) {
+			break
 		}
 		u.UserID = userID
 		u.StudentID = models.NullString(studentID)
@@ -100,7 +95,7 @@ func AdminListUsers(c *gin.Context) {
 		users = append(users, u)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Users retrieved", users)
+	utils.PageResponse(c, http.StatusOK, "Users retrieved", users, page.Meta(total))
 }
 
 // AdminGetUserByID returns details for a single user
@@ -120,14 +115,14 @@ func AdminGetUserByID(c *gin.Context) {
 	var studentID, email, firstName, lastName, dbRole, phone, profileURL sql.NullString
 	var isActive sql.NullBool
 	var createdAt, updatedAt time.Time
-	err = database.DB.QueryRow(`SELECT user_id, student_id, email, first_name, last_name, role, phone, profile_picture_url, is_active, created_at, updated_at
-		FROM users WHERE user_id = $1`, id).Scan(&userID, &studentID, &email, &firstName, &lastName, &dbRole, &phone, &profileURL, &isActive, &createdAt, &updatedAt)
+	err = database.DB.QueryRow(`SELECT user_id, student_id, email, first_name, last_name, role, phone, profile_picture_url, is_active, email_verified, created_at, updated_at
+		FROM users WHERE user_id = $1`, id).Scan(&userID, &studentID, &email, &firstName, &lastName, &dbRole, &phone, &profileURL, &isActive, &u.EmailVerified, &createdAt, &updatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.NotFoundResponse(c, "User not found")
 			return
 		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch user")
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user")
 		return
 	}
 	u.UserID = userID
@@ -174,7 +169,7 @@ func AdminUpdateUser(c *gin.Context) {
 
 	var req adminUpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -232,10 +227,18 @@ func AdminUpdateUser(c *gin.Context) {
 
 	_, err = database.DB.Exec(query, args...)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update user")
+		utils.AppErrorResponse(c, err, "Failed to update user")
 		return
 	}
 
+	// A deactivated user is signed out everywhere
+	if req.IsActive != nil && !*req.IsActive {
+		if err := revokeUserSessions(database.DB, id); err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to revoke user sessions")
+			return
+		}
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "User updated successfully", nil)
 }
 
@@ -254,7 +257,7 @@ func AdminDeleteUser(c *gin.Context) {
 
 	_, err = database.DB.Exec("DELETE FROM users WHERE user_id = $1", id)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete user")
+		utils.InternalServerErrorResponse(c, err, "Failed to delete user")
 		return
 	}
 
@@ -263,7 +266,7 @@ func AdminDeleteUser(c *gin.Context) {
 
 // changeRoleRequest is the payload for role update
 type changeRoleRequest struct {
-	Role string `json:"role" binding:"required"`
+	Role string `json:"role" binding:"required,oneof=student club_moderator system_admin"`
 }
 
 // AdminChangeUserRole changes a user's role
@@ -280,7 +283,7 @@ func AdminChangeUserRole(c *gin.Context) {
 	}
 	var req changeRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 	newRole := strings.TrimSpace(req.Role)
@@ -291,7 +294,7 @@ func AdminChangeUserRole(c *gin.Context) {
 
 	_, err = database.DB.Exec("UPDATE users SET role = $1, updated_at = $2 WHERE user_id = $3", newRole, time.Now(), id)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to change user role")
+		utils.AppErrorResponse(c, err, "Failed to change user role")
 		return
 	}
 
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/mailer"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// emailVerificationTTL is how long an email verification link stays valid
+const emailVerificationTTL = 48 * time.Hour
+
+// sendVerificationEmail replaces any outstanding verification tokens for the
+// user with a new one and emails the verification link
+func sendVerificationEmail(userID int, email, firstName string) error {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE email_verification_tokens SET used_at = CURRENT_TIMESTAMP
+		 WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO email_verification_tokens (user_id, token_hash, expires_at)
+		 VALUES ($1, $2, $3)`,
+		userID, utils.HashToken(token), time.Now().Add(emailVerificationTTL),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	verifyURL := strings.TrimRight(config.AppConfig.AppBaseURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+	return mailer.SendTemplate(email, "verify_email", gin.H{
+		"FirstName": firstName,
+		"VerifyURL": verifyURL,
+		"ExpiresIn": "48 hours",
+	})
+}
+
+// VerifyEmail marks the user's email address as verified using the token
+// from the verification email
+func VerifyEmail(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to verify email")
+		return
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRow(
+		`SELECT user_id FROM email_verification_tokens
+		 WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP AND used_at IS NULL
+		 FOR UPDATE`,
+		utils.HashToken(req.Token),
+	).Scan(&userID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.BadRequestResponse(c, "Invalid or expired verification token")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to validate token")
+		return
+	}
+
+	_, err = tx.Exec(
+		`UPDATE users
+		 SET email_verified = TRUE, email_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		 WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to verify email")
+		return
+	}
+
+	_, err = tx.Exec(
+		`UPDATE email_verification_tokens SET used_at = CURRENT_TIMESTAMP
+		 WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to mark token as used")
+		return
+	}
+
+	if err := logActivity(tx, userID, "email_verified", "user", userID, nil); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to verify email")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to verify email")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Email verified successfully", nil)
+}
+
+// ResendVerificationEmail sends a fresh verification link to the current user
+func ResendVerificationEmail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var email, firstName string
+	var verified bool
+	err := database.DB.QueryRow(
+		`SELECT email, first_name, email_verified FROM users WHERE user_id = $1`,
+		userID,
+	).Scan(&email, &firstName, &verified)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err, "Failed to fetch user")
+		return
+	}
+
+	if verified {
+		utils.BadRequestResponse(c, "Email address is already verified")
+		return
+	}
+
+	if err := sendVerificationEmail(userID.(int), email, firstName); err != nil {
+		utils.InternalServerErrorResponse(c, err, "Failed to send verification email")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification email sent", nil)
+}
@@ -0,0 +1,136 @@
+// Package ical writes events as iCalendar (RFC 5545) documents that calendar
+// apps can import or subscribe to. Each event keeps the same UID for its
+// lifetime and carries a SEQUENCE that grows with every change to its
+// schedule or details, so subscribed calendars update their copy in place.
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// ContentType is the media type of iCalendar documents
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	prodID    = "-//NUB Clubs Connect//Events//EN"
+	uidDomain = "nub-clubs-connect"
+	// maxLineOctets is the longest a content line may be before folding
+	maxLineOctets = 75
+	timeFormat    = "20060102T150405Z"
+)
+
+// Calendar is an iCalendar document of events
+type Calendar struct {
+	// Name is shown by calendar apps for subscribed feeds
+	Name   string
+	Events []models.Event
+	// EventURL returns the page of an event, if there is one
+	EventURL func(models.Event) string
+}
+
+// UID returns the unique identifier of an event, which never changes
+func UID(eventID int) string {
+	return "event-" + strconv.Itoa(eventID) + "@" + uidDomain
+}
+
+// Bytes renders the calendar
+func (cal *Calendar) Bytes() []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", prodID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME", text(cal.Name))
+	}
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.line("X-PUBLISHED-TTL", "PT1H")
+
+	for _, event := range cal.Events {
+		cal.writeEvent(w, event)
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+func (cal *Calendar) writeEvent(w *writer, event models.Event) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", UID(event.EventID))
+	w.line("DTSTAMP", timestamp(event.UpdatedAt))
+	w.line("SEQUENCE", strconv.Itoa(event.Sequence))
+	w.line("DTSTART", timestamp(event.StartDatetime))
+	w.line("DTEND", timestamp(event.EndDatetime))
+	w.line("SUMMARY", text(event.Title))
+	if event.Description != "" {
+		w.line("DESCRIPTION", text(event.Description))
+	}
+	if event.Location != "" {
+		w.line("LOCATION", text(event.Location))
+	}
+	if event.EventType != "" {
+		w.line("CATEGORIES", text(event.EventType))
+	}
+	if cal.EventURL != nil {
+		if url := cal.EventURL(event); url != "" {
+			w.line("URL", url)
+		}
+	}
+	w.line("STATUS", status(event.Status))
+	w.line("CREATED", timestamp(event.CreatedAt))
+	w.line("LAST-MODIFIED", timestamp(event.UpdatedAt))
+	w.line("END", "VEVENT")
+}
+
+// status maps an event status to the iCalendar event status
+func status(eventStatus string) string {
+	switch eventStatus {
+	case "cancelled":
+		return "CANCELLED"
+	case "pending":
+		return "TENTATIVE"
+	default:
+		return "CONFIRMED"
+	}
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// text escapes a TEXT property value
+func text(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writer writes content lines, folding them at 75 octets without splitting
+// UTF-8 sequences
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) line(name, value string) {
+	line := name + ":" + value
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut])
+		w.buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
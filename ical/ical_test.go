@@ -0,0 +1,173 @@
+package ical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func testEvent() models.Event {
+	created := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.FixedZone("BDT", 6*60*60))
+	return models.Event{
+		EventID:       42,
+		Title:         "Spring Hackathon, Day 1; Opening",
+		Description:   "Bring a laptop.\nTeams of 3\\4 are welcome, food is provided; see the rules page for the full schedule and the judging criteria.",
+		Location:      "Room 501, Main Campus",
+		EventType:     "competition",
+		Status:        "approved",
+		StartDatetime: start,
+		EndDatetime:   start.Add(8 * time.Hour),
+		CreatedAt:     created,
+		UpdatedAt:     created.Add(48 * time.Hour),
+		Sequence:      2,
+	}
+}
+
+func TestCalendarGolden(t *testing.T) {
+	cancelled := testEvent()
+	cancelled.EventID = 43
+	cancelled.Title = "Robotics Workshop — রোবটিক্স কর্মশালা"
+	cancelled.Description = ""
+	cancelled.Status = "cancelled"
+	cancelled.Sequence = 0
+
+	cal := &Calendar{
+		Name:   "NUB Programming Club",
+		Events: []models.Event{testEvent(), cancelled},
+		EventURL: func(event models.Event) string {
+			return "https://clubs.nub.ac.bd/events/" + strconv.Itoa(event.EventID)
+		},
+	}
+	got := cal.Bytes()
+
+	path := filepath.Join("testdata", "calendar.ics")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"a,b", `a\,b`},
+		{"a;b", `a\;b`},
+		{`a\b`, `a\\b`},
+		{`\,`, `\\\,`},
+		{"one\ntwo", `one\ntwo`},
+		{"one\r\ntwo", `one\ntwo`},
+		{"one\rtwo", `one\ntwo`},
+		{"colons: stay", "colons: stay"},
+	}
+
+	for _, tt := range tests {
+		if got := text(tt.in); got != tt.want {
+			t.Errorf("text(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "exactly 75 octets",
+			value: strings.Repeat("a", 70),
+			want:  "NAME:" + strings.Repeat("a", 70) + "\r\n",
+		},
+		{
+			name:  "one octet over",
+			value: strings.Repeat("a", 71),
+			want:  "NAME:" + strings.Repeat("a", 70) + "\r\n a\r\n",
+		},
+		{
+			name:  "continuation lines hold 74 octets",
+			value: strings.Repeat("a", 70+74+1),
+			want:  "NAME:" + strings.Repeat("a", 70) + "\r\n " + strings.Repeat("a", 74) + "\r\n a\r\n",
+		},
+		{
+			// "é" is two octets and would straddle the 75th
+			name:  "UTF-8 sequences aren't split",
+			value: strings.Repeat("a", 69) + "é",
+			want:  "NAME:" + strings.Repeat("a", 69) + "\r\n é\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		w := &writer{}
+		w.line("NAME", tt.value)
+		got := w.buf.String()
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+			if len(line) > maxLineOctets {
+				t.Errorf("%s: line of %d octets", tt.name, len(line))
+			}
+		}
+		if unfolded := strings.ReplaceAll(got, "\r\n ", ""); unfolded != "NAME:"+tt.value+"\r\n" {
+			t.Errorf("%s: unfolds to %q", tt.name, unfolded)
+		}
+	}
+}
+
+func TestUIDAndSequence(t *testing.T) {
+	if got := UID(42); got != "event-42@nub-clubs-connect" {
+		t.Errorf("got UID %s", got)
+	}
+
+	render := func(event models.Event) string {
+		return string((&Calendar{Events: []models.Event{event}}).Bytes())
+	}
+
+	// Rescheduling and renaming an event keeps its UID and raises its SEQUENCE
+	before := testEvent()
+	after := testEvent()
+	after.Title = "Spring Hackathon"
+	after.StartDatetime = after.StartDatetime.Add(24 * time.Hour)
+	after.EndDatetime = after.EndDatetime.Add(24 * time.Hour)
+	after.Sequence++
+
+	for _, tt := range []struct {
+		event    models.Event
+		sequence string
+	}{
+		{before, "SEQUENCE:2\r\n"},
+		{after, "SEQUENCE:3\r\n"},
+	} {
+		got := render(tt.event)
+		if !strings.Contains(got, "UID:event-42@nub-clubs-connect\r\n") {
+			t.Errorf("missing the event's UID\n%s", got)
+		}
+		if !strings.Contains(got, tt.sequence) {
+			t.Errorf("missing %q\n%s", tt.sequence, got)
+		}
+	}
+
+	if render(before) != render(testEvent()) {
+		t.Error("rendering the same event twice differs")
+	}
+}
@@ -0,0 +1,186 @@
+// Package imaging prepares uploaded images for publishing: it applies and
+// then strips EXIF metadata (including GPS coordinates) and produces smaller
+// renditions for thumbnails and in-page display.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// ErrInvalidImage is returned when the data can't be decoded as its type
+var ErrInvalidImage = errors.New("invalid image")
+
+// jpegQuality is used for re-encoded originals and JPEG renditions
+const jpegQuality = 85
+
+// maxPixels rejects images whose decoded size would exhaust memory
+const maxPixels = 50_000_000
+
+// Size is a rendition to generate, bounded by its longest edge
+type Size struct {
+	Name    string
+	MaxEdge int
+}
+
+// Sizes are the renditions generated for every image
+var Sizes = []Size{
+	{Name: "thumbnail", MaxEdge: 320},
+	{Name: "medium", MaxEdge: 1280},
+}
+
+// Image is an encoded image with its dimensions
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Result is a processed upload: the cleaned original and its renditions by
+// size name. Renditions are only made for sizes smaller than the original.
+type Result struct {
+	Original   Image
+	Renditions map[string]Image
+}
+
+// Process decodes an image of the given sniffed type, removes its metadata
+// and generates renditions
+func Process(data []byte, contentType string) (*Result, error) {
+	if err := checkDimensions(data, contentType); err != nil {
+		return nil, err
+	}
+
+	img, err := decode(data, contentType)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	original := Image{ContentType: contentType}
+	switch contentType {
+	case "image/jpeg":
+		// Re-encoding drops every APP segment, so bake the orientation in first
+		img = orient(img, jpegOrientation(data))
+		original.Data, err = encode(img, "image/jpeg")
+	case "image/png":
+		original.Data, err = encode(img, "image/png")
+	case "image/webp":
+		original.Data, err = stripWebPMetadata(data)
+	default:
+		// GIFs carry no EXIF, and re-encoding would lose animation
+		original.Data = data
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	original.Width, original.Height = bounds.Dx(), bounds.Dy()
+
+	result := &Result{Original: original, Renditions: map[string]Image{}}
+
+	renditionType := "image/jpeg"
+	if !opaque(img) {
+		renditionType = "image/png"
+	}
+	for _, size := range Sizes {
+		if max(original.Width, original.Height) <= size.MaxEdge {
+			continue
+		}
+		scaled := resize(img, size.MaxEdge)
+		encoded, err := encode(scaled, renditionType)
+		if err != nil {
+			return nil, err
+		}
+		result.Renditions[size.Name] = Image{
+			Data:        encoded,
+			ContentType: renditionType,
+			Width:       scaled.Bounds().Dx(),
+			Height:      scaled.Bounds().Dy(),
+		}
+	}
+
+	return result, nil
+}
+
+// checkDimensions reads the header to refuse decompression bombs before the
+// full image is decoded
+func checkDimensions(data []byte, contentType string) error {
+	var cfg image.Config
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		cfg, err = jpeg.DecodeConfig(bytes.NewReader(data))
+	case "image/png":
+		cfg, err = png.DecodeConfig(bytes.NewReader(data))
+	case "image/gif":
+		cfg, err = gif.DecodeConfig(bytes.NewReader(data))
+	case "image/webp":
+		cfg, err = webp.DecodeConfig(bytes.NewReader(data))
+	default:
+		return ErrInvalidImage
+	}
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return ErrInvalidImage
+	}
+	return nil
+}
+
+func decode(data []byte, contentType string) (image.Image, error) {
+	r := bytes.NewReader(data)
+	switch contentType {
+	case "image/jpeg":
+		return jpeg.Decode(r)
+	case "image/png":
+		return png.Decode(r)
+	case "image/gif":
+		return gif.Decode(r)
+	case "image/webp":
+		return webp.Decode(r)
+	}
+	return nil, ErrInvalidImage
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	return buf.Bytes(), err
+}
+
+// resize scales img so its longest edge is maxEdge, keeping the aspect ratio
+func resize(img image.Image, maxEdge int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w >= h {
+		h = max(1, h*maxEdge/w)
+		w = maxEdge
+	} else {
+		w = max(1, w*maxEdge/h)
+		h = maxEdge
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// opaque reports whether an image has no transparent pixels
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gpsText stands in for the coordinates in a GPS IFD; finding it in the output
+// means the EXIF block survived
+const gpsText = "GPS 23.8103N 90.4125E"
+
+func testImage(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{uint8(x), uint8(y), 128, alpha})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// exifJPEG encodes a JPEG with an APP1 EXIF block holding an orientation tag
+// and a pointer to GPS data
+func exifJPEG(t *testing.T, width, height, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(width, height, 255), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 2)
+	// Orientation, a SHORT stored in the first two bytes of the value
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = binary.BigEndian.AppendUint16(tiff, 0)
+	// GPS IFD pointer, to the data after the next-IFD offset
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x8825)
+	tiff = binary.BigEndian.AppendUint16(tiff, 4)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint32(tiff, uint32(len(tiff)+8))
+	tiff = binary.BigEndian.AppendUint32(tiff, 0)
+	tiff = append(tiff, gpsText...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte(nil), data[:2]...), app1...), data[2:]...)
+}
+
+// resizedPNG is a PNG whose header claims other dimensions than its pixels
+func resizedPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	data := encodePNG(t, testImage(1, 1, 255))
+	// The IHDR chunk follows the 8 byte signature: length, type, then width
+	// and height, with a CRC over the type and data
+	binary.BigEndian.PutUint32(data[16:], uint32(width))
+	binary.BigEndian.PutUint32(data[20:], uint32(height))
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestProcessStripsEXIF(t *testing.T) {
+	data := exifJPEG(t, 40, 20, 1)
+	if jpegOrientation(data) != 1 || !bytes.Contains(data, []byte(gpsText)) {
+		t.Fatal("the fixture has no EXIF block")
+	}
+
+	result, err := Process(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, marker := range []string{"Exif", gpsText} {
+		if bytes.Contains(result.Original.Data, []byte(marker)) {
+			t.Errorf("the output still contains %q", marker)
+		}
+	}
+}
+
+func TestProcessAppliesOrientation(t *testing.T) {
+	tests := []struct {
+		orientation   int
+		width, height int
+	}{
+		{1, 40, 20},
+		{3, 40, 20},
+		{6, 20, 40},
+		{8, 20, 40},
+		{9, 40, 20}, // out of range, ignored
+	}
+
+	for _, tt := range tests {
+		data := exifJPEG(t, 40, 20, tt.orientation)
+		result, err := Process(data, "image/jpeg")
+		if err != nil {
+			t.Fatalf("orientation %d: %v", tt.orientation, err)
+		}
+		if got := result.Original; got.Width != tt.width || got.Height != tt.height {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", tt.orientation, got.Width, got.Height, tt.width, tt.height)
+		}
+		if got := jpegOrientation(result.Original.Data); got != 1 {
+			t.Errorf("orientation %d: the output has orientation %d", tt.orientation, got)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image whose top-left pixel is marked
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	marked := color.NRGBA{255, 0, 0, 255}
+	src.Set(0, 0, marked)
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		x, y          int // where the marked pixel ends up
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+		b := got.Bounds()
+		if b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.width, tt.height)
+			continue
+		}
+		if c := color.NRGBAModel.Convert(got.At(tt.x, tt.y)); c != marked {
+			t.Errorf("orientation %d: pixel (%d, %d) is %v, want the marked one", tt.orientation, tt.x, tt.y, c)
+		}
+	}
+}
+
+func TestCheckDimensions(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantErr       bool
+	}{
+		{"small", 640, 400, false},
+		{"just under the limit", 7000, 7000, false},
+		{"exactly the limit", 10000, 5000, false},
+		{"over the limit", 10000, 5001, true},
+		{"a very long strip", 1_000_000, 100, true},
+		{"zero width", 0, 400, true},
+	}
+
+	for _, tt := range tests {
+		err := checkDimensions(resizedPNG(t, tt.width, tt.height), "image/png")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := Process(resizedPNG(t, 10000, 10000), "image/png"); err != ErrInvalidImage {
+		t.Errorf("a 100 MP image got error %v, want ErrInvalidImage", err)
+	}
+}
+
+func TestRenditions(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		alpha         uint8
+		contentType   string
+		want          map[string][2]int
+	}{
+		{
+			name:  "smaller than every size",
+			width: 300, height: 200, alpha: 255,
+			contentType: "image/jpeg",
+			want:        map[string][2]int{},
+		},
+		{
+			name:  "landscape",
+			width: 640, height: 400, alpha: 255,
+			contentType: "image/jpeg",
+			want:        map[string][2]int{"thumbnail": {320, 200}},
+		},
+		{
+			name:  "portrait",
+			width: 700, height: 1400, alpha: 255,
+			contentType: "image/jpeg",
+			want:        map[string][2]int{"thumbnail": {160, 320}, "medium": {640, 1280}},
+		},
+		{
+			name:  "transparent",
+			width: 640, height: 640, alpha: 100,
+			contentType: "image/png",
+			want:        map[string][2]int{"thumbnail": {320, 320}},
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := Process(encodePNG(t, testImage(tt.width, tt.height, tt.alpha)), "image/png")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(result.Renditions) != len(tt.want) {
+			t.Errorf("%s: got %d renditions, want %d", tt.name, len(result.Renditions), len(tt.want))
+		}
+		for name, size := range tt.want {
+			got, ok := result.Renditions[name]
+			if !ok {
+				t.Errorf("%s: no %s rendition", tt.name, name)
+				continue
+			}
+			if got.Width != size[0] || got.Height != size[1] || got.ContentType != tt.contentType {
+				t.Errorf("%s: %s is a %dx%d %s, want %dx%d %s", tt.name, name,
+					got.Width, got.Height, got.ContentType, size[0], size[1], tt.contentType)
+			}
+		}
+	}
+}
+
+// webpChunk encodes a RIFF chunk, padded to an even length
+func webpChunk(fourCC string, data []byte) []byte {
+	chunk := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	var body []byte
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	data = append(data, "WEBP"...)
+	return append(data, body...)
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	// VP8X announcing alpha, EXIF and XMP, with a 1x1 canvas
+	vp8x := webpChunk("VP8X", []byte{0x10 | vp8xMetadataFlags, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	pixels := webpChunk("VP8L", []byte{0x2f, 0, 0, 0, 0}) // odd length, padded
+	exif := webpChunk("EXIF", []byte("MM\x00\x2a"+gpsText))
+	xmp := webpChunk("XMP ", []byte("<x:xmpmeta/>"))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "metadata after the pixels",
+			data: webpFile(vp8x, pixels, exif, xmp),
+			want: webpFile(webpChunk("VP8X", []byte{0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0}), pixels),
+		},
+		{
+			name: "no metadata",
+			data: webpFile(pixels),
+			want: webpFile(pixels),
+		},
+		{
+			name:    "truncated chunk",
+			data:    webpFile(pixels, exif)[:40],
+			wantErr: true,
+		},
+		{
+			name:    "not a WebP file",
+			data:    encodePNG(t, testImage(1, 1, 255)),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := stripWebPMetadata(tt.data)
+		if tt.wantErr {
+			if err != ErrInvalidImage {
+				t.Errorf("%s: got error %v, want ErrInvalidImage", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: got\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// vp8xMetadataFlags are the VP8X header bits announcing EXIF and XMP chunks
+const vp8xMetadataFlags = 0x08 | 0x04
+
+// stripWebPMetadata removes the EXIF and XMP chunks from a WebP file without
+// re-encoding it, since the standard library has no WebP encoder
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidImage
+	}
+
+	var out bytes.Buffer
+	out.Write(data[0:12])
+
+	pos := 12
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // chunks are padded to an even length
+		if end > len(data) {
+			return nil, ErrInvalidImage
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			// Dropped
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			chunk[8] &^= vp8xMetadataFlags
+			out.Write(chunk)
+		default:
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:8], uint32(len(result)-8))
+	return result, nil
+}
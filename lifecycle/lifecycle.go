@@ -0,0 +1,133 @@
+// Package lifecycle is the state machine of event statuses. Every status
+// change goes through it, so only the allowed transitions happen and each
+// one is recorded in event_status_history.
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+)
+
+// Event statuses
+const (
+	Pending   = "pending"
+	Approved  = "approved"
+	Rejected  = "rejected"
+	Completed = "completed"
+	Cancelled = "cancelled"
+)
+
+// transitions lists the statuses each status may change to. Approved events
+// go back to review when they are significantly edited, and rejected events
+// when they are revised. Completed and cancelled events are final.
+var transitions = map[string][]string{
+	Pending:   {Approved, Rejected, Cancelled},
+	Approved:  {Pending, Completed, Cancelled},
+	Rejected:  {Pending},
+	Completed: {},
+	Cancelled: {},
+}
+
+// CanTransition reports whether an event may change from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Editable reports whether an event's details may still be changed
+func Editable(status string) bool {
+	return status == Pending || status == Approved || status == Rejected
+}
+
+// TakesAttendance reports whether attendance may be marked for an event:
+// while it is approved, and afterwards for the record once it is completed
+func TakesAttendance(status string) bool {
+	return status == Approved || status == Completed
+}
+
+// TransitionError is returned for a status change the state machine forbids
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("an event can't go from %s to %s", e.From, e.To)
+}
+
+// Transition moves an event to a new status, locking its row, and records the
+// change. changedBy is nil for changes made by the system. It returns the
+// previous status, sql.ErrNoRows when the event doesn't exist and a
+// *TransitionError when the change isn't allowed.
+func Transition(tx database.Querier, eventID int, to string, changedBy *int, reason string) (string, error) {
+	var from string
+	err := tx.QueryRow(`SELECT status FROM events WHERE event_id = $1 FOR UPDATE`, eventID).Scan(&from)
+	if err != nil {
+		return "", err
+	}
+	if !CanTransition(from, to) {
+		return from, &TransitionError{From: from, To: to}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE events SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE event_id = $2`,
+		to, eventID,
+	)
+	if err != nil {
+		return from, err
+	}
+
+	return from, Record(tx, eventID, &from, to, changedBy, reason)
+}
+
+// Record adds an entry to an event's status history. from is nil when the
+// event is created.
+func Record(exec database.Execer, eventID int, from *string, to string, changedBy *int, reason string) error {
+	_, err := exec.Exec(
+		`INSERT INTO event_status_history (event_id, from_status, to_status, changed_by, reason)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		eventID, from, to, changedBy, reason,
+	)
+	return err
+}
+
+// CompleteFinished moves every approved event that has ended to completed,
+// recording each change as made by the system. Events locked by another
+// transaction are left for the next run. It returns how many were completed.
+func CompleteFinished(tx database.Querier) (int, error) {
+	rows, err := tx.Query(
+		`SELECT event_id FROM events
+		 WHERE status = $1 AND end_datetime < CURRENT_TIMESTAMP
+		 ORDER BY event_id
+		 FOR UPDATE SKIP LOCKED`,
+		Approved,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var eventIDs []int
+	for rows.Next() {
+		var eventID int
+		if err := rows.Scan(&eventID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		eventIDs = append(eventIDs, eventID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, eventID := range eventIDs {
+		if _, err := Transition(tx, eventID, Completed, nil, "Event ended"); err != nil {
+			return 0, err
+		}
+	}
+	return len(eventIDs), nil
+}
@@ -0,0 +1,53 @@
+package lifecycle
+
+import "testing"
+
+var statuses = []string{Pending, Approved, Rejected, Completed, Cancelled}
+
+func TestCanTransition(t *testing.T) {
+	// Every allowed change; any other pair, including staying put, is refused
+	allowed := map[[2]string]bool{
+		{Pending, Approved}:   true,
+		{Pending, Rejected}:   true,
+		{Pending, Cancelled}:  true,
+		{Approved, Pending}:   true,
+		{Approved, Completed}: true,
+		{Approved, Cancelled}: true,
+		{Rejected, Pending}:   true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range append(statuses, "archived", "") {
+			want := allowed[[2]string{from, to}]
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%q, %q) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+	if CanTransition("archived", Pending) {
+		t.Error("an unknown status can change")
+	}
+}
+
+func TestStatusChecks(t *testing.T) {
+	tests := []struct {
+		status          string
+		editable        bool
+		takesAttendance bool
+	}{
+		{Pending, true, false},
+		{Approved, true, true},
+		{Rejected, true, false},
+		{Completed, false, true},
+		{Cancelled, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := Editable(tt.status); got != tt.editable {
+			t.Errorf("Editable(%q) = %v, want %v", tt.status, got, tt.editable)
+		}
+		if got := TakesAttendance(tt.status); got != tt.takesAttendance {
+			t.Errorf("TakesAttendance(%q) = %v, want %v", tt.status, got, tt.takesAttendance)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package listing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+)
+
+var errBadCursor = errors.New("invalid cursor")
+
+// cursor is the decoded form of the opaque cursor handed to clients
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Key   string `json:"k"`
+}
+
+// encodeCursor serializes a cursor and signs it, so that the values bound
+// into the next page's query are the ones this server produced
+func encodeCursor(c *cursor) string {
+	payload, _ := json.Marshal(c)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + cursorSignature(encoded)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	encoded, signature, ok := strings.Cut(s, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(cursorSignature(encoded))) {
+		return nil, errBadCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errBadCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, errBadCursor
+	}
+	if _, err := strconv.ParseInt(c.Key, 10, 64); err != nil {
+		return nil, errBadCursor
+	}
+	return &c, nil
+}
+
+func cursorSignature(encoded string) string {
+	mac := hmac.New(sha256.New, []byte("listing-cursor:"+config.AppConfig.JWTSecret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
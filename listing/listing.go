@@ -0,0 +1,317 @@
+// Package listing pages, filters and sorts list endpoints. Pages are
+// fetched by keyset: an opaque cursor carries the sort value and key of the
+// last row returned, so pages stay stable while rows are being added.
+package listing
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Sort is an order a list can be sorted by
+type Sort struct {
+	// Column is the SQL expression sorted on. It must never be NULL, so
+	// nullable columns should be wrapped in COALESCE.
+	Column string
+	// Type is the Postgres type of Column, used to cast cursor values
+	Type string
+}
+
+// FilterKind is how a filter's query parameter is matched against its column
+type FilterKind int
+
+const (
+	// Equals matches text exactly
+	Equals FilterKind = iota
+	// IntEquals matches an integer
+	IntEquals
+	// BoolEquals matches true/false
+	BoolEquals
+	// Contains matches a case-insensitive substring
+	Contains
+	// From matches timestamps at or after an RFC 3339 time or a date
+	From
+	// Until matches timestamps at or before an RFC 3339 time, or before the
+	// end of a date
+	Until
+)
+
+// Filter is a query parameter that narrows a list
+type Filter struct {
+	Column string
+	Kind   FilterKind
+}
+
+// Spec describes what a list endpoint can be sorted and filtered by
+type Spec struct {
+	// Key is a unique column that breaks ties between rows with the same
+	// sort value. It must be an integer.
+	Key string
+	// Sorts are the accepted sort orders by name
+	Sorts map[string]Sort
+	// DefaultSort is the sort name used when none is requested, prefixed
+	// with "-" for descending order
+	DefaultSort string
+	// Filters are matched against query parameters of the same name
+	Filters map[string]Filter
+	// DefaultLimit is the page size when none is requested; zero uses 20
+	DefaultLimit int
+}
+
+// Error is a problem with the list parameters of a request. Its message is
+// safe to show to clients.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func invalid(format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+// Page is the requested page of a list. Handlers add their own conditions
+// with Where, then build the query from FilterSQL and PageSQL.
+type Page struct {
+	Limit int
+
+	spec    *Spec
+	sort    string
+	order   Sort
+	desc    bool
+	after   *cursor
+	conds   []string
+	args    []interface{}
+	rows    int
+	last    Cursor
+	hasMore bool
+}
+
+// Parse reads the limit, cursor, sort and filters of a list request
+func Parse(c *gin.Context, spec *Spec) (*Page, error) {
+	var params models.PaginationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		return nil, invalid("Invalid pagination parameters")
+	}
+
+	p := &Page{spec: spec, Limit: spec.DefaultLimit}
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if params.Limit < 0 || params.Limit > maxLimit {
+		return nil, invalid("limit must be between 1 and %d", maxLimit)
+	}
+	if params.Limit > 0 {
+		p.Limit = params.Limit
+	}
+
+	p.sort = params.Sort
+	if p.sort == "" {
+		p.sort = spec.DefaultSort
+	}
+	name := strings.TrimPrefix(p.sort, "-")
+	order, ok := spec.Sorts[name]
+	if !ok {
+		return nil, invalid("sort must be one of: %s", strings.Join(spec.sortNames(), ", "))
+	}
+	p.order = order
+	p.desc = strings.HasPrefix(p.sort, "-")
+
+	if params.Cursor != "" {
+		after, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return nil, invalid("Invalid cursor")
+		}
+		if after.Sort != p.sort {
+			return nil, invalid("The cursor belongs to a different sort order")
+		}
+		p.after = after
+	}
+
+	for _, param := range spec.filterNames() {
+		value := strings.TrimSpace(c.Query(param))
+		if value == "" {
+			continue
+		}
+		if err := p.addFilter(param, spec.Filters[param], value); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *Page) addFilter(param string, f Filter, value string) error {
+	switch f.Kind {
+	case Equals:
+		p.Where(f.Column + " = " + p.Arg(value))
+	case IntEquals:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return invalid("%s must be a number", param)
+		}
+		p.Where(f.Column + " = " + p.Arg(n))
+	case BoolEquals:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("%s must be true or false", param)
+		}
+		p.Where(f.Column + " = " + p.Arg(b))
+	case Contains:
+		p.Where(f.Column + " ILIKE " + p.Arg("%"+escapeLike(value)+"%"))
+	case From, Until:
+		t, dateOnly, err := parseTime(value)
+		if err != nil {
+			return invalid("%s must be a date (YYYY-MM-DD) or an RFC 3339 time", param)
+		}
+		if f.Kind == From {
+			p.Where(f.Column + " >= " + p.Arg(t))
+		} else if dateOnly {
+			p.Where(f.Column + " < " + p.Arg(t.AddDate(0, 0, 1)))
+		} else {
+			p.Where(f.Column + " <= " + p.Arg(t))
+		}
+	}
+	return nil
+}
+
+// Arg adds a query argument and returns its placeholder. Every argument must
+// be referenced by the query it is sent with, so arguments used only in the
+// select list must be added after Count.
+func (p *Page) Arg(value interface{}) string {
+	p.args = append(p.args, value)
+	return "$" + strconv.Itoa(len(p.args))
+}
+
+// Where adds a condition that every row of the list must meet
+func (p *Page) Where(condition string) {
+	p.conds = append(p.conds, condition)
+}
+
+// FilterSQL returns the WHERE clause and arguments selecting every row of the
+// list, for counting them
+func (p *Page) FilterSQL() (string, []interface{}) {
+	return whereClause(p.conds), append([]interface{}(nil), p.args...)
+}
+
+// PageSQL returns the WHERE clause selecting the rows after the cursor, the
+// ORDER BY and LIMIT clauses, and their arguments. One row more than the
+// limit is fetched to tell whether another page follows.
+func (p *Page) PageSQL() (where, orderLimit string, args []interface{}) {
+	conds := append([]string(nil), p.conds...)
+	args = append([]interface{}(nil), p.args...)
+
+	direction, comparison := "ASC", ">"
+	if p.desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if p.after != nil {
+		args = append(args, p.after.Value, p.after.Key)
+		conds = append(conds, fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d::bigint)",
+			p.order.Column, p.spec.Key, comparison, len(args)-1, p.order.Type, len(args)))
+	}
+
+	args = append(args, p.Limit+1)
+	orderLimit = fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT $%d",
+		p.order.Column, direction, p.spec.Key, direction, len(args))
+
+	return whereClause(conds), orderLimit, args
+}
+
+// CursorColumns returns select expressions for the sort value and key of each
+// row, which are scanned into a Cursor
+func (p *Page) CursorColumns() string {
+	return fmt.Sprintf("(%s)::text, (%s)::text", p.order.Column, p.spec.Key)
+}
+
+// Cursor is the position of a row in a list, scanned from CursorColumns
+type Cursor struct {
+	Value string
+	Key   string
+}
+
+// Add records a scanned row's position. It returns false for the extra row
+// fetched past the limit, which belongs to the next page.
+func (p *Page) Add(row Cursor) bool {
+	if p.rows >= p.Limit {
+		p.hasMore = true
+		return false
+	}
+	p.rows++
+	p.last = row
+	return true
+}
+
+// Count returns how many rows the list has in total. from is the FROM clause,
+// including any joins the conditions refer to.
+func (p *Page) Count(db database.Querier, from string) (int, error) {
+	where, args := p.FilterSQL()
+	var total int
+	err := db.QueryRow("SELECT COUNT(*) FROM "+from+where, args...).Scan(&total)
+	return total, err
+}
+
+// Meta describes the page for the response
+func (p *Page) Meta(total int) *models.PageMeta {
+	meta := &models.PageMeta{Limit: p.Limit, Total: total, Sort: p.sort}
+	if p.hasMore {
+		meta.NextCursor = encodeCursor(&cursor{Sort: p.sort, Value: p.last.Value, Key: p.last.Key})
+	}
+	return meta
+}
+
+func (s *Spec) sortNames() []string {
+	names := make([]string, 0, len(s.Sorts))
+	for name := range s.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterNames returns the filter parameters in a fixed order, so argument
+// numbering is the same on every request
+func (s *Spec) filterNames() []string {
+	names := make([]string, 0, len(s.Filters))
+	for name := range s.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// escapeLike escapes the LIKE wildcards in a search term
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// parseTime parses an RFC 3339 time or a date, reporting whether it was a date
+func parseTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	return t, true, err
+}
@@ -0,0 +1,62 @@
+// Package logging configures the application's structured logger. Init
+// installs it as slog's default, so slog calls and the standard log package
+// both go through it; handlers log with From, which tags each entry with the
+// request it belongs to.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+)
+
+// loggerKey is where a request's logger is kept in its gin context
+const loggerKey = "logger"
+
+// Init configures the default logger from LOG_LEVEL and LOG_FORMAT
+func Init() error {
+	logger, err := New(os.Stdout, config.AppConfig.LogLevel, config.AppConfig.LogFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// New creates a logger writing entries at or above a level (debug, info,
+// warn or error) as text or json
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown LOG_LEVEL %q (expected debug, info, warn or error)", level)
+	}
+
+	options := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("unknown LOG_FORMAT %q (expected text or json)", format)
+}
+
+// Bind makes logger the one From returns for a request
+func Bind(c *gin.Context, logger *slog.Logger) {
+	c.Set(loggerKey, logger)
+}
+
+// From returns the logger for a request, or the default logger outside one
+func From(c *gin.Context) *slog.Logger {
+	if value, ok := c.Get(loggerKey); ok {
+		if logger, ok := value.(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		format  string
+		wantErr bool
+		json    bool
+		debug   bool // whether debug entries are written
+	}{
+		{name: "text at info", level: "info", format: "text"},
+		{name: "json at debug", level: "debug", format: "json", json: true, debug: true},
+		{name: "upper case names", level: "WARN", format: "JSON", json: true},
+		{name: "unknown level", level: "verbose", format: "text", wantErr: true},
+		{name: "unknown format", level: "info", format: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, tt.level, tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger.Debug("Debug entry")
+			if got := strings.Contains(buf.String(), "Debug entry"); got != tt.debug {
+				t.Errorf("debug entry written: %v, want %v", got, tt.debug)
+			}
+
+			buf.Reset()
+			logger.Error("Failed to do something", "error", "boom")
+			if tt.json {
+				var entry map[string]interface{}
+				if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+					t.Fatalf("entry isn't JSON: %v", err)
+				}
+				if entry["msg"] != "Failed to do something" || entry["error"] != "boom" {
+					t.Errorf("got %v", entry)
+				}
+				return
+			}
+			if !strings.Contains(buf.String(), `msg="Failed to do something" error=boom`) {
+				t.Errorf("got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if From(c) != slog.Default() {
+		t.Error("expected the default logger before one is bound")
+	}
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	Bind(c, logger)
+	if From(c) != logger {
+		t.Error("expected the bound logger")
+	}
+}
@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to a writer instead of delivering them. It is
+// meant for local development and tests, where reset and verification links
+// can be copied straight out of the log.
+type LogMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+// NewLogMailer creates a mailer that appends every message to w
+func NewLogMailer(w io.Writer, from string) *LogMailer {
+	return &LogMailer{w: w, from: from}
+}
+
+// Send writes the message to the underlying writer
+func (m *LogMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "----- email %s -----\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "From: %s\nTo: %s\nSubject: %s\n\n", m.from, msg.To, msg.Subject)
+	b.WriteString(strings.TrimRight(msg.TextBody, "\n"))
+	b.WriteString("\n----- end email -----\n")
+
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
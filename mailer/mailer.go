@@ -0,0 +1,74 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+)
+
+// Message is a single outgoing email with plain-text and HTML bodies
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer delivers email messages
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default is the mailer used by the application, configured by Init
+var Default Mailer
+
+// Init configures the default mailer from the application config
+func Init() error {
+	cfg := config.AppConfig
+
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return fmt.Errorf("SMTP_HOST must be set when MAIL_DRIVER is smtp")
+		}
+		Default = &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}
+
+	case "log":
+		if cfg.MailLogFile == "" {
+			Default = NewLogMailer(os.Stdout, cfg.MailFrom)
+			break
+		}
+		file, err := os.OpenFile(cfg.MailLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open mail log file: %w", err)
+		}
+		Default = NewLogMailer(file, cfg.MailFrom)
+
+	default:
+		return fmt.Errorf("unknown MAIL_DRIVER %q (expected smtp or log)", cfg.MailDriver)
+	}
+
+	return nil
+}
+
+// SendTemplate renders the named template with data and sends it with the default mailer
+func SendTemplate(to, name string, data interface{}) error {
+	if Default == nil {
+		return fmt.Errorf("mailer is not initialized")
+	}
+
+	msg, err := Render(name, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+
+	return Default.Send(msg)
+}
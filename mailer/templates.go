@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// Render builds a message from the named template. Each email has a
+// <name>.txt.tmpl file defining a "subject" block followed by the plain-text
+// body, and an optional <name>.html.tmpl defining "content" that is wrapped
+// in the shared HTML layout.
+func Render(name string, data interface{}) (Message, error) {
+	textTmpl, err := texttemplate.ParseFS(templateFiles, "templates/"+name+".txt.tmpl")
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to load email template %q: %w", name, err)
+	}
+
+	var subject, text bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render subject for %q: %w", name, err)
+	}
+	if err := textTmpl.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render email %q: %w", name, err)
+	}
+
+	msg := Message{
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: strings.TrimSpace(text.String()) + "\n",
+	}
+
+	htmlPath := "templates/" + name + ".html.tmpl"
+	if _, err := templateFiles.Open(htmlPath); err != nil {
+		return msg, nil
+	}
+
+	htmlTmpl, err := htmltemplate.ParseFS(templateFiles, "templates/layout.html.tmpl", htmlPath)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to load email template %q: %w", name, err)
+	}
+
+	var html bytes.Buffer
+	layoutData := struct {
+		Subject string
+		Data    interface{}
+	}{msg.Subject, data}
+	if err := htmlTmpl.ExecuteTemplate(&html, "layout", layoutData); err != nil {
+		return Message{}, fmt.Errorf("failed to render email %q: %w", name, err)
+	}
+	msg.HTMLBody = html.String()
+
+	return msg, nil
+}
@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nub-clubs-connect/nub_admin_api/config"
 	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/handlers"
+	"github.com/nub-clubs-connect/nub_admin_api/logging"
+	"github.com/nub-clubs-connect/nub_admin_api/mailer"
+	"github.com/nub-clubs-connect/nub_admin_api/realtime"
 	"github.com/nub-clubs-connect/nub_admin_api/routes"
+	"github.com/nub-clubs-connect/nub_admin_api/scheduler"
+	"github.com/nub-clubs-connect/nub_admin_api/storage"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
+	"github.com/nub-clubs-connect/nub_admin_api/validation"
 )
 
 func main() {
@@ -16,27 +28,118 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Configure structured logging; the standard logger writes through it too
+	if err := logging.Init(); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+
+	// Configure outgoing email
+	if err := mailer.Init(); err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+
+	// Configure file storage for uploads
+	if err := storage.Init(); err != nil {
+		log.Fatalf("Failed to configure storage: %v", err)
+	}
+
 	// Initialize database
 	if err := database.Init(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer database.Close()
 
+	// Give the handlers their data access
+	handlers.UseStores(store.New(database.DB))
+
+	// Configure live update delivery
+	if err := realtime.Init(); err != nil {
+		log.Fatalf("Failed to configure realtime updates: %v", err)
+	}
+
+	// Register background jobs, and run them unless this instance opts out
+	scheduler.RegisterDefaultJobs(scheduler.Default)
+	if config.AppConfig.SchedulerEnabled {
+		scheduler.Default.Start(context.Background())
+	}
+
 	// Set Gin mode
 	gin.SetMode(config.AppConfig.GinMode)
 
-	// Create Gin router
-	router := gin.Default()
+	// Add the request body validation rules
+	if err := validation.Register(); err != nil {
+		log.Fatalf("Failed to register validation rules: %v", err)
+	}
+
+	// Create Gin router; request logging and panic recovery are added with
+	// the routes
+	router := gin.New()
 
 	// Setup routes
 	routes.SetupRoutes(router)
 
 	// Start server
 	port := config.AppConfig.Port
-	fmt.Printf("🚀 Server starting on port %s\n", port)
-	fmt.Println("📚 API Documentation: http://localhost:" + port + "/api/docs")
+	slog.Info("Server starting", "port", port, "docs", "http://localhost:"+port+"/api/docs")
 
 	if err := router.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// runMigrateCommand handles `migrate up|down [steps]|status`
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: migrate up | down [steps] | status")
+	}
+
+	if err := database.Connect(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp()
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid number of steps: %s", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := database.MigrateDown(steps)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", rolledBack)
+
+	case "status":
+		statuses, err := database.GetMigrationStatus()
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		log.Fatalf("Unknown migrate command %q (expected up, down or status)", args[0])
+	}
+}
@@ -1,12 +1,21 @@
 package middleware
 
 import (
+	"database/sql"
+	"errors"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
 	"github.com/nub-clubs-connect/nub_admin_api/utils"
 )
 
+var (
+	errSessionRevoked = errors.New("session is no longer valid")
+	errStaleToken     = errors.New("token is stale")
+)
+
 // AuthMiddleware validates JWT token
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -33,15 +42,114 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		emailVerified, err := validateSession(claims)
+		if err != nil {
+			switch err {
+			case errSessionRevoked:
+				utils.UnauthorizedResponse(c, "Session has been revoked")
+			case errStaleToken:
+				utils.UnauthorizedResponse(c, "Token is out of date, please refresh")
+			default:
+				utils.InternalServerErrorResponse(c, err, "Failed to validate session")
+			}
+			c.Abort()
+			return
+		}
+
 		// Store user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
+		setUserContext(c, claims, emailVerified)
+
+		c.Next()
+	}
+}
+
+// validateSession checks that the token's session is still active and that
+// the user's role and active flag haven't changed since the token was issued.
+// It also reports whether the user's email address has been verified.
+func validateSession(claims *utils.Claims) (bool, error) {
+	var role string
+	var isActive, emailVerified, sessionValid bool
+
+	err := database.DB.QueryRow(
+		`SELECT u.role, u.is_active, u.email_verified, s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP
+		 FROM sessions s
+		 JOIN users u ON s.user_id = u.user_id
+		 WHERE s.session_id = $1 AND s.user_id = $2`,
+		claims.SessionID, claims.UserID,
+	).Scan(&role, &isActive, &emailVerified, &sessionValid)
+
+	if err == sql.ErrNoRows {
+		return false, errSessionRevoked
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !sessionValid || !isActive {
+		return false, errSessionRevoked
+	}
+	if role != claims.Role {
+		return false, errStaleToken
+	}
+
+	return emailVerified, nil
+}
+
+// RevalidateSession checks again that the token a request was authenticated
+// with is still valid and its session hasn't been revoked. Long-lived
+// requests such as event streams call it periodically, since the middleware
+// only checks once when the request starts.
+func RevalidateSession(c *gin.Context) error {
+	claims, err := utils.ValidateToken(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+	if err != nil {
+		return err
+	}
+	_, err = validateSession(claims)
+	return err
+}
+
+func setUserContext(c *gin.Context, claims *utils.Claims, emailVerified bool) {
+	c.Set("user_id", claims.UserID)
+	c.Set("email", claims.Email)
+	c.Set("role", claims.Role)
+	c.Set("session_id", claims.SessionID)
+	c.Set("email_verified", emailVerified)
+}
+
+// VerifiedEmailMiddleware restricts users with an unverified email address to
+// read-only requests. It must run after AuthMiddleware.
+func VerifiedEmailMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if !c.GetBool("email_verified") {
+			utils.ErrorResponse(c, http.StatusForbidden, "Please verify your email address before making changes", "email_not_verified")
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
 
+// QueryTokenMiddleware accepts the access token from the access_token query
+// parameter for clients that cannot set headers, such as a browser
+// EventSource. It must run before AuthMiddleware.
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
+
 // RoleMiddleware checks if user has required role
 func RoleMiddleware(requiredRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -94,10 +202,15 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Revoked or stale tokens are treated as anonymous
+		emailVerified, err := validateSession(claims)
+		if err != nil {
+			c.Next()
+			return
+		}
+
 		// Store user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
+		setUserContext(c, claims, emailVerified)
 
 		c.Next()
 	}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// ClubResolver determines which club a request acts on
+type ClubResolver func(c *gin.Context) (int, error)
+
+// clubResolveError is returned by resolvers when the request itself is at fault
+type clubResolveError struct {
+	status  int
+	message string
+}
+
+func (e *clubResolveError) Error() string {
+	return e.message
+}
+
+// ClubFromParam resolves the club ID directly from a route parameter
+func ClubFromParam(param string) ClubResolver {
+	return func(c *gin.Context) (int, error) {
+		clubID, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			return 0, &clubResolveError{http.StatusBadRequest, "Invalid club ID"}
+		}
+		return lookupClub(`SELECT club_id FROM clubs WHERE club_id = $1`, clubID, "Club not found")
+	}
+}
+
+// ClubFromEventParam resolves the club that owns the event in a route parameter
+func ClubFromEventParam(param string) ClubResolver {
+	return func(c *gin.Context) (int, error) {
+		eventID, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			return 0, &clubResolveError{http.StatusBadRequest, "Invalid event ID"}
+		}
+		return lookupClub(`SELECT club_id FROM events WHERE event_id = $1`, eventID, "Event not found")
+	}
+}
+
+// ClubFromNewsParam resolves the club that owns the news post in a route parameter
+func ClubFromNewsParam(param string) ClubResolver {
+	return func(c *gin.Context) (int, error) {
+		newsID, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			return 0, &clubResolveError{http.StatusBadRequest, "Invalid news ID"}
+		}
+		return lookupClub(`SELECT club_id FROM news WHERE news_id = $1`, newsID, "News post not found")
+	}
+}
+
+// ClubFromJSONBody resolves the club from a numeric field of the JSON body.
+// The body is restored afterwards so the handler can bind it again.
+func ClubFromJSONBody(field string) ClubResolver {
+	return func(c *gin.Context) (int, error) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return 0, &clubResolveError{http.StatusBadRequest, "Invalid request body"}
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return 0, &clubResolveError{http.StatusBadRequest, "Invalid request body"}
+		}
+
+		var clubID int
+		if raw, ok := payload[field]; !ok || json.Unmarshal(raw, &clubID) != nil || clubID <= 0 {
+			return 0, &clubResolveError{http.StatusBadRequest, "A valid " + field + " is required"}
+		}
+
+		return lookupClub(`SELECT club_id FROM clubs WHERE club_id = $1`, clubID, "Club not found")
+	}
+}
+
+func lookupClub(query string, id int, notFoundMessage string) (int, error) {
+	var clubID int
+	err := database.DB.QueryRow(query, id).Scan(&clubID)
+	if err == sql.ErrNoRows {
+		return 0, &clubResolveError{http.StatusNotFound, notFoundMessage}
+	}
+	return clubID, err
+}
+
+// ClubModeratorMiddleware allows system admins and moderators of the club
+// resolved from the request. The resolved club is stored as "club_id".
+func ClubModeratorMiddleware(resolve ClubResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clubID, err := resolve(c)
+		if err != nil {
+			var resolveErr *clubResolveError
+			switch {
+			case errors.As(err, &resolveErr) && resolveErr.status == http.StatusNotFound:
+				utils.NotFoundResponse(c, resolveErr.message)
+			case errors.As(err, &resolveErr):
+				utils.BadRequestResponse(c, resolveErr.message)
+			default:
+				utils.InternalServerErrorResponse(c, err, "Failed to verify club permissions")
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("club_id", clubID)
+
+		allowed, err := CanModerateClub(c, clubID)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err, "Failed to verify club permissions")
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			utils.ForbiddenResponse(c, "You are not a moderator of this club")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CanModerateClub reports whether the authenticated user is a system admin
+// or a moderator of the given club
+func CanModerateClub(c *gin.Context, clubID int) (bool, error) {
+	if role, _ := c.Get("role"); role == "system_admin" {
+		return true, nil
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return false, nil
+	}
+
+	return IsClubModerator(userID.(int), clubID)
+}
+
+// IsClubModerator reports whether the user is assigned as a moderator of the club
+func IsClubModerator(userID, clubID int) (bool, error) {
+	var isModerator bool
+	err := database.DB.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM club_moderators WHERE user_id = $1 AND club_id = $2)`,
+		userID, clubID,
+	).Scan(&isModerator)
+
+	return isModerator, err
+}
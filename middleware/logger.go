@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/logging"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// LoggerMiddleware gives each request a logger tagged with its ID, for
+// handlers to use through logging.From, and logs the request once it has been
+// handled. It must run after RequestIDMiddleware.
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		logger := slog.Default().With("request_id", c.GetString("request_id"))
+		logging.Bind(c, logger)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		attrs := []interface{}{
+			"method", c.Request.Method,
+			"route", route,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"ip", c.ClientIP(),
+		}
+		// Set by the auth middleware on authenticated routes
+		if userID, ok := c.Get("user_id"); ok {
+			attrs = append(attrs, "user_id", userID, "role", c.GetString("role"))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.Log(c.Request.Context(), level, "Request", attrs...)
+	}
+}
+
+// RecoveryMiddleware turns a panic in a handler into a 500 response, logging
+// the panic and its stack with the request's logger
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		logging.From(c).Error("Panic while handling request", "panic", recovered, "stack", string(debug.Stack()))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Internal server error", "internal_server_error")
+		c.Abort()
+	})
+}
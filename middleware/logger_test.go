@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// captureLogs sends the default logger's entries to a buffer as JSON for the
+// rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// entries decodes the logged entries
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var logged []map[string]interface{}
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var entry map[string]interface{}
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		logged = append(logged, entry)
+	}
+	return logged
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		level  string
+		route  string
+		// extra is an entry logged by the handler before the request entry
+		extra string
+	}{
+		{name: "success", path: "/ok", status: http.StatusOK, level: "INFO", route: "/ok"},
+		{name: "client error", path: "/items/x", status: http.StatusBadRequest, level: "WARN", route: "/items/:id"},
+		{name: "unmatched route", path: "/nowhere", status: http.StatusNotFound, level: "WARN", route: "unmatched"},
+		{name: "server error", path: "/fail", status: http.StatusInternalServerError, level: "ERROR", route: "/fail",
+			extra: "Failed to load items"},
+		{name: "panic", path: "/panic", status: http.StatusInternalServerError, level: "ERROR", route: "/panic",
+			extra: "Panic while handling request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t)
+
+			router := gin.New()
+			router.Use(RequestIDMiddleware(), LoggerMiddleware(), RecoveryMiddleware())
+			router.GET("/ok", func(c *gin.Context) {
+				utils.SuccessResponse(c, http.StatusOK, "OK", nil)
+			})
+			router.GET("/items/:id", func(c *gin.Context) {
+				utils.BadRequestResponse(c, "Invalid item ID")
+			})
+			router.GET("/fail", func(c *gin.Context) {
+				utils.InternalServerErrorResponse(c, errors.New("connection refused"), "Failed to load items")
+			})
+			router.GET("/panic", func(c *gin.Context) {
+				panic("boom")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(RequestIDHeader, "test-request")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.status)
+			}
+
+			logged := entries(t, buf)
+			want := 1
+			if tt.extra != "" {
+				want = 2
+			}
+			if len(logged) != want {
+				t.Fatalf("got %d entries, want %d: %v", len(logged), want, logged)
+			}
+			for _, entry := range logged {
+				if entry["request_id"] != "test-request" {
+					t.Errorf("entry %q isn't tagged with the request ID", entry["msg"])
+				}
+			}
+			if tt.extra != "" && logged[0]["msg"] != tt.extra {
+				t.Errorf("got %q logged by the handler, want %q", logged[0]["msg"], tt.extra)
+			}
+
+			request := logged[len(logged)-1]
+			if request["msg"] != "Request" || request["level"] != tt.level {
+				t.Errorf("got %s %q, want the request logged at %s", request["level"], request["msg"], tt.level)
+			}
+			if request["route"] != tt.route || request["path"] != tt.path || request["status"] != float64(tt.status) {
+				t.Errorf("got route %v path %v status %v", request["route"], request["path"], request["status"])
+			}
+		})
+	}
+}
+
+func TestInternalServerErrorLogsCause(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := captureLogs(t)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), LoggerMiddleware())
+	router.GET("/fail", func(c *gin.Context) {
+		utils.InternalServerErrorResponse(c, errors.New("connection refused"), "Failed to load items")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	logged := entries(t, buf)
+	if len(logged) == 0 || logged[0]["error"] != "connection refused" {
+		t.Fatalf("the cause wasn't logged: %v", logged)
+	}
+
+	var body struct {
+		Message   string `json:"message"`
+		Error     string `json:"error"`
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Message != "Failed to load items" || body.Error != "internal_server_error" {
+		t.Errorf("got %+v", body)
+	}
+	if body.RequestID == "" || body.RequestID != logged[0]["request_id"] {
+		t.Errorf("body has request ID %q, log has %v", body.RequestID, logged[0]["request_id"])
+	}
+}
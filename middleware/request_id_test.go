@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		sent string
+		keep bool
+	}{
+		{"missing", "", false},
+		{"well formed", "req-42", true},
+		{"every allowed character", "Ab9._:-", true},
+		{"longest allowed", strings.Repeat("a", 128), true},
+		{"too long", strings.Repeat("a", 129), false},
+		{"space", "req 42", false},
+		{"newline", "req\n42", false},
+		{"markup", "<script>", false},
+		{"non-ASCII", "réq", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			router := gin.New()
+			router.Use(RequestIDMiddleware())
+			router.GET("/", func(c *gin.Context) {
+				seen = c.GetString("request_id")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.sent != "" {
+				req.Header.Set(RequestIDHeader, tt.sent)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			returned := rec.Header().Get(RequestIDHeader)
+			if returned != seen {
+				t.Errorf("header has %q but handlers saw %q", returned, seen)
+			}
+			if tt.keep {
+				if returned != tt.sent {
+					t.Errorf("got %q, want the client's %q", returned, tt.sent)
+				}
+				return
+			}
+			if returned == tt.sent {
+				t.Errorf("kept malformed ID %q", tt.sent)
+			}
+			if !requestIDPattern.MatchString(returned) {
+				t.Errorf("generated ID %q is malformed", returned)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddlewareGeneratesUniqueIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/", func(c *gin.Context) {})
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		id := rec.Header().Get(RequestIDHeader)
+		if seen[id] {
+			t.Fatalf("ID %q was generated twice", id)
+		}
+		seen[id] = true
+	}
+}
@@ -2,6 +2,11 @@ package models
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,6 +22,7 @@ type User struct {
 	Phone              string    `json:"phone"`
 	ProfilePictureURL  string    `json:"profile_picture_url"`
 	IsActive           bool      `json:"is_active"`
+	EmailVerified      bool      `json:"email_verified"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 }
@@ -82,17 +88,22 @@ type Event struct {
 	WaitlistCount       int       `json:"waitlist_count,omitempty"`
 	AverageRating       *float64  `json:"average_rating,omitempty"`
 	FeedbackCount       int       `json:"feedback_count,omitempty"`
+	MyRegistrationStatus string   `json:"my_registration_status,omitempty"` // the signed in user's registration, if any
+	Sequence            int       `json:"-"`                                // revision count, the iCalendar SEQUENCE
+	LatestReview         *ContentReview `json:"latest_review,omitempty"`          // shown to the club's moderators
 }
 
 // EventRegistration represents a user's registration for an event
 type EventRegistration struct {
-	RegistrationID   int       `json:"registration_id"`
-	EventID          int       `json:"event_id"`
-	UserID           int       `json:"user_id"`
-	RegistrationStatus string  `json:"registration_status"` // confirmed, waitlist, cancelled, attended
-	RegistrationDate time.Time `json:"registration_date"`
-	AttendanceMarked bool      `json:"attendance_marked"`
-	FeedbackSubmitted bool      `json:"feedback_submitted"`
+	RegistrationID     int        `json:"registration_id"`
+	EventID            int        `json:"event_id"`
+	UserID             int        `json:"user_id"`
+	RegistrationStatus string     `json:"registration_status"` // confirmed, waitlist, cancelled, attended
+	RegistrationDate   time.Time  `json:"registration_date"`
+	AttendanceMarked   bool       `json:"attendance_marked"`
+	FeedbackSubmitted  bool       `json:"feedback_submitted"`
+	CheckedInAt        *time.Time `json:"checked_in_at"`
+	CheckedInBy        *int       `json:"checked_in_by"`
 }
 
 // EventFeedback represents feedback for an event
@@ -115,6 +126,12 @@ type EventGallery struct {
 	UploadedBy int       `json:"uploaded_by"`
 	ImageURL   string    `json:"image_url"`
 	Caption    string    `json:"caption"`
+	ContentType string   `json:"content_type,omitempty"`
+	FileSize   int64     `json:"file_size,omitempty"`
+	Width      int       `json:"width,omitempty"`
+	Height     int       `json:"height,omitempty"`
+	Renditions ImageRenditions `json:"-"`
+	Images     *ImageSet `json:"images,omitempty"`
 	UploadedAt time.Time `json:"uploaded_at"`
 	UploadedByName string `json:"uploaded_by_name,omitempty"`
 }
@@ -136,6 +153,7 @@ type News struct {
 	ClubCode  string    `json:"club_code,omitempty"`
 	Author    string    `json:"author,omitempty"`
 	Media     []NewsMedia `json:"media,omitempty"`
+	LatestReview *ContentReview `json:"latest_review,omitempty"` // shown to the club's moderators
 }
 
 // NewsMedia represents media attached to a news post
@@ -146,9 +164,98 @@ type NewsMedia struct {
 	MediaURL     string    `json:"media_url"`
 	Caption      string    `json:"caption"`
 	DisplayOrder int       `json:"display_order"`
+	ContentType  string    `json:"content_type,omitempty"`
+	FileSize     int64     `json:"file_size,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	Renditions   ImageRenditions `json:"-"`
+	Images       *ImageSet `json:"images,omitempty"`
 	UploadedAt   time.Time `json:"uploaded_at"`
 }
 
+// ImageRendition is a resized copy of an uploaded image
+type ImageRendition struct {
+	Key    string `json:"key"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageRenditions are an image's renditions by size name, stored as JSONB
+type ImageRenditions map[string]ImageRendition
+
+// Value encodes the renditions for storage
+func (r ImageRenditions) Value() (driver.Value, error) {
+	if r == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(r)
+	return string(data), err
+}
+
+// Scan decodes renditions read from the database
+func (r *ImageRenditions) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*r = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into ImageRenditions", src)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// Keys returns the storage keys of the renditions
+func (r ImageRenditions) Keys() []string {
+	keys := make([]string, 0, len(r))
+	for _, rendition := range r {
+		keys = append(keys, rendition.Key)
+	}
+	return keys
+}
+
+// ImageSource is one size of an image that a client can choose from
+type ImageSource struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageSet lists the sizes of an image from smallest to largest, together
+// with the equivalent value for an <img srcset> attribute
+type ImageSet struct {
+	SrcSet  string        `json:"srcset"`
+	Sources []ImageSource `json:"sources"`
+}
+
+// NewImageSet builds the image set for an original and its renditions. It
+// returns nil when the original's dimensions are unknown, as they are for
+// linked images and uploads made before renditions were generated.
+func NewImageSet(url string, width, height int, renditions ImageRenditions) *ImageSet {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	sources := []ImageSource{{Name: "original", URL: url, Width: width, Height: height}}
+	for name, rendition := range renditions {
+		sources = append(sources, ImageSource{Name: name, URL: rendition.URL, Width: rendition.Width, Height: rendition.Height})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Width < sources[j].Width })
+
+	candidates := make([]string, len(sources))
+	for i, source := range sources {
+		candidates[i] = fmt.Sprintf("%s %dw", source.URL, source.Width)
+	}
+
+	return &ImageSet{SrcSet: strings.Join(candidates, ", "), Sources: sources}
+}
+
 // Notification represents a system notification
 type Notification struct {
 	NotificationID   int       `json:"notification_id"`
@@ -175,6 +282,34 @@ type SystemAnnouncement struct {
 	CreatedByName  string    `json:"created_by_name,omitempty"`
 }
 
+// SearchHit is one result of a full-text search. Title and Snippet are
+// HTML-escaped, with the matched terms wrapped in <mark> tags.
+type SearchHit struct {
+	EntityType string    `json:"entity_type"` // event, news, club, announcement
+	EntityID   int       `json:"entity_id"`
+	Title      string    `json:"title"`
+	Snippet    string    `json:"snippet"`
+	Rank       float64   `json:"rank"`
+	Date       time.Time `json:"date"`
+	ClubName   string    `json:"club_name,omitempty"`
+	ClubCode   string    `json:"club_code,omitempty"`
+}
+
+// SearchGroup is a page of search results of one entity type
+type SearchGroup struct {
+	Items []SearchHit `json:"items"`
+	Meta  *PageMeta   `json:"meta"`
+}
+
+// SearchResults are the results of a search, grouped by entity type. Only
+// the requested types are present.
+type SearchResults struct {
+	Events        *SearchGroup `json:"events,omitempty"`
+	News          *SearchGroup `json:"news,omitempty"`
+	Clubs         *SearchGroup `json:"clubs,omitempty"`
+	Announcements *SearchGroup `json:"announcements,omitempty"`
+}
+
 // ActivityLog represents a user activity log entry
 type ActivityLog struct {
 	LogID      int       `json:"log_id"`
@@ -192,7 +327,7 @@ type ActivityLog struct {
 type PasswordResetToken struct {
 	TokenID   int       `json:"token_id"`
 	UserID    int       `json:"user_id"`
-	Token     string    `json:"token"`
+	TokenHash string    `json:"-"`
 	ExpiresAt time.Time `json:"expires_at"`
 	IsUsed    bool      `json:"is_used"`
 	CreatedAt time.Time `json:"created_at"`
@@ -236,55 +371,169 @@ type LoginRequest struct {
 
 // RegisterRequest represents a registration request
 type RegisterRequest struct {
-	StudentID string `json:"student_id" binding:"required"`
-	Email        string `json:"email" binding:"required,email"`
-	Password     string `json:"password" binding:"required,min=6"`
-	FirstName    string `json:"first_name" binding:"required"`
-	LastName     string `json:"last_name" binding:"required"`
+	StudentID string `json:"student_id" binding:"required,notblank,max=50"`
+	Email     string `json:"email" binding:"required,email,max=255"`
+	Password  string `json:"password" binding:"required,password"`
+	FirstName string `json:"first_name" binding:"required,notblank,max=100"`
+	LastName  string `json:"last_name" binding:"required,notblank,max=100"`
+}
+
+// ResetPasswordRequest represents a password reset with an emailed token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,password"`
 }
 
 // UpdateProfileRequest represents a profile update request
 type UpdateProfileRequest struct {
-	FirstName         string `json:"first_name"`
-	LastName          string `json:"last_name"`
-	Phone             string `json:"phone"`
+	FirstName         string `json:"first_name" binding:"max=100"`
+	LastName          string `json:"last_name" binding:"max=100"`
+	Phone             string `json:"phone" binding:"max=30"`
 	ProfilePictureURL string `json:"profile_picture_url"`
 }
 
 // CreateClubRequest represents a club creation request
 type CreateClubRequest struct {
-	ClubName      string `json:"club_name" binding:"required"`
-	ClubCode      string `json:"club_code" binding:"required"`
+	ClubName      string     `json:"club_name" binding:"required,notblank,max=150"`
+	ClubCode      string     `json:"club_code" binding:"required,club_code"`
 	Description   string `json:"description"`
 	LogoURL       string `json:"logo_url"`
 	CoverImageURL string `json:"cover_image_url"`
 	FoundedDate   *time.Time `json:"founded_date"`
-	Email         string `json:"email"`
+	Email         string     `json:"email" binding:"omitempty,email,max=255"`
 }
 
 // CreateEventRequest represents an event creation request
 type CreateEventRequest struct {
 	ClubID               int       `json:"club_id" binding:"required"`
-	Title                string    `json:"title" binding:"required"`
+	Title                string     `json:"title" binding:"required,notblank,max=255"`
 	Description          string    `json:"description"`
-	EventType            string    `json:"event_type"`
-	Location             string    `json:"location"`
+	EventType            string     `json:"event_type" binding:"max=50"`
+	Location             string     `json:"location" binding:"max=255"`
 	StartDatetime        time.Time `json:"start_datetime" binding:"required"`
-	EndDatetime          time.Time `json:"end_datetime" binding:"required"`
-	RegistrationDeadline *time.Time `json:"registration_deadline"`
-	Capacity             int       `json:"capacity"`
+	EndDatetime          time.Time  `json:"end_datetime" binding:"required,gtfield=StartDatetime"`
+	RegistrationDeadline *time.Time `json:"registration_deadline" binding:"omitempty,ltefield=StartDatetime"`
+	Capacity             int        `json:"capacity" binding:"gt=0"`
 	BannerImageURL       string    `json:"banner_image_url"`
 }
 
+// ReviewComment is a reviewer's note on one field of an event or news post,
+// optionally pointing at a line of its text
+type ReviewComment struct {
+	Field   string `json:"field"`
+	Line    int    `json:"line,omitempty"`
+	Comment string `json:"comment"`
+}
+
+// ReviewComments is stored as a JSON array
+type ReviewComments []ReviewComment
+
+// Value encodes comments for the database
+func (r ReviewComments) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(r)
+	return string(data), err
+}
+
+// Scan decodes comments read from the database
+func (r *ReviewComments) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*r = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into ReviewComments", src)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// ContentReview is an entry in the review history of an event or news post:
+// a reviewer's decision or the author's resubmission
+type ContentReview struct {
+	ReviewID   int64          `json:"review_id"`
+	EntityType string         `json:"entity_type"` // event, news
+	EntityID   int            `json:"entity_id"`
+	Action     string         `json:"action"` // approved, rejected, resubmitted
+	UserID     *int           `json:"user_id"`
+	UserName   string         `json:"user_name,omitempty"`
+	Reason     string         `json:"reason"`
+	Comments   ReviewComments `json:"comments"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// ReviewRequest is a reviewer's decision on an event or news post. A reason
+// is required to reject.
+type ReviewRequest struct {
+	Reason   string          `json:"reason"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// UpdateEventRequest represents an event edit. Only the fields sent are
+// changed.
+type UpdateEventRequest struct {
+	Title                *string    `json:"title" binding:"omitempty,notblank,max=255"`
+	Description          *string    `json:"description"`
+	EventType            *string    `json:"event_type" binding:"omitempty,max=50"`
+	Location             *string    `json:"location" binding:"omitempty,max=255"`
+	StartDatetime        *time.Time `json:"start_datetime"`
+	EndDatetime          *time.Time `json:"end_datetime"`
+	RegistrationDeadline *time.Time `json:"registration_deadline"`
+	Capacity             *int       `json:"capacity" binding:"omitempty,gt=0"`
+	IsRegistrationOpen   *bool      `json:"is_registration_open"`
+	BannerImageURL       *string    `json:"banner_image_url"`
+}
+
+// CancelEventRequest represents an event cancellation
+type CancelEventRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RescheduleEventRequest represents moving an event to a new time and,
+// optionally, a new location
+type RescheduleEventRequest struct {
+	StartDatetime        time.Time  `json:"start_datetime" binding:"required"`
+	EndDatetime          time.Time  `json:"end_datetime" binding:"required,gtfield=StartDatetime"`
+	RegistrationDeadline *time.Time `json:"registration_deadline" binding:"omitempty,ltefield=StartDatetime"`
+	Location             *string    `json:"location" binding:"omitempty,max=255"`
+	Reason               string     `json:"reason"`
+}
+
+// EventStatusChange is an entry in an event's status history
+type EventStatusChange struct {
+	HistoryID     int64     `json:"history_id"`
+	FromStatus    *string   `json:"from_status"` // nil when the event was created
+	ToStatus      string    `json:"to_status"`
+	ChangedBy     *int      `json:"changed_by"` // nil for changes made by the system
+	ChangedByName string    `json:"changed_by_name,omitempty"`
+	Reason        string    `json:"reason"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
 // CreateNewsRequest represents a news creation request
 type CreateNewsRequest struct {
 	ClubID     int    `json:"club_id" binding:"required"`
-	Title      string `json:"title" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	Category   string `json:"category"`
+	Title      string `json:"title" binding:"required,notblank,max=255"`
+	Content    string `json:"content" binding:"required,notblank"`
+	Category   string `json:"category" binding:"omitempty,news_category"`
 	IsFeatured bool   `json:"is_featured"`
 }
 
+// UpdateNewsRequest represents a news post edit. Only the fields sent are
+// changed.
+type UpdateNewsRequest struct {
+	Title      *string `json:"title" binding:"omitempty,notblank,max=255"`
+	Content    *string `json:"content" binding:"omitempty,notblank"`
+	Category   *string `json:"category" binding:"omitempty,news_category"`
+	IsFeatured *bool   `json:"is_featured"`
+}
+
 // SubmitFeedbackRequest represents feedback submission
 type SubmitFeedbackRequest struct {
 	Rating int    `json:"rating" binding:"required,min=1,max=5"`
@@ -296,20 +545,68 @@ type APIResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    *PageMeta   `json:"meta,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	// Details lists the fields an error was caused by
+	Details []FieldError `json:"details,omitempty"`
+	// RequestID identifies the request in the logs; set on errors
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// FieldError describes a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // JWTClaims represents JWT token claims
 type JWTClaims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    int    `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID int64  `json:"sid"`
 }
 
-// PaginationParams represents pagination parameters
+// RefreshTokenRequest represents a token refresh request
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// JobRun represents one execution of a background job
+type JobRun struct {
+	RunID          int64      `json:"run_id"`
+	JobName        string     `json:"job_name"`
+	TriggerType    string     `json:"trigger_type"` // schedule, manual
+	TriggeredBy    *int       `json:"triggered_by"`
+	Status         string     `json:"status"` // running, succeeded, failed
+	ItemsProcessed int        `json:"items_processed"`
+	Error          string     `json:"error,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+// ScheduledJob describes a registered background job and its latest run
+type ScheduledJob struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Interval    string  `json:"interval"`
+	LastRun     *JobRun `json:"last_run"`
+}
+
+// PaginationParams represents pagination parameters of list requests
 type PaginationParams struct {
-	Page     int `json:"page" form:"page"`
-	PageSize int `json:"page_size" form:"page_size"`
+	Limit  int    `json:"limit" form:"limit"`
+	Cursor string `json:"cursor" form:"cursor"`
+	Sort   string `json:"sort" form:"sort"` // field name, prefixed with - for descending
+}
+
+// PageMeta describes a page of a list response
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"`
+	Sort       string `json:"sort"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // Helper function to check if a value is null
@@ -0,0 +1,144 @@
+// Package notifier turns domain events into rows in the notifications table.
+// Handlers call it inside the same transaction as the change that triggered
+// the notification, so a rolled-back change never notifies anyone. Live
+// streams are told about new notifications once that transaction commits.
+package notifier
+
+import (
+	"github.com/lib/pq"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/realtime"
+)
+
+// Notification describes a single notification before it is rendered. Data is
+// passed to the kind's title and message templates.
+type Notification struct {
+	Kind       Kind
+	EntityType string
+	EntityID   int
+	Data       map[string]interface{}
+}
+
+// ToUser notifies a single user
+func ToUser(exec database.Execer, userID int, n Notification) error {
+	title, message, err := render(n)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.Exec(
+		`INSERT INTO notifications (user_id, title, message, notification_type, related_entity_type, related_entity_id)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		userID, title, message, string(n.Kind), n.EntityType, n.EntityID,
+	)
+	if err != nil {
+		return err
+	}
+
+	database.AfterCommit(exec, func() { realtime.NotifyUsers(userID) })
+	return nil
+}
+
+// ToClubMembers notifies every active member of a club, skipping the given
+// users (typically the ones who already received a more specific notification)
+func ToClubMembers(exec database.Querier, clubID int, n Notification, excludeUserIDs ...int) error {
+	title, message, err := render(n)
+	if err != nil {
+		return err
+	}
+
+	exclude := make([]int64, len(excludeUserIDs))
+	for i, id := range excludeUserIDs {
+		exclude[i] = int64(id)
+	}
+
+	rows, err := exec.Query(
+		`INSERT INTO notifications (user_id, title, message, notification_type, related_entity_type, related_entity_id)
+		 SELECT cm.user_id, $2, $3, $4, $5, $6
+		 FROM club_members cm
+		 JOIN users u ON cm.user_id = u.user_id
+		 WHERE cm.club_id = $1 AND cm.is_active = TRUE AND u.is_active = TRUE
+		   AND NOT (cm.user_id = ANY($7))
+		 RETURNING user_id`,
+		clubID, title, message, string(n.Kind), n.EntityType, n.EntityID, pq.Array(exclude),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var recipients []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return err
+		}
+		recipients = append(recipients, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	database.AfterCommit(exec, func() { realtime.NotifyUsers(recipients...) })
+	return nil
+}
+
+// ToEventRegistrants notifies every active user with a confirmed or waitlisted
+// registration for an event
+func ToEventRegistrants(exec database.Querier, eventID int, n Notification) error {
+	title, message, err := render(n)
+	if err != nil {
+		return err
+	}
+
+	rows, err := exec.Query(
+		`INSERT INTO notifications (user_id, title, message, notification_type, related_entity_type, related_entity_id)
+		 SELECT er.user_id, $2, $3, $4, $5, $6
+		 FROM event_registrations er
+		 JOIN users u ON er.user_id = u.user_id
+		 WHERE er.event_id = $1 AND er.registration_status IN ('confirmed', 'waitlist') AND u.is_active = TRUE
+		 RETURNING user_id`,
+		eventID, title, message, string(n.Kind), n.EntityType, n.EntityID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var recipients []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return err
+		}
+		recipients = append(recipients, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	database.AfterCommit(exec, func() { realtime.NotifyUsers(recipients...) })
+	return nil
+}
+
+// ToAllUsers notifies every active user
+func ToAllUsers(exec database.Execer, n Notification) error {
+	title, message, err := render(n)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.Exec(
+		`INSERT INTO notifications (user_id, title, message, notification_type, related_entity_type, related_entity_id)
+		 SELECT user_id, $1, $2, $3, $4, $5
+		 FROM users
+		 WHERE is_active = TRUE`,
+		title, message, string(n.Kind), n.EntityType, n.EntityID,
+	)
+	if err != nil {
+		return err
+	}
+
+	database.AfterCommit(exec, realtime.NotifyAll)
+	return nil
+}
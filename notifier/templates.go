@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Kind identifies a notification type; it is stored in notification_type
+type Kind string
+
+const (
+	EventApproved          Kind = "event_approved"
+	EventRejected          Kind = "event_rejected"
+	EventPublished         Kind = "event_published"
+	NewsApproved           Kind = "news_approved"
+	NewsRejected           Kind = "news_rejected"
+	NewsPublished          Kind = "news_published"
+	RegistrationConfirmed  Kind = "registration_confirmed"
+	RegistrationWaitlisted Kind = "registration_waitlisted"
+	WaitlistPromoted       Kind = "waitlist_promoted"
+	ModeratorAssigned      Kind = "moderator_assigned"
+	SystemAnnouncement     Kind = "system_announcement"
+	EventReminder          Kind = "event_reminder"
+	FeedbackRequested      Kind = "feedback_requested"
+	EventCancelled         Kind = "event_cancelled"
+	EventRescheduled       Kind = "event_rescheduled"
+)
+
+// DateFormat is how event dates are written in notification messages
+const DateFormat = "Mon, Jan 2 2006 at 3:04 PM"
+
+// kindTemplates holds the title and message template for each kind
+var kindTemplates = map[Kind][2]string{
+	EventApproved: {
+		`Your event was approved`,
+		`"{{.EventTitle}}" has been approved and is now visible to students.`,
+	},
+	EventRejected: {
+		`Your event was not approved`,
+		`"{{.EventTitle}}" was rejected by an administrator.{{if .Reason}} Reason: {{.Reason}}{{end}}`,
+	},
+	EventPublished: {
+		`New event from {{.ClubName}}`,
+		`{{.ClubName}} is hosting "{{.EventTitle}}" on {{.StartDate}}.`,
+	},
+	NewsApproved: {
+		`Your news post was published`,
+		`"{{.NewsTitle}}" has been approved and published.`,
+	},
+	NewsRejected: {
+		`Your news post was not approved`,
+		`"{{.NewsTitle}}" was rejected by an administrator.{{if .Reason}} Reason: {{.Reason}}{{end}}`,
+	},
+	NewsPublished: {
+		`News from {{.ClubName}}`,
+		`{{.ClubName}} posted "{{.NewsTitle}}".`,
+	},
+	RegistrationConfirmed: {
+		`Registration confirmed`,
+		`You're registered for "{{.EventTitle}}" on {{.StartDate}}.`,
+	},
+	RegistrationWaitlisted: {
+		`You're on the waitlist`,
+		`"{{.EventTitle}}" is full. We'll let you know if a seat opens up.`,
+	},
+	WaitlistPromoted: {
+		`You're off the waitlist!`,
+		`A seat opened up and your registration for "{{.EventTitle}}" is now confirmed.`,
+	},
+	ModeratorAssigned: {
+		`You're now a moderator`,
+		`You have been made a moderator of {{.ClubName}}.`,
+	},
+	SystemAnnouncement: {
+		`{{.Title}}`,
+		`{{.Content}}`,
+	},
+	EventReminder: {
+		`"{{.EventTitle}}" starts {{.StartsIn}}`,
+		`Reminder: "{{.EventTitle}}" starts on {{.StartDate}}{{if .Location}} at {{.Location}}{{end}}.`,
+	},
+	FeedbackRequested: {
+		`How was "{{.EventTitle}}"?`,
+		`Thanks for joining "{{.EventTitle}}". Let the organizers know what you thought by leaving feedback.`,
+	},
+	EventCancelled: {
+		`"{{.EventTitle}}" was cancelled`,
+		`{{.ClubName}} cancelled "{{.EventTitle}}", which was scheduled for {{.StartDate}}.{{if .Reason}} Reason: {{.Reason}}{{end}}`,
+	},
+	EventRescheduled: {
+		`"{{.EventTitle}}" has a new time`,
+		`"{{.EventTitle}}" moved from {{.OldStartDate}} to {{.StartDate}}{{if .Location}} at {{.Location}}{{end}}.{{if .Reason}} Reason: {{.Reason}}{{end}}`,
+	},
+}
+
+// templates is kindTemplates parsed once at startup
+var templates = parseTemplates()
+
+func parseTemplates() map[Kind][2]*template.Template {
+	parsed := make(map[Kind][2]*template.Template, len(kindTemplates))
+	for kind, texts := range kindTemplates {
+		parsed[kind] = [2]*template.Template{
+			template.Must(template.New(string(kind) + ".title").Option("missingkey=error").Parse(texts[0])),
+			template.Must(template.New(string(kind) + ".message").Option("missingkey=error").Parse(texts[1])),
+		}
+	}
+	return parsed
+}
+
+// render produces the title and message for a notification
+func render(n Notification) (string, string, error) {
+	tmpls, ok := templates[n.Kind]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification kind %q", n.Kind)
+	}
+
+	var out [2]string
+	for i, tmpl := range tmpls {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, n.Data); err != nil {
+			return "", "", fmt.Errorf("failed to render %s notification: %w", n.Kind, err)
+		}
+		out[i] = strings.TrimSpace(buf.String())
+	}
+
+	return out[0], out[1], nil
+}
@@ -0,0 +1,91 @@
+// Package realtime pushes live updates to connected clients. A Hub tracks the
+// streams open on this instance; a Publisher decides how updates reach the
+// hubs, either directly in-process or through Postgres LISTEN/NOTIFY when
+// several API instances run side by side.
+package realtime
+
+import "sync"
+
+// Update tells subscribers that something changed for the given users, or
+// for everyone when All is set. Streams re-read the database on receipt, so
+// an update carries no payload of its own.
+type Update struct {
+	UserIDs []int `json:"user_ids,omitempty"`
+	All     bool  `json:"all,omitempty"`
+}
+
+// Subscription receives a signal on C whenever an update concerns its user.
+// Signals are coalesced: a stream that is busy sees at most one pending signal.
+type Subscription struct {
+	C      <-chan struct{}
+	c      chan struct{}
+	userID int
+	hub    *Hub
+}
+
+// Close unregisters the subscription
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// Hub fans updates out to the subscriptions open on this instance
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[int]map[*Subscription]struct{}
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{subs: make(map[int]map[*Subscription]struct{})}
+}
+
+// Subscribe registers a stream for a user's updates
+func (h *Hub) Subscribe(userID int) *Subscription {
+	ch := make(chan struct{}, 1)
+	sub := &Subscription{C: ch, c: ch, userID: userID, hub: h}
+
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subs[sub.userID], sub)
+	if len(h.subs[sub.userID]) == 0 {
+		delete(h.subs, sub.userID)
+	}
+}
+
+// Deliver signals every local subscription the update concerns
+func (h *Hub) Deliver(u Update) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if u.All {
+		for _, subs := range h.subs {
+			signal(subs)
+		}
+		return
+	}
+	for _, userID := range u.UserIDs {
+		signal(h.subs[userID])
+	}
+}
+
+func signal(subs map[*Subscription]struct{}) {
+	for sub := range subs {
+		select {
+		case sub.c <- struct{}{}:
+		default:
+			// A signal is already pending
+		}
+	}
+}
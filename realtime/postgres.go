@@ -0,0 +1,83 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+)
+
+// notifyChannel is the Postgres channel updates are broadcast on
+const notifyChannel = "realtime_updates"
+
+// maxNotifyUsers bounds the user IDs sent in one NOTIFY, keeping the payload
+// well under Postgres' 8000 byte limit; larger updates go to everyone
+const maxNotifyUsers = 500
+
+// PostgresPublisher broadcasts updates with NOTIFY and delivers the updates it
+// hears on LISTEN to the local hub, so every instance sees every update
+type PostgresPublisher struct {
+	hub      *Hub
+	listener *pq.Listener
+}
+
+// NewPostgresPublisher starts listening for updates on the given database
+func NewPostgresPublisher(databaseURL string, hub *Hub) (*PostgresPublisher, error) {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("Realtime listener error", "error", err)
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	p := &PostgresPublisher{hub: hub, listener: listener}
+	go p.run()
+	return p, nil
+}
+
+// Publish sends the update to every instance, including this one
+func (p *PostgresPublisher) Publish(u Update) {
+	if len(u.UserIDs) > maxNotifyUsers {
+		u = Update{All: true}
+	}
+
+	payload, err := json.Marshal(u)
+	if err != nil {
+		slog.Error("Failed to encode realtime update", "error", err)
+		return
+	}
+
+	if _, err := database.DB.Exec(`SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		// Fall back to this instance's streams rather than dropping the update
+		slog.Error("Failed to publish realtime update", "error", err)
+		p.hub.Deliver(u)
+	}
+}
+
+// Close stops listening
+func (p *PostgresPublisher) Close() error {
+	return p.listener.Close()
+}
+
+func (p *PostgresPublisher) run() {
+	for n := range p.listener.Notify {
+		if n == nil {
+			// The connection was re-established and updates may have been
+			// missed, so have every stream resynchronize
+			p.hub.Deliver(Update{All: true})
+			continue
+		}
+
+		var u Update
+		if err := json.Unmarshal([]byte(n.Extra), &u); err != nil {
+			slog.Warn("Ignoring malformed realtime update", "error", err)
+			continue
+		}
+		p.hub.Deliver(u)
+	}
+}
@@ -0,0 +1,63 @@
+package realtime
+
+import (
+	"fmt"
+
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+)
+
+// Publisher sends updates to the hubs of every API instance
+type Publisher interface {
+	Publish(u Update)
+}
+
+// LocalHub holds the streams connected to this instance
+var LocalHub = NewHub()
+
+// Default is the publisher used by the application, configured by Init. It
+// delivers in-process until Init selects another backend.
+var Default Publisher = LocalHub
+
+// Publish delivers the hub's updates in-process, which is all a single
+// instance needs
+func (h *Hub) Publish(u Update) {
+	h.Deliver(u)
+}
+
+// Init configures the default publisher from the application config
+func Init() error {
+	switch config.AppConfig.RealtimeBackend {
+	case "memory":
+		Default = LocalHub
+
+	case "postgres":
+		pub, err := NewPostgresPublisher(config.AppConfig.DatabaseURL, LocalHub)
+		if err != nil {
+			return err
+		}
+		Default = pub
+
+	default:
+		return fmt.Errorf("unknown REALTIME_BACKEND %q (expected memory or postgres)", config.AppConfig.RealtimeBackend)
+	}
+
+	return nil
+}
+
+// Publish sends an update with the default publisher
+func Publish(u Update) {
+	if len(u.UserIDs) == 0 && !u.All {
+		return
+	}
+	Default.Publish(u)
+}
+
+// NotifyUsers is shorthand for publishing an update for some users
+func NotifyUsers(userIDs ...int) {
+	Publish(Update{UserIDs: userIDs})
+}
+
+// NotifyAll is shorthand for publishing an update for every user
+func NotifyAll() {
+	Publish(Update{All: true})
+}
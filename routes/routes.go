@@ -7,6 +7,13 @@ import (
 )
 
 func SetupRoutes(router *gin.Engine) {
+	// Request IDs, so error responses and log entries can be traced
+	router.Use(middleware.RequestIDMiddleware())
+
+	// Request logging, and recovery from panics
+	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.RecoveryMiddleware())
+
 	// CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
@@ -15,6 +22,10 @@ func SetupRoutes(router *gin.Engine) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Uploaded files
+	router.GET("/media/*key", handlers.ServeMedia)
+	router.HEAD("/media/*key", handlers.ServeMedia)
+
 	// Authentication routes (no auth required)
 	authGroup := router.Group("/api/auth")
 	{
@@ -22,16 +33,23 @@ func SetupRoutes(router *gin.Engine) {
 		authGroup.POST("/login", handlers.Login)
 		authGroup.POST("/forgot-password", handlers.ForgotPassword)
 		authGroup.POST("/reset-password", handlers.ResetPassword)
+		authGroup.POST("/refresh", handlers.RefreshToken)
+		authGroup.POST("/verify-email", handlers.VerifyEmail)
+		authGroup.POST("/resend-verification", middleware.AuthMiddleware(), handlers.ResendVerificationEmail)
+		authGroup.POST("/logout", middleware.AuthMiddleware(), handlers.Logout)
+		authGroup.POST("/logout-all", middleware.AuthMiddleware(), handlers.LogoutAllDevices)
 	}
 
 	// User routes
 	userGroup := router.Group("/api/users")
-	userGroup.Use(middleware.AuthMiddleware())
+	userGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware())
 	{
 		userGroup.GET("/profile", handlers.GetProfile)
 		userGroup.PUT("/profile", handlers.UpdateProfile)
 		userGroup.GET("/:id/clubs", handlers.GetUserClubs)
 		userGroup.GET("/:id/events", handlers.GetUserRegisteredEvents)
+		userGroup.POST("/calendar-feed", handlers.CreateCalendarFeed)
+		userGroup.DELETE("/calendar-feed", handlers.DeleteCalendarFeed)
 	}
 
 	// Club routes
@@ -43,11 +61,12 @@ func SetupRoutes(router *gin.Engine) {
 		clubGroup.GET("/:id/members", handlers.GetClubMembers)
 		clubGroup.GET("/:id/moderators", handlers.GetClubModerators)
 		clubGroup.GET("/:id/news", handlers.GetClubNews)
+		clubGroup.GET("/:id/calendar.ics", handlers.GetClubCalendar)
 	}
 
 	// Club routes requiring authentication
 	clubAuthGroup := router.Group("/api/clubs")
-	clubAuthGroup.Use(middleware.AuthMiddleware())
+	clubAuthGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware())
 	{
 		clubAuthGroup.POST("", middleware.RoleMiddleware("system_admin"), handlers.CreateClub)
 		clubAuthGroup.POST("/:id/join", handlers.JoinClub)
@@ -65,22 +84,35 @@ func SetupRoutes(router *gin.Engine) {
 	{
 		eventGroup.GET("", handlers.GetAllEvents)
 		eventGroup.GET("/:id", handlers.GetEventDetails)
-		eventGroup.GET("/:id/registrations", handlers.GetEventRegistrations)
 		eventGroup.GET("/:id/feedback", handlers.GetEventFeedback)
+		eventGroup.GET("/:id/ics", handlers.GetEventCalendar)
 	}
 
 	// Event routes requiring authentication
 	eventAuthGroup := router.Group("/api/events")
-	eventAuthGroup.Use(middleware.AuthMiddleware())
+	eventAuthGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware())
 	{
-		eventAuthGroup.POST("", handlers.CreateEvent)
+		eventAuthGroup.POST("", middleware.ClubModeratorMiddleware(middleware.ClubFromJSONBody("club_id")), handlers.CreateEvent)
+		eventAuthGroup.PUT("/:id", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.UpdateEvent)
+		eventAuthGroup.POST("/:id/cancel", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.CancelEvent)
+		eventAuthGroup.POST("/:id/reschedule", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.RescheduleEvent)
+		eventAuthGroup.GET("/:id/history", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.GetEventStatusHistory)
+		eventAuthGroup.GET("/:id/reviews", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.GetEventReviews)
 		eventAuthGroup.POST("/:id/register", handlers.RegisterForEvent)
 		eventAuthGroup.DELETE("/:id/register", handlers.CancelEventRegistration)
 		eventAuthGroup.POST("/:id/feedback", handlers.SubmitEventFeedback)
-		eventAuthGroup.POST("/:id/attendance", middleware.RoleMiddleware("club_moderator", "system_admin"), handlers.MarkAttendance)
+		// Registrations expose registrants' personal details, so only the
+		// event's club may list them
+		eventAuthGroup.GET("/:id/registrations", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.GetEventRegistrations)
+		eventAuthGroup.GET("/:id/registrations.csv", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.ExportEventRegistrations)
+		eventAuthGroup.POST("/:id/attendance", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.MarkAttendance)
+		eventAuthGroup.POST("/:id/attendance/bulk", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.BulkMarkAttendance)
+		eventAuthGroup.GET("/:id/ticket", handlers.GetEventTicket)
+		eventAuthGroup.GET("/:id/ticket.png", handlers.GetEventTicketQRCode)
+		eventAuthGroup.POST("/:id/check-in", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.CheckInTicket)
 		eventAuthGroup.POST("/:id/approve", middleware.RoleMiddleware("system_admin"), handlers.ApproveEvent)
 		eventAuthGroup.POST("/:id/reject", middleware.RoleMiddleware("system_admin"), handlers.RejectEvent)
-		eventAuthGroup.POST("/:id/gallery", handlers.UploadEventGallery)
+		eventAuthGroup.POST("/:id/gallery", middleware.ClubModeratorMiddleware(middleware.ClubFromEventParam("id")), handlers.UploadEventGallery)
 		eventAuthGroup.DELETE("/:id/gallery/:galleryId", handlers.DeleteGalleryImage)
 	}
 
@@ -93,7 +125,7 @@ func SetupRoutes(router *gin.Engine) {
 
 	// Admin event routes
 	adminEventGroup := router.Group("/api/admin/events")
-	adminEventGroup.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("system_admin"))
+	adminEventGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware(), middleware.RoleMiddleware("system_admin"))
 	{
 		adminEventGroup.GET("/pending", handlers.GetPendingEvents)
 	}
@@ -109,11 +141,13 @@ func SetupRoutes(router *gin.Engine) {
 
 	// News routes requiring authentication
 	newsAuthGroup := router.Group("/api/news")
-	newsAuthGroup.Use(middleware.AuthMiddleware())
+	newsAuthGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware())
 	{
-		newsAuthGroup.POST("", handlers.CreateNews)
-		newsAuthGroup.POST("/:id/media", handlers.UploadNewsMedia)
-		newsAuthGroup.DELETE("/:id/media/:mediaId", handlers.DeleteNewsMedia)
+		newsAuthGroup.POST("", middleware.ClubModeratorMiddleware(middleware.ClubFromJSONBody("club_id")), handlers.CreateNews)
+		newsAuthGroup.PUT("/:id", middleware.ClubModeratorMiddleware(middleware.ClubFromNewsParam("id")), handlers.UpdateNews)
+		newsAuthGroup.GET("/:id/reviews", middleware.ClubModeratorMiddleware(middleware.ClubFromNewsParam("id")), handlers.GetNewsReviews)
+		newsAuthGroup.POST("/:id/media", middleware.ClubModeratorMiddleware(middleware.ClubFromNewsParam("id")), handlers.UploadNewsMedia)
+		newsAuthGroup.DELETE("/:id/media/:mediaId", middleware.ClubModeratorMiddleware(middleware.ClubFromNewsParam("id")), handlers.DeleteNewsMedia)
 		newsAuthGroup.PUT("/:id/approve", middleware.RoleMiddleware("system_admin"), handlers.ApproveNews)
 		newsAuthGroup.PUT("/:id/reject", middleware.RoleMiddleware("system_admin"), handlers.RejectNews)
 	}
@@ -127,14 +161,24 @@ func SetupRoutes(router *gin.Engine) {
 
 	// Admin news routes
 	adminNewsGroup := router.Group("/api/admin/news")
-	adminNewsGroup.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("system_admin"))
+	adminNewsGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware(), middleware.RoleMiddleware("system_admin"))
 	{
 		adminNewsGroup.GET("/pending", handlers.GetPendingNews)
 	}
 
+	// Personal calendar feed, authenticated by the token in the URL
+	router.GET("/api/calendar/:token", handlers.GetPersonalCalendar)
+
+	// Full-text search (public)
+	searchGroup := router.Group("/api/search")
+	searchGroup.Use(middleware.OptionalAuthMiddleware())
+	{
+		searchGroup.GET("", handlers.Search)
+	}
+
 	// Notification routes
 	notificationGroup := router.Group("/api/notifications")
-	notificationGroup.Use(middleware.AuthMiddleware())
+	notificationGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware())
 	{
 		notificationGroup.GET("", handlers.GetUserNotifications)
 		notificationGroup.GET("/unread-count", handlers.GetUnreadNotificationCount)
@@ -143,6 +187,13 @@ func SetupRoutes(router *gin.Engine) {
 		notificationGroup.DELETE("/:id", handlers.DeleteNotification)
 	}
 
+	// Notification stream; EventSource clients may pass the token in the query string
+	notificationStreamGroup := router.Group("/api/notifications")
+	notificationStreamGroup.Use(middleware.QueryTokenMiddleware(), middleware.AuthMiddleware())
+	{
+		notificationStreamGroup.GET("/stream", handlers.StreamNotifications)
+	}
+
 	// System announcements routes
 	announcementGroup := router.Group("/api/announcements")
 	announcementGroup.Use(middleware.OptionalAuthMiddleware())
@@ -153,7 +204,7 @@ func SetupRoutes(router *gin.Engine) {
 
 	// System announcements routes requiring admin
 	announcementAdminGroup := router.Group("/api/announcements")
-	announcementAdminGroup.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("system_admin"))
+	announcementAdminGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware(), middleware.RoleMiddleware("system_admin"))
 	{
 		announcementAdminGroup.POST("", handlers.CreateSystemAnnouncement)
 		announcementAdminGroup.PUT("/:id", handlers.UpdateSystemAnnouncement)
@@ -162,7 +213,7 @@ func SetupRoutes(router *gin.Engine) {
 
 	// Activity log routes
 	activityGroup := router.Group("/api/activity")
-	activityGroup.Use(middleware.AuthMiddleware())
+	activityGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware())
 	{
 		activityGroup.GET("/current-user", handlers.GetCurrentUserActivityLog)
 		activityGroup.GET("/user/:id", handlers.GetUserActivityLog)
@@ -170,14 +221,14 @@ func SetupRoutes(router *gin.Engine) {
 
 	// Activity log routes (admin only)
 	activityAdminGroup := router.Group("/api/activity")
-	activityAdminGroup.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("system_admin"))
+	activityAdminGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware(), middleware.RoleMiddleware("system_admin"))
 	{
 		activityAdminGroup.GET("/all", handlers.GetAllActivityLogs)
 	}
 
 	// Admin routes
 	adminGroup := router.Group("/api/admin")
-	adminGroup.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("system_admin"))
+	adminGroup.Use(middleware.AuthMiddleware(), middleware.VerifiedEmailMiddleware(), middleware.RoleMiddleware("system_admin"))
 	{
 		adminGroup.GET("/dashboard", handlers.GetDashboardStats)
 		adminGroup.GET("/analytics/clubs", handlers.GetClubActivityMetrics)
@@ -197,5 +248,10 @@ func SetupRoutes(router *gin.Engine) {
 		adminGroup.PUT("/users/:id", handlers.AdminUpdateUser)
 		adminGroup.DELETE("/users/:id", handlers.AdminDeleteUser)
 		adminGroup.PUT("/users/:id/role", handlers.AdminChangeUserRole)
+
+		// Background jobs
+		adminGroup.GET("/jobs", handlers.AdminListJobs)
+		adminGroup.GET("/jobs/runs", handlers.AdminListJobRuns)
+		adminGroup.POST("/jobs/:name/run", handlers.AdminTriggerJob)
 	}
 }
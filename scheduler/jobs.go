@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/lifecycle"
+	"github.com/nub-clubs-connect/nub_admin_api/notifier"
+)
+
+// feedbackWindow is how long after an event ends attendees are still asked
+// for feedback; older events are left alone
+const feedbackWindow = 7 * 24 * time.Hour
+
+// RegisterDefaultJobs registers the application's background jobs
+func RegisterDefaultJobs(s *Scheduler) {
+	s.Register(&Job{
+		Name:        "event_reminders",
+		Description: "Remind confirmed registrants 24 hours and 1 hour before an event starts",
+		Interval:    5 * time.Minute,
+		Run:         sendEventReminders,
+	})
+	s.Register(&Job{
+		Name:        "complete_events",
+		Description: "Mark approved events as completed once they have ended",
+		Interval:    5 * time.Minute,
+		Run:         completeFinishedEvents,
+	})
+	s.Register(&Job{
+		Name:        "feedback_requests",
+		Description: "Ask attendees of completed events for feedback",
+		Interval:    15 * time.Minute,
+		Run:         requestEventFeedback,
+	})
+	s.Register(&Job{
+		Name:        "purge_expired_tokens",
+		Description: "Delete expired password reset and email verification tokens",
+		Interval:    time.Hour,
+		Run:         purgeExpiredTokens,
+	})
+}
+
+// reminderWindow is a reminder sent when an event is less than Before away,
+// as long as it is still more than After away
+type reminderWindow struct {
+	Type     string
+	Before   time.Duration
+	After    time.Duration
+	StartsIn string
+}
+
+var reminderWindows = []reminderWindow{
+	{Type: "24h", Before: 24 * time.Hour, After: time.Hour, StartsIn: "tomorrow"},
+	{Type: "1h", Before: time.Hour, After: 0, StartsIn: "in an hour"},
+}
+
+// sendEventReminders notifies confirmed registrants of upcoming events, once
+// per reminder window
+func sendEventReminders(ctx context.Context) (int, error) {
+	sent := 0
+	for _, window := range reminderWindows {
+		n, err := sendReminderWindow(ctx, window)
+		sent += n
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+func sendReminderWindow(ctx context.Context, window reminderWindow) (int, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Record the reminders first; the conflict check makes each one unique
+	// even if two runs overlap
+	now := time.Now()
+	rows, err := tx.QueryContext(ctx,
+		`INSERT INTO event_reminders (event_id, user_id, reminder_type)
+		 SELECT er.event_id, er.user_id, $1
+		 FROM event_registrations er
+		 JOIN events e ON er.event_id = e.event_id
+		 WHERE e.status = 'approved'
+		   AND e.start_datetime > $2 AND e.start_datetime <= $3
+		   AND er.registration_status = 'confirmed'
+		 ON CONFLICT (event_id, user_id, reminder_type) DO NOTHING
+		 RETURNING event_id, user_id`,
+		window.Type, now.Add(window.After), now.Add(window.Before),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	type reminder struct{ eventID, userID int }
+	var reminders []reminder
+	for rows.Next() {
+		var r reminder
+		if err := rows.Scan(&r.eventID, &r.userID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		reminders = append(reminders, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, r := range reminders {
+		var title, location string
+		var start time.Time
+		err := tx.QueryRowContext(ctx,
+			`SELECT title, location, start_datetime FROM events WHERE event_id = $1`,
+			r.eventID,
+		).Scan(&title, &location, &start)
+		if err != nil {
+			return 0, err
+		}
+
+		err = notifier.ToUser(tx, r.userID, notifier.Notification{
+			Kind:       notifier.EventReminder,
+			EntityType: "event",
+			EntityID:   r.eventID,
+			Data: map[string]interface{}{
+				"EventTitle": title,
+				"Location":   location,
+				"StartDate":  start.Format(notifier.DateFormat),
+				"StartsIn":   window.StartsIn,
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(reminders), nil
+}
+
+// completeFinishedEvents moves approved events that have ended to completed
+// through the lifecycle, which records the change in their status history
+func completeFinishedEvents(ctx context.Context) (int, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	completed, err := lifecycle.CompleteFinished(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return completed, nil
+}
+
+// requestEventFeedback asks attendees of recently completed events who
+// haven't left feedback to do so, once per event
+func requestEventFeedback(ctx context.Context) (int, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`INSERT INTO event_reminders (event_id, user_id, reminder_type)
+		 SELECT er.event_id, er.user_id, 'feedback'
+		 FROM event_registrations er
+		 JOIN events e ON er.event_id = e.event_id
+		 WHERE e.status = 'completed'
+		   AND e.end_datetime > $1
+		   AND er.registration_status IN ('confirmed', 'attended')
+		   AND NOT EXISTS (
+		       SELECT 1 FROM event_feedback f
+		       WHERE f.event_id = er.event_id AND f.user_id = er.user_id
+		   )
+		 ON CONFLICT (event_id, user_id, reminder_type) DO NOTHING
+		 RETURNING event_id, user_id`,
+		time.Now().Add(-feedbackWindow),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	type request struct{ eventID, userID int }
+	var requests []request
+	for rows.Next() {
+		var r request
+		if err := rows.Scan(&r.eventID, &r.userID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		requests = append(requests, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, r := range requests {
+		var title string
+		err := tx.QueryRowContext(ctx, `SELECT title FROM events WHERE event_id = $1`, r.eventID).Scan(&title)
+		if err != nil {
+			return 0, err
+		}
+
+		err = notifier.ToUser(tx, r.userID, notifier.Notification{
+			Kind:       notifier.FeedbackRequested,
+			EntityType: "event",
+			EntityID:   r.eventID,
+			Data:       map[string]interface{}{"EventTitle": title},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(requests), nil
+}
+
+// purgeExpiredTokens deletes password reset and email verification tokens
+// that can no longer be used
+func purgeExpiredTokens(ctx context.Context) (int, error) {
+	purged := 0
+	for _, query := range []string{
+		`DELETE FROM password_reset_tokens WHERE expires_at < CURRENT_TIMESTAMP`,
+		`DELETE FROM email_verification_tokens WHERE expires_at < CURRENT_TIMESTAMP`,
+	} {
+		result, err := database.DB.ExecContext(ctx, query)
+		if err != nil {
+			return purged, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		purged += int(n)
+	}
+	return purged, nil
+}
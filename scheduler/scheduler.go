@@ -0,0 +1,285 @@
+// Package scheduler runs periodic background jobs. Every run is recorded in
+// job_runs, and a Postgres advisory lock per job makes sure only one API
+// instance runs a given job at a time.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/listing"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// jobLockClass namespaces the advisory locks taken by jobs; the second lock
+// key is a hash of the job name
+const jobLockClass = 7241
+
+var (
+	// ErrUnknownJob is returned when triggering a job that isn't registered
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrJobRunning is returned when the job is already running elsewhere
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job is a unit of periodic work. Run reports how many items it processed.
+type Job struct {
+	Name        string
+	Description string
+	Interval    time.Duration
+	Run         func(ctx context.Context) (int, error)
+}
+
+// Scheduler runs registered jobs on their intervals
+type Scheduler struct {
+	mu   sync.RWMutex
+	jobs []*Job
+}
+
+// Default is the scheduler used by the application
+var Default = New()
+
+// New creates a scheduler with no jobs
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job to the scheduler
+func (s *Scheduler) Register(job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+}
+
+// Jobs returns the registered jobs in registration order
+func (s *Scheduler) Jobs() []*Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*Job(nil), s.jobs...)
+}
+
+// Job looks up a registered job by name
+func (s *Scheduler) Job(name string) (*Job, bool) {
+	for _, job := range s.Jobs() {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+// Start runs every job once and then on its interval until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.Jobs() {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.run(ctx, job, "schedule", nil); err != nil && err != ErrJobRunning {
+			slog.Error("Job failed", "job", job.Name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Trigger runs a job immediately on behalf of a user and returns the run
+func (s *Scheduler) Trigger(ctx context.Context, name string, userID int) (*models.JobRun, error) {
+	job, ok := s.Job(name)
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	return s.run(ctx, job, "manual", &userID)
+}
+
+// run executes a job while holding its advisory lock and records the run.
+// A failing job still returns its run, with the error recorded on it.
+func (s *Scheduler) run(ctx context.Context, job *Job, triggerType string, triggeredBy *int) (*models.JobRun, error) {
+	unlock, err := lockJob(ctx, job.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	run := &models.JobRun{
+		JobName:     job.Name,
+		TriggerType: triggerType,
+		TriggeredBy: triggeredBy,
+		Status:      "running",
+	}
+	err = database.DB.QueryRowContext(ctx,
+		`INSERT INTO job_runs (job_name, trigger_type, triggered_by)
+		 VALUES ($1, $2, $3)
+		 RETURNING run_id, started_at`,
+		job.Name, triggerType, triggeredBy,
+	).Scan(&run.RunID, &run.StartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	items, runErr := runSafely(ctx, job)
+
+	run.ItemsProcessed = items
+	run.Status = "succeeded"
+	if runErr != nil {
+		run.Status = "failed"
+		run.Error = runErr.Error()
+	}
+
+	var finishedAt time.Time
+	err = database.DB.QueryRow(
+		`UPDATE job_runs
+		 SET status = $1, items_processed = $2, error = $3, finished_at = CURRENT_TIMESTAMP
+		 WHERE run_id = $4
+		 RETURNING finished_at`,
+		run.Status, run.ItemsProcessed, run.Error, run.RunID,
+	).Scan(&finishedAt)
+	if err != nil {
+		return run, fmt.Errorf("failed to record job result: %w", err)
+	}
+	run.FinishedAt = &finishedAt
+
+	return run, runErr
+}
+
+// runSafely runs the job, turning a panic into an error so one broken job
+// can't take down the server
+func runSafely(ctx context.Context, job *Job) (items int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// lockJob takes the job's advisory lock on a dedicated connection, failing
+// with ErrJobRunning if another run holds it
+func lockJob(ctx context.Context, name string) (func(), error) {
+	conn, err := database.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	err = conn.QueryRowContext(ctx,
+		`SELECT pg_try_advisory_lock($1, hashtext($2))`, jobLockClass, name,
+	).Scan(&locked)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrJobRunning
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1, hashtext($2))`, jobLockClass, name)
+		conn.Close()
+	}, nil
+}
+
+// RecentRuns returns a page of the run history and the number of runs it
+// has in total. The page's filters may refer to the job_runs columns.
+func RecentRuns(page *listing.Page) ([]models.JobRun, int, error) {
+	total, err := page.Count(database.DB, "job_runs")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where, orderLimit, args := page.PageSQL()
+	rows, err := database.DB.Query(
+		`SELECT run_id, job_name, trigger_type, triggered_by, status, items_processed, error, started_at, finished_at,
+			`+page.CursorColumns()+`
+		 FROM job_runs`+where+orderLimit,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	runs := []models.JobRun{}
+	for rows.Next() {
+		var cursor listing.Cursor
+		run, err := scanRun(cursorScanner{rows, &cursor})
+		if err != nil {
+			return nil, 0, err
+		}
+		if !page.Add(cursor) {
+			break
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+// LastRun returns the most recent run of a job, or nil if it never ran
+func LastRun(jobName string) (*models.JobRun, error) {
+	row := database.DB.QueryRow(
+		`SELECT run_id, job_name, trigger_type, triggered_by, status, items_processed, error, started_at, finished_at
+		 FROM job_runs
+		 WHERE job_name = $1
+		 ORDER BY started_at DESC, run_id DESC
+		 LIMIT 1`,
+		jobName,
+	)
+
+	run, err := scanRun(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// cursorScanner scans a row's listing cursor after its other columns
+type cursorScanner struct {
+	rowScanner
+	cursor *listing.Cursor
+}
+
+func (s cursorScanner) Scan(dest ...interface{}) error {
+	return s.rowScanner.Scan(append(dest, &s.cursor.Value, &s.cursor.Key)...)
+}
+
+func scanRun(row rowScanner) (models.JobRun, error) {
+	var run models.JobRun
+	var triggeredBy sql.NullInt64
+	var finishedAt sql.NullTime
+
+	err := row.Scan(&run.RunID, &run.JobName, &run.TriggerType, &triggeredBy, &run.Status,
+		&run.ItemsProcessed, &run.Error, &run.StartedAt, &finishedAt)
+	if err != nil {
+		return run, err
+	}
+
+	if triggeredBy.Valid {
+		id := int(triggeredBy.Int64)
+		run.TriggeredBy = &id
+	}
+	run.FinishedAt = models.NullTime(finishedAt)
+	return run, nil
+}
@@ -0,0 +1,98 @@
+// Package storage keeps uploaded files. Files are stored under keys derived
+// from their content, so identical uploads share one object and a key's
+// content never changes.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+)
+
+// ErrNotFound is returned when no object exists under a key
+var ErrNotFound = errors.New("object not found")
+
+// Object is a stored file opened for reading. Body is an io.ReadSeeker when
+// the backend supports seeking.
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage stores and retrieves objects by key
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (*Object, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Default is the storage used by the application, configured by Init
+var Default Storage
+
+// Init configures the default storage from the application config
+func Init() error {
+	cfg := config.AppConfig
+
+	switch cfg.StorageDriver {
+	case "local":
+		local, err := NewLocalStorage(cfg.StorageLocalPath)
+		if err != nil {
+			return err
+		}
+		Default = local
+
+	case "s3":
+		if cfg.S3Bucket == "" || cfg.S3Endpoint == "" {
+			return fmt.Errorf("S3_BUCKET and S3_ENDPOINT must be set when STORAGE_DRIVER is s3")
+		}
+		Default = &S3Storage{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		}
+
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q (expected local or s3)", cfg.StorageDriver)
+	}
+
+	return nil
+}
+
+// keyPattern matches the keys produced by ContentKey
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{2}/[0-9a-f]{2}/[0-9a-f]{64}\.[a-z0-9]+$`)
+
+// ContentKey builds the key for content with the given SHA-256 hex digest.
+// The two leading directory levels keep any one directory small.
+func ContentKey(sha256Hex, ext string) string {
+	return sha256Hex[0:2] + "/" + sha256Hex[2:4] + "/" + sha256Hex + "." + ext
+}
+
+// ValidKey reports whether key is a well-formed content key. Keys from
+// requests must be checked before use so they can't escape the store.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// KeyDigest returns the SHA-256 digest embedded in a content key
+func KeyDigest(key string) string {
+	name := path.Base(key)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// URL returns the public URL an object is served from
+func URL(key string) string {
+	return strings.TrimRight(config.AppConfig.MediaBaseURL, "/") + "/media/" + key
+}
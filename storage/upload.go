@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+)
+
+var (
+	// ErrTooLarge is returned when an upload exceeds its size limit
+	ErrTooLarge = errors.New("file is too large")
+	// ErrUnsupportedType is returned when an upload's content isn't an allowed type
+	ErrUnsupportedType = errors.New("unsupported file type")
+)
+
+// ImageTypes maps the accepted image MIME types to their file extensions
+var ImageTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// VideoTypes maps the accepted video MIME types to their file extensions
+var VideoTypes = map[string]string{
+	"video/mp4":  "mp4",
+	"video/webm": "webm",
+}
+
+// Upload is a validated file that has been written to storage
+type Upload struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// Save validates an upload and stores it under its content key. The type is
+// sniffed from the content rather than trusted from the client, and must be
+// one of allowedTypes. maxBytes is looked up by the sniffed type.
+func Save(ctx context.Context, s Storage, r io.Reader, allowedTypes map[string]string, maxBytes func(contentType string) int64) (*Upload, error) {
+	// Sniff the type from the first bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+	limit := maxBytes(contentType)
+
+	// Spool to a temporary file while hashing, so the key is known before the
+	// object is stored and oversized uploads never reach the store
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(io.MultiReader(bytes.NewReader(head), r), limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if written > limit {
+		return nil, ErrTooLarge
+	}
+
+	upload := &Upload{
+		Key:         ContentKey(hex.EncodeToString(hash.Sum(nil)), ext),
+		ContentType: contentType,
+		Size:        written,
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := put(ctx, s, upload, tmp); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// SaveBytes stores content that is already in memory and of a known type,
+// such as a processed image, under its content key
+func SaveBytes(ctx context.Context, s Storage, data []byte, contentType string) (*Upload, error) {
+	ext, ok := ImageTypes[contentType]
+	if !ok {
+		ext, ok = VideoTypes[contentType]
+	}
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	sum := sha256.Sum256(data)
+	upload := &Upload{
+		Key:         ContentKey(hex.EncodeToString(sum[:]), ext),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+
+	if err := put(ctx, s, upload, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// put writes an upload's content unless identical content, which would have
+// the same key, is already stored
+func put(ctx context.Context, s Storage, upload *Upload, r io.Reader) error {
+	exists, err := s.Exists(ctx, upload.Key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	return s.Put(ctx, upload.Key, r, upload.Size, upload.ContentType)
+}
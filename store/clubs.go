@@ -0,0 +1,104 @@
+package store
+
+import (
+	"database/sql"
+
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// ClubStore reads and creates clubs and changes their memberships
+type ClubStore interface {
+	// Get returns a club with its member and upcoming event counts
+	Get(clubID int) (*models.Club, error)
+	// Create adds a club and returns its ID
+	Create(req models.CreateClubRequest) (int, error)
+	// SetActive opens or closes a club
+	SetActive(clubID int, active bool) error
+	// Join makes a user an active member of a club, rejoining if they left
+	Join(userID, clubID int) error
+	// Leave deactivates a user's membership of a club
+	Leave(userID, clubID int) error
+	// IsModerator reports whether a user is assigned as a moderator of a club
+	IsModerator(userID, clubID int) (bool, error)
+}
+
+type clubStore struct {
+	db database.Querier
+}
+
+func (s *clubStore) Get(clubID int) (*models.Club, error) {
+	var club models.Club
+	var description, logoURL, coverImageURL, email sql.NullString
+	var foundedDate sql.NullTime
+
+	err := s.db.QueryRow(
+		`SELECT
+			c.club_id, c.club_name, c.club_code, c.description, c.logo_url, c.cover_image_url, c.founded_date, c.email, c.is_active, c.created_at, c.updated_at,
+			COUNT(DISTINCT cm.user_id) as member_count,
+			COUNT(DISTINCT e.event_id) FILTER (WHERE e.status = 'approved') as upcoming_events
+		 FROM clubs c
+		 LEFT JOIN club_members cm ON c.club_id = cm.club_id AND cm.is_active = TRUE
+		 LEFT JOIN events e ON c.club_id = e.club_id
+		 WHERE c.club_id = $1
+		 GROUP BY c.club_id`,
+		clubID,
+	).Scan(&club.ClubID, &club.ClubName, &club.ClubCode, &description, &logoURL, &coverImageURL, &foundedDate, &email,
+		&club.IsActive, &club.CreatedAt, &club.UpdatedAt, &club.MemberCount, &club.UpcomingEvents)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	club.Description = models.NullString(description)
+	club.LogoURL = models.NullString(logoURL)
+	club.CoverImageURL = models.NullString(coverImageURL)
+	club.FoundedDate = models.NullTime(foundedDate)
+	club.Email = models.NullString(email)
+	return &club, nil
+}
+
+func (s *clubStore) Create(req models.CreateClubRequest) (int, error) {
+	var clubID int
+	err := s.db.QueryRow(
+		`INSERT INTO clubs (club_name, club_code, description, logo_url, cover_image_url, founded_date, email)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING club_id`,
+		req.ClubName, req.ClubCode, req.Description, req.LogoURL, req.CoverImageURL, req.FoundedDate, req.Email,
+	).Scan(&clubID)
+	return clubID, err
+}
+
+func (s *clubStore) SetActive(clubID int, active bool) error {
+	_, err := s.db.Exec(
+		`UPDATE clubs SET is_active = $2, updated_at = CURRENT_TIMESTAMP WHERE club_id = $1`,
+		clubID, active,
+	)
+	return err
+}
+
+func (s *clubStore) Join(userID, clubID int) error {
+	_, err := s.db.Exec(
+		`INSERT INTO club_members (user_id, club_id, role)
+		 VALUES ($1, $2, 'member')
+		 ON CONFLICT (user_id, club_id) DO UPDATE SET is_active = TRUE, joined_date = CURRENT_TIMESTAMP`,
+		userID, clubID,
+	)
+	return err
+}
+
+func (s *clubStore) Leave(userID, clubID int) error {
+	_, err := s.db.Exec(
+		`UPDATE club_members SET is_active = FALSE WHERE user_id = $1 AND club_id = $2`,
+		userID, clubID,
+	)
+	return err
+}
+
+func (s *clubStore) IsModerator(userID, clubID int) (bool, error) {
+	var isModerator bool
+	err := s.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM club_moderators WHERE user_id = $1 AND club_id = $2)`,
+		userID, clubID,
+	).Scan(&isModerator)
+	return isModerator, err
+}
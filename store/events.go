@@ -0,0 +1,75 @@
+package store
+
+import (
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// EventStore reads events and records feedback on them
+type EventStore interface {
+	// Get returns an event with its club, creator, registration counts and
+	// average rating
+	Get(eventID int) (*models.Event, error)
+	// SaveFeedback records a user's feedback on an event, replacing any they
+	// gave before
+	SaveFeedback(eventID, userID int, feedback models.SubmitFeedbackRequest) error
+	// MarkFeedbackSubmitted flags a user's registration as having feedback
+	MarkFeedbackSubmitted(eventID, userID int) error
+}
+
+type eventStore struct {
+	db database.Querier
+}
+
+func (s *eventStore) Get(eventID int) (*models.Event, error) {
+	var event models.Event
+	err := s.db.QueryRow(
+		`SELECT
+			e.event_id, e.title, e.description, e.event_type, e.location,
+			e.start_datetime, e.end_datetime, e.registration_deadline, e.capacity, e.banner_image_url, e.status,
+			c.club_name, c.club_code,
+			u.first_name || ' ' || u.last_name as created_by_name,
+			COUNT(er.registration_id) FILTER (WHERE er.registration_status = 'confirmed') as confirmed_count,
+			COUNT(er.registration_id) FILTER (WHERE er.registration_status = 'waitlist') as waitlist_count,
+			AVG(ef.rating) as average_rating,
+			COUNT(ef.feedback_id) as feedback_count,
+			e.club_id, e.created_by
+		 FROM events e
+		 JOIN clubs c ON e.club_id = c.club_id
+		 JOIN users u ON e.created_by = u.user_id
+		 LEFT JOIN event_registrations er ON e.event_id = er.event_id
+		 LEFT JOIN event_feedback ef ON e.event_id = ef.event_id
+		 WHERE e.event_id = $1
+		 GROUP BY e.event_id, c.club_id, u.user_id`,
+		eventID,
+	).Scan(&event.EventID, &event.Title, &event.Description, &event.EventType, &event.Location,
+		&event.StartDatetime, &event.EndDatetime, &event.RegistrationDeadline, &event.Capacity, &event.BannerImageURL, &event.Status,
+		&event.ClubName, &event.ClubCode, &event.CreatedByName,
+		&event.ConfirmedCount, &event.WaitlistCount, &event.AverageRating, &event.FeedbackCount,
+		&event.ClubID, &event.CreatedBy)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &event, nil
+}
+
+func (s *eventStore) SaveFeedback(eventID, userID int, feedback models.SubmitFeedbackRequest) error {
+	_, err := s.db.Exec(
+		`INSERT INTO event_feedback (event_id, user_id, rating, comment)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (event_id, user_id) DO UPDATE
+		 SET rating = EXCLUDED.rating, comment = EXCLUDED.comment, submitted_at = CURRENT_TIMESTAMP`,
+		eventID, userID, feedback.Rating, feedback.Comment,
+	)
+	return err
+}
+
+func (s *eventStore) MarkFeedbackSubmitted(eventID, userID int) error {
+	_, err := s.db.Exec(
+		`UPDATE event_registrations
+		 SET feedback_submitted = TRUE
+		 WHERE event_id = $1 AND user_id = $2`,
+		eventID, userID,
+	)
+	return err
+}
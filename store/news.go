@@ -0,0 +1,94 @@
+package store
+
+import (
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// NewsStore reads and creates news posts and their media
+type NewsStore interface {
+	// Get returns a news post in any status, with its club and author
+	Get(newsID int) (*models.News, error)
+	// Create adds a news post by a user, pending review, and returns its ID
+	Create(userID int, req models.CreateNewsRequest) (int, error)
+	// Media returns the media attached to a news post in display order
+	Media(newsID int) ([]models.NewsMedia, error)
+	// AddMedia attaches media hosted elsewhere to a news post and returns its
+	// ID
+	AddMedia(newsID int, item models.NewsMedia) (int, error)
+}
+
+type newsStore struct {
+	db database.Querier
+}
+
+func (s *newsStore) Get(newsID int) (*models.News, error) {
+	var news models.News
+	err := s.db.QueryRow(
+		`SELECT
+			n.news_id, n.title, n.content, n.category, n.is_featured, n.status, n.published_at, n.created_at, n.updated_at,
+			c.club_name, c.club_code,
+			u.first_name || ' ' || u.last_name as author,
+			n.club_id, n.created_by
+		 FROM news n
+		 JOIN clubs c ON n.club_id = c.club_id
+		 JOIN users u ON n.created_by = u.user_id
+		 WHERE n.news_id = $1`,
+		newsID,
+	).Scan(&news.NewsID, &news.Title, &news.Content, &news.Category, &news.IsFeatured, &news.Status, &news.PublishedAt, &news.CreatedAt, &news.UpdatedAt,
+		&news.ClubName, &news.ClubCode, &news.Author, &news.ClubID, &news.CreatedBy)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &news, nil
+}
+
+func (s *newsStore) Create(userID int, req models.CreateNewsRequest) (int, error) {
+	var newsID int
+	err := s.db.QueryRow(
+		`INSERT INTO news (club_id, created_by, title, content, category, is_featured)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING news_id`,
+		req.ClubID, userID, req.Title, req.Content, req.Category, req.IsFeatured,
+	).Scan(&newsID)
+	return newsID, err
+}
+
+func (s *newsStore) Media(newsID int) ([]models.NewsMedia, error) {
+	rows, err := s.db.Query(
+		`SELECT media_id, media_type, media_url, caption, display_order, width, height, renditions, uploaded_at
+		 FROM news_media
+		 WHERE news_id = $1
+		 ORDER BY display_order`,
+		newsID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []models.NewsMedia
+	for rows.Next() {
+		var item models.NewsMedia
+		err := rows.Scan(&item.MediaID, &item.MediaType, &item.MediaURL, &item.Caption, &item.DisplayOrder,
+			&item.Width, &item.Height, &item.Renditions, &item.UploadedAt)
+		if err != nil {
+			return nil, err
+		}
+		item.NewsID = newsID
+		item.Images = models.NewImageSet(item.MediaURL, item.Width, item.Height, item.Renditions)
+		media = append(media, item)
+	}
+	return media, rows.Err()
+}
+
+func (s *newsStore) AddMedia(newsID int, item models.NewsMedia) (int, error) {
+	var mediaID int
+	err := s.db.QueryRow(
+		`INSERT INTO news_media (news_id, media_type, media_url, caption, display_order)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING media_id`,
+		newsID, item.MediaType, item.MediaURL, item.Caption, item.DisplayOrder,
+	).Scan(&mediaID)
+	return mediaID, err
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+)
+
+// NotificationStore reads and changes a user's notifications. Every method
+// is scoped to the user, so one user can't touch another's notifications.
+type NotificationStore interface {
+	// UnreadCount counts a user's unread notifications
+	UnreadCount(userID int) (int, error)
+	// MarkRead marks one of a user's notifications as read
+	MarkRead(userID, notificationID int) error
+	// MarkAllRead marks all of a user's notifications as read
+	MarkAllRead(userID int) error
+	// Delete deletes one of a user's notifications
+	Delete(userID, notificationID int) error
+}
+
+type notificationStore struct {
+	db database.Querier
+}
+
+func (s *notificationStore) UnreadCount(userID int) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+func (s *notificationStore) MarkRead(userID, notificationID int) error {
+	_, err := s.db.Exec(
+		`UPDATE notifications SET is_read = TRUE WHERE notification_id = $1 AND user_id = $2`,
+		notificationID, userID,
+	)
+	return err
+}
+
+func (s *notificationStore) MarkAllRead(userID int) error {
+	_, err := s.db.Exec(
+		`UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND is_read = FALSE`,
+		userID,
+	)
+	return err
+}
+
+func (s *notificationStore) Delete(userID, notificationID int) error {
+	_, err := s.db.Exec(
+		`DELETE FROM notifications WHERE notification_id = $1 AND user_id = $2`,
+		notificationID, userID,
+	)
+	return err
+}
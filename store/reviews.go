@@ -0,0 +1,36 @@
+package store
+
+import (
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// ReviewStore reads the review history of events and news posts
+type ReviewStore interface {
+	// Latest returns the most recent approval or rejection of an event or
+	// news post, or ErrNotFound if it hasn't been reviewed
+	Latest(entityType string, entityID int) (*models.ContentReview, error)
+}
+
+type reviewStore struct {
+	db database.Querier
+}
+
+func (s *reviewStore) Latest(entityType string, entityID int) (*models.ContentReview, error) {
+	var review models.ContentReview
+	err := s.db.QueryRow(
+		`SELECT r.review_id, r.entity_type, r.entity_id, r.action, r.user_id,
+			COALESCE(u.first_name || ' ' || u.last_name, ''), r.reason, r.comments, r.created_at
+		 FROM content_reviews r
+		 LEFT JOIN users u ON r.user_id = u.user_id
+		 WHERE r.entity_type = $1 AND r.entity_id = $2 AND r.action IN ('approved', 'rejected')
+		 ORDER BY r.created_at DESC, r.review_id DESC
+		 LIMIT 1`,
+		entityType, entityID,
+	).Scan(&review.ReviewID, &review.EntityType, &review.EntityID, &review.Action, &review.UserID,
+		&review.UserName, &review.Reason, &review.Comments, &review.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &review, nil
+}
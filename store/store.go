@@ -0,0 +1,76 @@
+// Package store is the data access layer. Each aggregate has a store
+// interface with a PostgreSQL implementation, so handlers can be given fakes
+// in tests, and a set of stores can be bound to one transaction with WithTx.
+package store
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+)
+
+// ErrNotFound is returned when the requested row doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// Stores bundles the store of each aggregate
+type Stores struct {
+	Users         UserStore
+	Clubs         ClubStore
+	Events        EventStore
+	News          NewsStore
+	Notifications NotificationStore
+	Reviews       ReviewStore
+
+	// db starts transactions; nil for stores already bound to one
+	db *sql.DB
+}
+
+// New returns stores backed by the connection pool
+func New(db *sql.DB) *Stores {
+	stores := bind(db)
+	stores.db = db
+	return stores
+}
+
+// bind returns stores that run their queries on q
+func bind(q database.Querier) *Stores {
+	return &Stores{
+		Users:         &userStore{q},
+		Clubs:         &clubStore{q},
+		Events:        &eventStore{q},
+		News:          &newsStore{q},
+		Notifications: &notificationStore{q},
+		Reviews:       &reviewStore{q},
+	}
+}
+
+// WithTx runs fn with stores bound to a new transaction, committing it when
+// fn returns nil and rolling it back otherwise. Stores that can't begin a
+// transaction, because they are already in one or are fakes, run fn on
+// themselves.
+func (s *Stores) WithTx(fn func(tx *Stores) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+
+	sqlTx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	tx := &database.Tx{Tx: sqlTx}
+	defer tx.Rollback()
+
+	if err := fn(bind(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// notFound maps sql.ErrNoRows to ErrNotFound
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
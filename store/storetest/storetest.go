@@ -0,0 +1,494 @@
+// Package storetest has in-memory fakes of the stores, for testing handlers
+// without a database. Tests seed the fakes' maps, pass Fakes.Stores to
+// handlers.UseStores and inspect the maps afterwards. Setting Err on a fake
+// makes every one of its methods fail with it.
+package storetest
+
+import (
+	"sync"
+
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
+)
+
+// Fakes is a set of fake stores
+type Fakes struct {
+	Users         *Users
+	Clubs         *Clubs
+	Events        *Events
+	News          *News
+	Notifications *Notifications
+	Reviews       *Reviews
+}
+
+// New returns empty fakes
+func New() *Fakes {
+	return &Fakes{
+		Users: &Users{
+			Users:          map[int]*models.User{},
+			PasswordHashes: map[int]string{},
+			ResetTokens:    map[string]int{},
+			RevokedFor:     map[int]int{},
+		},
+		Clubs: &Clubs{
+			Clubs:      map[int]*models.Club{},
+			Members:    map[Membership]bool{},
+			Moderators: map[Membership]bool{},
+		},
+		Events: &Events{
+			Events:            map[int]*models.Event{},
+			Feedback:          map[Registration]models.SubmitFeedbackRequest{},
+			FeedbackSubmitted: map[Registration]bool{},
+		},
+		News: &News{
+			News:     map[int]*models.News{},
+			Attached: map[int][]models.NewsMedia{},
+		},
+		Notifications: &Notifications{
+			Notifications: map[int]*models.Notification{},
+		},
+		Reviews: &Reviews{},
+	}
+}
+
+// Stores returns the fakes as stores. WithTx runs its function on them
+// directly, so a failed step doesn't undo the ones before it.
+func (f *Fakes) Stores() *store.Stores {
+	return &store.Stores{
+		Users:         f.Users,
+		Clubs:         f.Clubs,
+		Events:        f.Events,
+		News:          f.News,
+		Notifications: f.Notifications,
+		Reviews:       f.Reviews,
+	}
+}
+
+// Activity is an entry a fake UserStore logged
+type Activity struct {
+	UserID     int
+	Action     string
+	EntityType string
+	EntityID   int
+	Details    interface{}
+}
+
+// Users is a fake store.UserStore
+type Users struct {
+	mu  sync.Mutex
+	Err error
+
+	Users          map[int]*models.User
+	PasswordHashes map[int]string
+	// ResetTokens maps the hash of each usable reset token to its user
+	ResetTokens map[string]int
+	// RevokedFor counts the times each user's sessions were revoked
+	RevokedFor map[int]int
+	Activity   []Activity
+}
+
+func (s *Users) Get(userID int) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	user, ok := s.Users[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (s *Users) UpdateProfile(userID int, req models.UpdateProfileRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	user, ok := s.Users[userID]
+	if !ok {
+		return nil
+	}
+	for _, field := range []struct {
+		value string
+		dst   *string
+	}{
+		{req.FirstName, &user.FirstName},
+		{req.LastName, &user.LastName},
+		{req.Phone, &user.Phone},
+		{req.ProfilePictureURL, &user.ProfilePictureURL},
+	} {
+		if field.value != "" {
+			*field.dst = field.value
+		}
+	}
+	return nil
+}
+
+func (s *Users) ClaimResetToken(tokenHash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	userID, ok := s.ResetTokens[tokenHash]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	return userID, nil
+}
+
+func (s *Users) SetPassword(userID int, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.PasswordHashes[userID] = passwordHash
+	return nil
+}
+
+func (s *Users) UseResetTokens(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	for hash, owner := range s.ResetTokens {
+		if owner == userID {
+			delete(s.ResetTokens, hash)
+		}
+	}
+	return nil
+}
+
+func (s *Users) RevokeSessions(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.RevokedFor[userID]++
+	return nil
+}
+
+func (s *Users) LogActivity(userID int, action, entityType string, entityID int, details interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.Activity = append(s.Activity, Activity{userID, action, entityType, entityID, details})
+	return nil
+}
+
+// Membership is a user's place in a club
+type Membership struct {
+	UserID int
+	ClubID int
+}
+
+// Clubs is a fake store.ClubStore
+type Clubs struct {
+	mu  sync.Mutex
+	Err error
+
+	Clubs map[int]*models.Club
+	// Members records whether each membership is active
+	Members    map[Membership]bool
+	Moderators map[Membership]bool
+}
+
+func (s *Clubs) Get(clubID int) (*models.Club, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	club, ok := s.Clubs[clubID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *club
+	copied.MemberCount = 0
+	for membership, active := range s.Members {
+		if membership.ClubID == clubID && active {
+			copied.MemberCount++
+		}
+	}
+	return &copied, nil
+}
+
+func (s *Clubs) Create(req models.CreateClubRequest) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	clubID := len(s.Clubs) + 1
+	s.Clubs[clubID] = &models.Club{
+		ClubID:   clubID,
+		ClubName: req.ClubName,
+		ClubCode: req.ClubCode,
+		IsActive: true,
+	}
+	return clubID, nil
+}
+
+func (s *Clubs) SetActive(clubID int, active bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if club, ok := s.Clubs[clubID]; ok {
+		club.IsActive = active
+	}
+	return nil
+}
+
+func (s *Clubs) Join(userID, clubID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.Members[Membership{userID, clubID}] = true
+	return nil
+}
+
+func (s *Clubs) Leave(userID, clubID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if _, ok := s.Members[Membership{userID, clubID}]; ok {
+		s.Members[Membership{userID, clubID}] = false
+	}
+	return nil
+}
+
+func (s *Clubs) IsModerator(userID, clubID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return false, s.Err
+	}
+	return s.Moderators[Membership{userID, clubID}], nil
+}
+
+// Registration names a user's registration for an event
+type Registration struct {
+	EventID int
+	UserID  int
+}
+
+// Events is a fake store.EventStore
+type Events struct {
+	mu  sync.Mutex
+	Err error
+
+	Events            map[int]*models.Event
+	Feedback          map[Registration]models.SubmitFeedbackRequest
+	FeedbackSubmitted map[Registration]bool
+}
+
+func (s *Events) Get(eventID int) (*models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	event, ok := s.Events[eventID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *event
+	return &copied, nil
+}
+
+func (s *Events) SaveFeedback(eventID, userID int, feedback models.SubmitFeedbackRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.Feedback[Registration{eventID, userID}] = feedback
+	return nil
+}
+
+func (s *Events) MarkFeedbackSubmitted(eventID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.FeedbackSubmitted[Registration{eventID, userID}] = true
+	return nil
+}
+
+// News is a fake store.NewsStore
+type News struct {
+	mu  sync.Mutex
+	Err error
+
+	News map[int]*models.News
+	// Attached holds each post's media in display order
+	Attached map[int][]models.NewsMedia
+}
+
+func (s *News) Get(newsID int) (*models.News, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	news, ok := s.News[newsID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *news
+	return &copied, nil
+}
+
+func (s *News) Create(userID int, req models.CreateNewsRequest) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	newsID := len(s.News) + 1
+	s.News[newsID] = &models.News{
+		NewsID:     newsID,
+		ClubID:     req.ClubID,
+		CreatedBy:  userID,
+		Title:      req.Title,
+		Content:    req.Content,
+		Category:   req.Category,
+		IsFeatured: req.IsFeatured,
+		Status:     "pending",
+	}
+	return newsID, nil
+}
+
+func (s *News) Media(newsID int) ([]models.NewsMedia, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return append([]models.NewsMedia(nil), s.Attached[newsID]...), nil
+}
+
+func (s *News) AddMedia(newsID int, item models.NewsMedia) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	mediaID := 1
+	for _, media := range s.Attached {
+		mediaID += len(media)
+	}
+	item.MediaID, item.NewsID = mediaID, newsID
+	s.Attached[newsID] = append(s.Attached[newsID], item)
+	return mediaID, nil
+}
+
+// Notifications is a fake store.NotificationStore
+type Notifications struct {
+	mu  sync.Mutex
+	Err error
+
+	Notifications map[int]*models.Notification
+}
+
+func (s *Notifications) UnreadCount(userID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	count := 0
+	for _, notif := range s.Notifications {
+		if notif.UserID == userID && !notif.IsRead {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Notifications) MarkRead(userID, notificationID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if notif, ok := s.Notifications[notificationID]; ok && notif.UserID == userID {
+		notif.IsRead = true
+	}
+	return nil
+}
+
+func (s *Notifications) MarkAllRead(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	for _, notif := range s.Notifications {
+		if notif.UserID == userID {
+			notif.IsRead = true
+		}
+	}
+	return nil
+}
+
+func (s *Notifications) Delete(userID, notificationID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if notif, ok := s.Notifications[notificationID]; ok && notif.UserID == userID {
+		delete(s.Notifications, notificationID)
+	}
+	return nil
+}
+
+// Reviews is a fake store.ReviewStore
+type Reviews struct {
+	mu  sync.Mutex
+	Err error
+
+	// Reviews is the review history, oldest first
+	Reviews []models.ContentReview
+}
+
+func (s *Reviews) Latest(entityType string, entityID int) (*models.ContentReview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	for i := len(s.Reviews) - 1; i >= 0; i-- {
+		review := s.Reviews[i]
+		if review.EntityType == entityType && review.EntityID == entityID &&
+			(review.Action == "approved" || review.Action == "rejected") {
+			return &review, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+var (
+	_ store.UserStore         = (*Users)(nil)
+	_ store.ClubStore         = (*Clubs)(nil)
+	_ store.EventStore        = (*Events)(nil)
+	_ store.NewsStore         = (*News)(nil)
+	_ store.NotificationStore = (*Notifications)(nil)
+	_ store.ReviewStore       = (*Reviews)(nil)
+)
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// UserStore reads and changes users, their credentials and sessions, and
+// records their activity
+type UserStore interface {
+	// Get returns a user's profile
+	Get(userID int) (*models.User, error)
+	// UpdateProfile changes the non-empty fields of req
+	UpdateProfile(userID int, req models.UpdateProfileRequest) error
+	// ClaimResetToken returns the user of an unused, unexpired password
+	// reset token, locking the token until the transaction ends
+	ClaimResetToken(tokenHash string) (int, error)
+	// SetPassword replaces a user's password hash
+	SetPassword(userID int, passwordHash string) error
+	// UseResetTokens marks every outstanding reset token of a user as used
+	UseResetTokens(userID int) error
+	// RevokeSessions revokes every active session of a user
+	RevokeSessions(userID int) error
+	// LogActivity adds an entry to the activity log
+	LogActivity(userID int, action, entityType string, entityID int, details interface{}) error
+}
+
+type userStore struct {
+	db database.Querier
+}
+
+func (s *userStore) Get(userID int) (*models.User, error) {
+	var user models.User
+	err := s.db.QueryRow(
+		`SELECT user_id, student_id, email, first_name, last_name, role, phone, profile_picture_url, is_active, email_verified, created_at, updated_at
+		 FROM users
+		 WHERE user_id = $1`,
+		userID,
+	).Scan(&user.UserID, &user.StudentID, &user.Email, &user.FirstName, &user.LastName, &user.Role, &user.Phone,
+		&user.ProfilePictureURL, &user.IsActive, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (s *userStore) UpdateProfile(userID int, req models.UpdateProfileRequest) error {
+	_, err := s.db.Exec(
+		`UPDATE users
+		 SET first_name = COALESCE(NULLIF($1, ''), first_name),
+		     last_name = COALESCE(NULLIF($2, ''), last_name),
+		     phone = COALESCE(NULLIF($3, ''), phone),
+		     profile_picture_url = COALESCE(NULLIF($4, ''), profile_picture_url),
+		     updated_at = CURRENT_TIMESTAMP
+		 WHERE user_id = $5`,
+		req.FirstName, req.LastName, req.Phone, req.ProfilePictureURL, userID,
+	)
+	return err
+}
+
+func (s *userStore) ClaimResetToken(tokenHash string) (int, error) {
+	var userID int
+	err := s.db.QueryRow(
+		`SELECT user_id FROM password_reset_tokens
+		 WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP AND is_used = FALSE
+		 FOR UPDATE`,
+		tokenHash,
+	).Scan(&userID)
+	return userID, notFound(err)
+}
+
+func (s *userStore) SetPassword(userID int, passwordHash string) error {
+	_, err := s.db.Exec(
+		`UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2`,
+		passwordHash, userID,
+	)
+	return err
+}
+
+func (s *userStore) UseResetTokens(userID int) error {
+	_, err := s.db.Exec(
+		`UPDATE password_reset_tokens SET is_used = TRUE WHERE user_id = $1 AND is_used = FALSE`,
+		userID,
+	)
+	return err
+}
+
+func (s *userStore) RevokeSessions(userID int) error {
+	return RevokeSessions(s.db, userID)
+}
+
+func (s *userStore) LogActivity(userID int, action, entityType string, entityID int, details interface{}) error {
+	return LogActivity(s.db, userID, action, entityType, entityID, details)
+}
+
+// RevokeSessions revokes every active session of a user using the given
+// executor, for code that doesn't go through a UserStore yet
+func RevokeSessions(exec database.Execer, userID int) error {
+	_, err := exec.Exec(
+		`UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	return err
+}
+
+// LogActivity writes an activity log entry using the given executor, for
+// code that doesn't go through a UserStore yet
+func LogActivity(exec database.Execer, userID int, action, entityType string, entityID int, details interface{}) error {
+	var detailsJSON string
+	if details != nil {
+		jsonBytes, _ := json.Marshal(details)
+		detailsJSON = string(jsonBytes)
+	}
+
+	_, err := exec.Exec(
+		`INSERT INTO activity_log (user_id, action, entity_type, entity_id, details)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		userID, action, entityType, entityID, detailsJSON,
+	)
+	return err
+}
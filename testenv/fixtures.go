@@ -0,0 +1,177 @@
+package testenv
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nub-clubs-connect/nub_admin_api/lifecycle"
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// Password is the password of every fixture user
+const Password = "fixture-password"
+
+// FixtureUser is a seeded user
+type FixtureUser struct {
+	UserID    int
+	StudentID string
+	Email     string
+}
+
+// Fixtures are the rows seeded into a fresh database: a user per role, a
+// club moderated by Moderator with Member as a member, its events in each
+// review state, and its news
+type Fixtures struct {
+	Student   FixtureUser // belongs to no club
+	Member    FixtureUser // member of Club
+	Moderator FixtureUser // moderates Club
+	Admin     FixtureUser
+
+	ClubID      int
+	OtherClubID int // a club Moderator doesn't moderate
+
+	UpcomingEventID int // approved, starts tomorrow, capacity 2
+	PendingEventID  int // awaiting review
+	PastEventID     int // completed last week, Member attended
+
+	PublishedNewsID int
+	PendingNewsID   int
+}
+
+// passwordHash is computed once; bcrypt is deliberately slow
+var passwordHash string
+
+func seed(db *sql.DB) (Fixtures, error) {
+	var f Fixtures
+
+	if passwordHash == "" {
+		hash, err := utils.HashPassword(Password)
+		if err != nil {
+			return f, err
+		}
+		passwordHash = hash
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return f, err
+	}
+	defer tx.Rollback()
+
+	users := []struct {
+		user *FixtureUser
+		role string
+		name string
+	}{
+		{&f.Student, "student", "student"},
+		{&f.Member, "student", "member"},
+		{&f.Moderator, "club_moderator", "moderator"},
+		{&f.Admin, "system_admin", "admin"},
+	}
+	for i, u := range users {
+		u.user.StudentID = "2024-1-60-00" + string(rune('1'+i))
+		u.user.Email = u.name + "@nub.ac.bd"
+		err := tx.QueryRow(
+			`INSERT INTO users (student_id, email, password_hash, first_name, last_name, role, email_verified, email_verified_at)
+			 VALUES ($1, $2, $3, $4, 'Fixture', $5, TRUE, CURRENT_TIMESTAMP)
+			 RETURNING user_id`,
+			u.user.StudentID, u.user.Email, passwordHash, u.name, u.role,
+		).Scan(&u.user.UserID)
+		if err != nil {
+			return f, err
+		}
+	}
+
+	clubs := []struct {
+		id         *int
+		name, code string
+	}{
+		{&f.ClubID, "Programming Club", "NUBPC"},
+		{&f.OtherClubID, "Debate Club", "NUBDC"},
+	}
+	for _, club := range clubs {
+		err := tx.QueryRow(
+			`INSERT INTO clubs (club_name, club_code, description, email)
+			 VALUES ($1, $2, $1 || ' fixture', lower($2) || '@nub.ac.bd')
+			 RETURNING club_id`,
+			club.name, club.code,
+		).Scan(club.id)
+		if err != nil {
+			return f, err
+		}
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO club_moderators (user_id, club_id) VALUES ($1, $2)`,
+		f.Moderator.UserID, f.ClubID,
+	)
+	if err != nil {
+		return f, err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO club_members (user_id, club_id, role) VALUES ($1, $3, 'member'), ($2, $3, 'member')`,
+		f.Moderator.UserID, f.Member.UserID, f.ClubID,
+	)
+	if err != nil {
+		return f, err
+	}
+
+	now := time.Now().Truncate(time.Second)
+	events := []struct {
+		id     *int
+		title  string
+		start  time.Time
+		status string
+	}{
+		{&f.UpcomingEventID, "Go Workshop", now.Add(24 * time.Hour), lifecycle.Approved},
+		{&f.PendingEventID, "Hackathon", now.Add(14 * 24 * time.Hour), lifecycle.Pending},
+		{&f.PastEventID, "Orientation", now.Add(-7 * 24 * time.Hour), lifecycle.Completed},
+	}
+	for _, event := range events {
+		err := tx.QueryRow(
+			`INSERT INTO events (club_id, created_by, title, description, event_type, location,
+				start_datetime, end_datetime, capacity, status)
+			 VALUES ($1, $2, $3, $3 || ' fixture', 'workshop', 'Room 101', $4, $5, 2, $6)
+			 RETURNING event_id`,
+			f.ClubID, f.Moderator.UserID, event.title, event.start, event.start.Add(2*time.Hour), event.status,
+		).Scan(event.id)
+		if err != nil {
+			return f, err
+		}
+		if err := lifecycle.Record(tx, *event.id, nil, event.status, &f.Moderator.UserID, "Fixture"); err != nil {
+			return f, err
+		}
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO event_registrations (event_id, user_id, registration_status, attendance_marked)
+		 VALUES ($1, $2, 'attended', TRUE)`,
+		f.PastEventID, f.Member.UserID,
+	)
+	if err != nil {
+		return f, err
+	}
+
+	news := []struct {
+		id     *int
+		title  string
+		status string
+	}{
+		{&f.PublishedNewsID, "Programming Club wins regional contest", "published"},
+		{&f.PendingNewsID, "New semester plans", "pending"},
+	}
+	for _, item := range news {
+		err := tx.QueryRow(
+			`INSERT INTO news (club_id, created_by, title, content, category, status, published_at)
+			 VALUES ($1, $2, $3, $3 || ' fixture', 'update', $4,
+				CASE WHEN $4 = 'published' THEN CURRENT_TIMESTAMP END)
+			 RETURNING news_id`,
+			f.ClubID, f.Moderator.UserID, item.title, item.status,
+		).Scan(item.id)
+		if err != nil {
+			return f, err
+		}
+	}
+
+	return f, tx.Commit()
+}
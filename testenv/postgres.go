@@ -0,0 +1,141 @@
+package testenv
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nub-clubs-connect/nub_admin_api/utils"
+)
+
+// postgres is a database created for one test run
+type postgres struct {
+	dsn  string
+	stop func() error
+}
+
+// startPostgres provides an empty database. With TEST_DATABASE_URL set it
+// creates a database on that server; otherwise it runs a private server from
+// the PostgreSQL binaries in PG_BIN, on the PATH or in the usual install
+// locations.
+func startPostgres() (*postgres, error) {
+	if serverURL := os.Getenv("TEST_DATABASE_URL"); serverURL != "" {
+		return createDatabase(serverURL)
+	}
+	return startServer()
+}
+
+// createDatabase creates a uniquely named database on an existing server and
+// drops it when stopped
+func createDatabase(serverURL string) (*postgres, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return nil, fmt.Errorf("TEST_DATABASE_URL must be a postgres:// URL")
+	}
+
+	random, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, err
+	}
+	name := "nub_test_" + utils.HashToken(random)[:12]
+
+	admin, err := sql.Open("postgres", serverURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := admin.Exec(`CREATE DATABASE ` + name); err != nil {
+		admin.Close()
+		return nil, fmt.Errorf("failed to create test database: %w", err)
+	}
+
+	u.Path = "/" + name
+	return &postgres{
+		dsn: u.String(),
+		stop: func() error {
+			defer admin.Close()
+			_, err := admin.Exec(`DROP DATABASE IF EXISTS ` + name + ` WITH (FORCE)`)
+			return err
+		},
+	}, nil
+}
+
+// startServer initialises a cluster in a temporary directory and starts a
+// server on it that only listens on a Unix socket in the same directory
+func startServer() (*postgres, error) {
+	bin, err := findPostgresBin()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "nubpg")
+	if err != nil {
+		return nil, err
+	}
+	data := filepath.Join(dir, "data")
+
+	initdb := exec.Command(filepath.Join(bin, "initdb"), "-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync")
+	if out, err := initdb.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		if os.Geteuid() == 0 {
+			return nil, fmt.Errorf("initdb can't run as root; set TEST_DATABASE_URL to use an existing server instead")
+		}
+		return nil, fmt.Errorf("initdb failed: %v\n%s", err, out)
+	}
+
+	options := "-c listen_addresses='' -c fsync=off -c synchronous_commit=off -c full_page_writes=off -k " + dir
+	start := exec.Command(filepath.Join(bin, "pg_ctl"), "-D", data, "-o", options, "-l", filepath.Join(dir, "server.log"), "-w", "start")
+	if out, err := start.CombinedOutput(); err != nil {
+		log, _ := os.ReadFile(filepath.Join(dir, "server.log"))
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start postgres: %v\n%s%s", err, out, log)
+	}
+
+	return &postgres{
+		dsn: "host=" + dir + " user=postgres dbname=postgres sslmode=disable",
+		stop: func() error {
+			stop := exec.Command(filepath.Join(bin, "pg_ctl"), "-D", data, "-m", "immediate", "-w", "stop")
+			out, err := stop.CombinedOutput()
+			os.RemoveAll(dir)
+			if err != nil {
+				return fmt.Errorf("failed to stop postgres: %v\n%s", err, out)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// findPostgresBin returns the directory holding initdb and pg_ctl
+func findPostgresBin() (string, error) {
+	if bin := os.Getenv("PG_BIN"); bin != "" {
+		return bin, nil
+	}
+	if path, err := exec.LookPath("initdb"); err == nil {
+		return filepath.Dir(path), nil
+	}
+
+	// Debian and Ubuntu keep the server binaries out of the PATH; prefer the
+	// newest version installed
+	versions, _ := filepath.Glob("/usr/lib/postgresql/*/bin")
+	sort.Slice(versions, func(i, j int) bool {
+		return majorVersion(versions[i]) > majorVersion(versions[j])
+	})
+	for _, dir := range append(versions, "/usr/local/pgsql/bin", "/opt/homebrew/bin") {
+		if _, err := os.Stat(filepath.Join(dir, "initdb")); err == nil {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("PostgreSQL binaries not found; install PostgreSQL, set PG_BIN, or set TEST_DATABASE_URL")
+}
+
+// majorVersion reads the version from a /usr/lib/postgresql/<version>/bin path
+func majorVersion(bin string) int {
+	version, _ := strconv.Atoi(strings.SplitN(filepath.Base(filepath.Dir(bin)), ".", 2)[0])
+	return version
+}
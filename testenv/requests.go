@@ -0,0 +1,149 @@
+package testenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nub-clubs-connect/nub_admin_api/handlers"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// Role is who a request is made as
+type Role string
+
+// Roles that requests can be made as. Each is a fixture user; Anonymous
+// sends no token.
+const (
+	Anonymous Role = ""
+	Student   Role = "student"
+	Member    Role = "member"
+	Moderator Role = "club_moderator"
+	Admin     Role = "system_admin"
+)
+
+// Response is a recorded response
+type Response struct {
+	*httptest.ResponseRecorder
+}
+
+// Envelope is the standard response body with its data left undecoded
+type Envelope struct {
+	models.APIResponse
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Do sends a request as a role. A body that isn't an io.Reader is sent as
+// JSON.
+func (e *Env) Do(t testing.TB, role Role, method, path string, body interface{}) *Response {
+	t.Helper()
+	return e.DoWithHeaders(t, role, method, path, body, nil)
+}
+
+// DoWithHeaders sends a request as a role with extra headers, for example to
+// set the Content-Type of a CSV or multipart body
+func (e *Env) DoWithHeaders(t testing.TB, role Role, method, path string, body interface{}, headers map[string]string) *Response {
+	t.Helper()
+
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if role != Anonymous {
+		req.Header.Set("Authorization", "Bearer "+e.Token(t, role))
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	e.Router.ServeHTTP(recorder, req)
+	// Emails and other work handed off by the handler are part of the
+	// request as far as a test is concerned
+	handlers.WaitForBackground()
+	return &Response{recorder}
+}
+
+// Token returns an access token for a role's fixture user, signing in the
+// first time it is needed after each Reset
+func (e *Env) Token(t testing.TB, role Role) string {
+	t.Helper()
+
+	e.mu.Lock()
+	token, ok := e.tokens[role]
+	e.mu.Unlock()
+	if ok {
+		return token
+	}
+
+	user, err := e.userFor(role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := e.Do(t, Anonymous, http.MethodPost, "/api/auth/login", models.LoginRequest{Email: user.Email, Password: Password})
+	var login struct {
+		Token string `json:"token"`
+	}
+	res.RequireStatus(t, http.StatusOK)
+	res.DecodeData(t, &login)
+
+	e.mu.Lock()
+	e.tokens[role] = login.Token
+	e.mu.Unlock()
+	return login.Token
+}
+
+// Envelope decodes the response body
+func (r *Response) Envelope(t testing.TB) Envelope {
+	t.Helper()
+	var envelope Envelope
+	if err := json.Unmarshal(r.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, r.Body.String())
+	}
+	return envelope
+}
+
+// DecodeData decodes the data of the response envelope into v
+func (r *Response) DecodeData(t testing.TB, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.Envelope(t).Data, v); err != nil {
+		t.Fatalf("failed to decode response data: %v\n%s", err, r.Body.String())
+	}
+}
+
+// RequireStatus fails the test unless the response has the status code
+func (r *Response) RequireStatus(t testing.TB, code int) {
+	t.Helper()
+	if r.Code != code {
+		t.Fatalf("expected status %d, got %d\n%s", code, r.Code, r.Body.String())
+	}
+}
+
+// RequireError fails the test unless the response is an error with the
+// status and error code
+func (r *Response) RequireError(t testing.TB, code int, errorCode string) {
+	t.Helper()
+	r.RequireStatus(t, code)
+	if got := r.Envelope(t).Error; got != errorCode {
+		t.Fatalf("expected error %q, got %q\n%s", errorCode, got, r.Body.String())
+	}
+}
@@ -0,0 +1,218 @@
+// Package testenv runs the API against a throwaway PostgreSQL database for
+// end-to-end tests. Start applies the migrations, seeds the fixtures and
+// builds the router from routes.SetupRoutes; requests are then served in
+// process, signed in as any fixture role.
+//
+//	var env *testenv.Env
+//
+//	func TestMain(m *testing.M) {
+//		var err error
+//		if env, err = testenv.Start(); err != nil {
+//			log.Fatal(err)
+//		}
+//		code := m.Run()
+//		env.Close()
+//		os.Exit(code)
+//	}
+//
+//	func TestJoinClub(t *testing.T) {
+//		env.Reset(t)
+//		res := env.Do(t, testenv.Student, "POST", fmt.Sprintf("/api/clubs/%d/join", env.Fixtures.ClubID), nil)
+//		res.RequireStatus(t, http.StatusOK)
+//	}
+//
+// Set TEST_DATABASE_URL to use an existing server, or PG_BIN to choose the
+// PostgreSQL binaries.
+package testenv
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/config"
+	"github.com/nub-clubs-connect/nub_admin_api/database"
+	"github.com/nub-clubs-connect/nub_admin_api/handlers"
+	"github.com/nub-clubs-connect/nub_admin_api/logging"
+	"github.com/nub-clubs-connect/nub_admin_api/mailer"
+	"github.com/nub-clubs-connect/nub_admin_api/realtime"
+	"github.com/nub-clubs-connect/nub_admin_api/routes"
+	"github.com/nub-clubs-connect/nub_admin_api/scheduler"
+	"github.com/nub-clubs-connect/nub_admin_api/storage"
+	"github.com/nub-clubs-connect/nub_admin_api/store"
+	"github.com/nub-clubs-connect/nub_admin_api/validation"
+)
+
+// Env is the API running against its own database
+type Env struct {
+	DB       *sql.DB
+	Router   *gin.Engine
+	Fixtures Fixtures
+
+	// Mail holds every email sent, as written by the log mailer
+	Mail *bytes.Buffer
+	// Log holds the application's log entries
+	Log *bytes.Buffer
+
+	pg      *postgres
+	tempDir string
+
+	mu     sync.Mutex
+	tokens map[Role]string
+}
+
+// Start creates the database, configures the application to use it and
+// seeds the fixtures. The application's packages keep their state in package
+// variables, so only one Env can run at a time.
+func Start() (*Env, error) {
+	pg, err := startPostgres()
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Env{pg: pg, Mail: &bytes.Buffer{}, Log: &bytes.Buffer{}, tokens: make(map[Role]string)}
+	if err := env.setup(); err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env, nil
+}
+
+func (e *Env) setup() error {
+	var err error
+	e.tempDir, err = os.MkdirTemp("", "nubtest")
+	if err != nil {
+		return err
+	}
+
+	config.AppConfig = &config.Config{
+		DatabaseURL:            e.pg.dsn,
+		GinMode:                gin.TestMode,
+		JWTSecret:              "test-secret",
+		JWTExpiration:          15 * time.Minute,
+		RefreshTokenExpiration: 720 * time.Hour,
+		AppBaseURL:             "http://localhost:3000",
+		APIBaseURL:             "http://localhost:8080",
+		MailDriver:             "log",
+		MailFrom:               "NUB Clubs Connect <no-reply@localhost>",
+		RealtimeBackend:        "memory",
+		LogLevel:               "debug",
+		LogFormat:              "text",
+		StorageDriver:          "local",
+		StorageLocalPath:       filepath.Join(e.tempDir, "uploads"),
+		MaxImageUploadBytes:    10 << 20,
+		MaxVideoUploadBytes:    100 << 20,
+	}
+
+	logger, err := logging.New(e.Log, config.AppConfig.LogLevel, config.AppConfig.LogFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	if err := database.Init(); err != nil {
+		return err
+	}
+	e.DB = database.DB
+
+	handlers.UseStores(store.New(database.DB))
+	mailer.Default = mailer.NewLogMailer(e.Mail, config.AppConfig.MailFrom)
+	if err := storage.Init(); err != nil {
+		return err
+	}
+	if err := realtime.Init(); err != nil {
+		return err
+	}
+	// Jobs are registered but not started; tests run them through the admin API
+	scheduler.Default = scheduler.New()
+	scheduler.RegisterDefaultJobs(scheduler.Default)
+
+	gin.SetMode(gin.TestMode)
+	if err := validation.Register(); err != nil {
+		return err
+	}
+	e.Router = gin.New()
+	routes.SetupRoutes(e.Router)
+
+	e.Fixtures, err = seed(e.DB)
+	return err
+}
+
+// Close stops the database and removes its files
+func (e *Env) Close() error {
+	database.Close()
+	if e.tempDir != "" {
+		os.RemoveAll(e.tempDir)
+	}
+	return e.pg.stop()
+}
+
+// Reset empties every table and seeds the fixtures again, so a test starts
+// from a known state whatever ran before it
+func (e *Env) Reset(t testing.TB) {
+	t.Helper()
+
+	_, err := e.DB.Exec(`DO $$
+		DECLARE tables TEXT;
+		BEGIN
+			SELECT string_agg(quote_ident(tablename), ', ') INTO tables
+			FROM pg_tables
+			WHERE schemaname = 'public' AND tablename <> 'schema_migrations';
+			EXECUTE 'TRUNCATE ' || tables || ' RESTART IDENTITY CASCADE';
+		END $$`)
+	if err != nil {
+		t.Fatalf("failed to empty the database: %v", err)
+	}
+
+	e.Fixtures, err = seed(e.DB)
+	if err != nil {
+		t.Fatalf("failed to seed fixtures: %v", err)
+	}
+
+	e.mu.Lock()
+	e.tokens = make(map[Role]string)
+	e.mu.Unlock()
+	e.Mail.Reset()
+	e.Log.Reset()
+}
+
+// Exec runs a statement for test setup, failing the test on error
+func (e *Env) Exec(t testing.TB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := e.DB.Exec(query, args...); err != nil {
+		t.Fatalf("%v\n%s", err, query)
+	}
+}
+
+// QueryInt runs a query returning a single integer, failing the test on
+// error
+func (e *Env) QueryInt(t testing.TB, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := e.DB.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%v\n%s", err, query)
+	}
+	return n
+}
+
+// userFor returns the fixture user signed in for a role
+func (e *Env) userFor(role Role) (FixtureUser, error) {
+	switch role {
+	case Student:
+		return e.Fixtures.Student, nil
+	case Member:
+		return e.Fixtures.Member, nil
+	case Moderator:
+		return e.Fixtures.Moderator, nil
+	case Admin:
+		return e.Fixtures.Admin, nil
+	}
+	return FixtureUser{}, fmt.Errorf("no fixture user for role %q", role)
+}
@@ -0,0 +1,24 @@
+package utils
+
+import "strings"
+
+// EmailDomainAllowed reports whether the email's domain is one of the allowed
+// domains or a subdomain of one. An empty list allows every domain.
+func EmailDomainAllowed(email string, allowedDomains []string) bool {
+	if len(allowedDomains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	for _, allowed := range allowedDomains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -10,19 +10,22 @@ import (
 )
 
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    int    `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID int64  `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int, email, role string) (string, error) {
+// GenerateToken issues a short-lived access token bound to a server-side session
+func GenerateToken(userID int, email, role string, sessionID int64) (string, error) {
 	expirationTime := time.Now().Add(config.AppConfig.JWTExpiration)
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -66,8 +69,9 @@ func ExtractUserFromToken(tokenString string) (*models.JWTClaims, error) {
 	}
 
 	return &models.JWTClaims{
-		UserID: claims.UserID,
-		Email:  claims.Email,
-		Role:   claims.Role,
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		SessionID: claims.SessionID,
 	}, nil
 }
@@ -23,14 +23,8 @@ func VerifyPassword(hashedPassword, password string) bool {
 	return err == nil
 }
 
-// GenerateResetToken generates a random token for password reset
+// GenerateResetToken generates a cryptographically random password reset
+// token. Only its HashToken digest should be persisted.
 func GenerateResetToken() (string, error) {
-	// In production, use a cryptographically secure random token generator
-	// For now, we'll use a simple approach
-	randomBytes := make([]byte, 32)
-	hashedToken, err := bcrypt.GenerateFromPassword(randomBytes, bcryptCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedToken), nil
+	return GenerateSecureToken(32)
 }
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/nub-clubs-connect/nub_admin_api/apperr"
+	"github.com/nub-clubs-connect/nub_admin_api/logging"
 	"github.com/nub-clubs-connect/nub_admin_api/models"
+	"github.com/nub-clubs-connect/nub_admin_api/validation"
 )
 
 // SuccessResponse sends a success response
@@ -14,15 +17,46 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 	})
 }
 
+// PageResponse sends a success response with one page of a list
+func PageResponse(c *gin.Context, statusCode int, message string, data interface{}, meta *models.PageMeta) {
+	c.JSON(statusCode, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
 // ErrorResponse sends an error response
 func ErrorResponse(c *gin.Context, statusCode int, message, errorCode string) {
+	FieldErrorResponse(c, statusCode, message, errorCode, nil)
+}
+
+// FieldErrorResponse sends an error response listing the fields at fault
+func FieldErrorResponse(c *gin.Context, statusCode int, message, errorCode string, details []models.FieldError) {
 	c.JSON(statusCode, models.APIResponse{
-		Success: false,
-		Message: message,
-		Error:   errorCode,
+		Success:   false,
+		Message:   message,
+		Error:     errorCode,
+		Details:   details,
+		RequestID: c.GetString("request_id"),
 	})
 }
 
+// AppErrorResponse sends the response for an error: an *apperr.Error or a
+// constraint violation gets its own status and code, and anything else is
+// logged and sent as a 500 with the given message
+func AppErrorResponse(c *gin.Context, err error, message string) {
+	appErr := apperr.From(err)
+	if appErr == nil {
+		appErr = apperr.Internal(message, err)
+	}
+	if appErr.Status >= 500 {
+		logging.From(c).Error(appErr.Message, "error", appErr.Err)
+	}
+	FieldErrorResponse(c, appErr.Status, appErr.Message, appErr.Code, appErr.Details)
+}
+
 // BadRequestResponse sends a 400 bad request response
 func BadRequestResponse(c *gin.Context, message string) {
 	ErrorResponse(c, 400, message, "bad_request")
@@ -48,7 +82,15 @@ func ConflictResponse(c *gin.Context, message string) {
 	ErrorResponse(c, 409, message, "conflict")
 }
 
-// InternalServerErrorResponse sends a 500 internal server error response
-func InternalServerErrorResponse(c *gin.Context, message string) {
+// InternalServerErrorResponse logs the error that caused a failure and sends
+// a 500 internal server error response with the given message
+func InternalServerErrorResponse(c *gin.Context, err error, message string) {
+	logging.From(c).Error(message, "error", err)
 	ErrorResponse(c, 500, message, "internal_server_error")
 }
+
+// BindErrorResponse sends the response for a request body that couldn't be
+// bound, listing the fields that failed validation
+func BindErrorResponse(c *gin.Context, err error) {
+	AppErrorResponse(c, validation.FromBind(err), "Invalid request body")
+}
@@ -0,0 +1,243 @@
+// Package validation checks request bodies as they are bound. Register adds
+// the application's rules to the validator gin uses for `binding` tags, and
+// FromBind turns a binding failure into an error listing each invalid field.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/nub-clubs-connect/nub_admin_api/apperr"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+// NewsCategories are the categories a news post can be filed under
+var NewsCategories = []string{"general", "announcement", "achievement", "event", "update", "recruitment"}
+
+// clubCodePattern is an upper case code such as NUBPC or CSE-CLUB
+var clubCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{1,19}$`)
+
+// rules are the custom tags, usable in `binding` tags like the built-in ones
+var rules = map[string]validator.Func{
+	"club_code": func(fl validator.FieldLevel) bool {
+		return clubCodePattern.MatchString(fl.Field().String())
+	},
+	"news_category": func(fl validator.FieldLevel) bool {
+		category := fl.Field().String()
+		for _, allowed := range NewsCategories {
+			if category == allowed {
+				return true
+			}
+		}
+		return false
+	},
+	"notblank": func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	},
+}
+
+// aliases name a combination of rules shared by several fields. Failures are
+// reported under the rule that failed, e.g. too_short for a short password.
+var aliases = map[string]string{
+	// bcrypt ignores everything after 72 bytes
+	"password": "min=6,max=72",
+}
+
+// Register adds the custom rules to gin's validator and makes it report
+// fields by their JSON names. It must run before requests are served.
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unexpected validator engine %T", binding.Validator.Engine())
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			return fmt.Errorf("failed to register %s rule: %w", tag, err)
+		}
+	}
+	for alias, tags := range aliases {
+		v.RegisterAlias(alias, tags)
+	}
+	return nil
+}
+
+// FromBind turns the error from binding a request body into the error to
+// respond with: a 422 listing the invalid fields when the body was well
+// formed, and a 400 when it wasn't
+func FromBind(err error) *apperr.Error {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		details := make([]models.FieldError, 0, len(invalid))
+		for _, fe := range invalid {
+			details = append(details, fieldError(fe))
+		}
+		return Invalid(details...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Invalid(models.FieldError{
+			Field:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: typeErr.Field + " must be " + article(typeName(typeErr.Type)),
+		})
+	}
+
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return apperr.New(http.StatusBadRequest, "bad_request", "Invalid request body: times must be in RFC 3339 format")
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apperr.New(http.StatusRequestEntityTooLarge, "request_too_large", "Request body is too large")
+	}
+
+	if errors.Is(err, io.EOF) {
+		return apperr.New(http.StatusBadRequest, "bad_request", "Request body is empty")
+	}
+
+	return &apperr.Error{Status: http.StatusBadRequest, Code: "bad_request", Message: "Invalid request body", Err: err}
+}
+
+// Invalid is a 422 error for the given fields, with a message naming the
+// problem when there is only one
+func Invalid(details ...models.FieldError) *apperr.Error {
+	message := "Request has invalid fields"
+	if len(details) == 1 {
+		message = details[0].Message
+	}
+	return apperr.Invalid(message, details...)
+}
+
+// Field is a 422 error for a single field, for checks made by handlers
+func Field(field, code, message string) *apperr.Error {
+	return Invalid(models.FieldError{Field: field, Code: code, Message: field + " " + message})
+}
+
+// fieldError describes a failed rule
+func fieldError(fe validator.FieldError) models.FieldError {
+	// The namespace starts with the struct's name, e.g. CreateEventRequest.title
+	field := fe.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	code, message := describe(fe)
+	return models.FieldError{Field: field, Code: code, Message: field + " " + message}
+}
+
+// describe returns the code and message for a failed rule
+func describe(fe validator.FieldError) (string, string) {
+	isString := fe.Kind() == reflect.String
+	isTime := fe.Type() == reflect.TypeOf(time.Time{}) || fe.Type() == reflect.TypeOf(&time.Time{})
+	other := otherField(fe)
+
+	switch fe.ActualTag() {
+	case "required", "notblank":
+		return "required", "is required"
+	case "email":
+		return "invalid_email", "must be a valid email address"
+	case "club_code":
+		return "invalid_club_code", "must be 2 to 20 upper case letters, digits, hyphens or underscores"
+	case "news_category":
+		return "invalid_category", "must be one of " + strings.Join(NewsCategories, ", ")
+	case "oneof":
+		return "invalid_choice", "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min", "gte":
+		if isString {
+			return "too_short", "must be at least " + fe.Param() + " characters"
+		}
+		return "too_small", "must be at least " + fe.Param()
+	case "max", "lte":
+		if isString {
+			return "too_long", "must be at most " + fe.Param() + " characters"
+		}
+		return "too_large", "must be at most " + fe.Param()
+	case "gt":
+		return "too_small", "must be greater than " + fe.Param()
+	case "lt":
+		return "too_large", "must be less than " + fe.Param()
+	case "gtfield":
+		if isTime {
+			return "too_early", "must be after " + other
+		}
+		return "too_small", "must be greater than " + other
+	case "ltefield":
+		if isTime {
+			return "too_late", "must not be after " + other
+		}
+		return "too_large", "must not be greater than " + other
+	}
+	return "invalid", "is invalid"
+}
+
+// otherField is the JSON name of the field a cross-field rule compares
+// against. The validator only reports its Go name.
+func otherField(fe validator.FieldError) string {
+	param := fe.Param()
+	if param == "" {
+		return ""
+	}
+	var name []rune
+	for i, r := range param {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				name = append(name, '_')
+			}
+			r += 'a' - 'A'
+		}
+		name = append(name, r)
+	}
+	return string(name)
+}
+
+// typeName describes a Go type in JSON terms
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// article prefixes a type name with a or an
+func article(name string) string {
+	if strings.ContainsRune("aeiou", rune(name[0])) {
+		return "an " + name
+	}
+	return "a " + name
+}
@@ -0,0 +1,263 @@
+package validation
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/nub-clubs-connect/nub_admin_api/models"
+)
+
+func TestMain(m *testing.M) {
+	if err := Register(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+const (
+	start = `"start_datetime": "2026-05-01T10:00:00Z"`
+	event = `"club_id": 1, "title": "Hackathon", "capacity": 50, ` + start
+)
+
+func TestFromBind(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  func() interface{}
+		body    string
+		status  int
+		code    string
+		message string
+		fields  map[string]string // field -> code
+	}{
+		{
+			name:    "end before start",
+			target:  func() interface{} { return &models.CreateEventRequest{} },
+			body:    `{` + event + `, "end_datetime": "2026-05-01T09:00:00Z"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "end_datetime must be after start_datetime",
+			fields:  map[string]string{"end_datetime": "too_early"},
+		},
+		{
+			name:    "deadline after start",
+			target:  func() interface{} { return &models.CreateEventRequest{} },
+			body:    `{` + event + `, "end_datetime": "2026-05-01T12:00:00Z", "registration_deadline": "2026-05-02T00:00:00Z"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "registration_deadline must not be after start_datetime",
+			fields:  map[string]string{"registration_deadline": "too_late"},
+		},
+		{
+			name:    "several invalid fields",
+			target:  func() interface{} { return &models.CreateEventRequest{} },
+			body:    `{"club_id": 1, "title": "  ", "capacity": 0, ` + start + `, "end_datetime": "2026-05-01T12:00:00Z"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "Request has invalid fields",
+			fields:  map[string]string{"title": "required", "capacity": "too_small"},
+		},
+		{
+			name:    "text too long",
+			target:  func() interface{} { return &models.CreateEventRequest{} },
+			body:    `{"club_id": 1, "title": "` + strings.Repeat("a", 256) + `", "capacity": 5, ` + start + `, "end_datetime": "2026-05-01T12:00:00Z"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "title must be at most 255 characters",
+			fields:  map[string]string{"title": "too_long"},
+		},
+		{
+			name:    "lower case club code",
+			target:  func() interface{} { return &models.CreateClubRequest{} },
+			body:    `{"club_name": "Programming Club", "club_code": "nubpc"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "club_code must be 2 to 20 upper case letters, digits, hyphens or underscores",
+			fields:  map[string]string{"club_code": "invalid_club_code"},
+		},
+		{
+			name:   "one letter club code",
+			target: func() interface{} { return &models.CreateClubRequest{} },
+			body:   `{"club_name": "Programming Club", "club_code": "P"}`,
+			status: http.StatusUnprocessableEntity,
+			fields: map[string]string{"club_code": "invalid_club_code"},
+		},
+		{
+			name:   "club code starting with a hyphen",
+			target: func() interface{} { return &models.CreateClubRequest{} },
+			body:   `{"club_name": "Programming Club", "club_code": "-PC"}`,
+			status: http.StatusUnprocessableEntity,
+			fields: map[string]string{"club_code": "invalid_club_code"},
+		},
+		{
+			name:    "invalid club email",
+			target:  func() interface{} { return &models.CreateClubRequest{} },
+			body:    `{"club_name": "Programming Club", "club_code": "NUBPC", "email": "not-an-email"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "email must be a valid email address",
+			fields:  map[string]string{"email": "invalid_email"},
+		},
+		{
+			name:    "unknown news category",
+			target:  func() interface{} { return &models.CreateNewsRequest{} },
+			body:    `{"club_id": 1, "title": "Results", "content": "We won", "category": "gossip"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "category must be one of general, announcement, achievement, event, update, recruitment",
+			fields:  map[string]string{"category": "invalid_category"},
+		},
+		{
+			name:   "unknown news category in an edit",
+			target: func() interface{} { return &models.UpdateNewsRequest{} },
+			body:   `{"category": "Achievement"}`,
+			status: http.StatusUnprocessableEntity,
+			fields: map[string]string{"category": "invalid_category"},
+		},
+		{
+			name:    "short password",
+			target:  func() interface{} { return &models.RegisterRequest{} },
+			body:    `{"student_id": "221-15-0001", "email": "a@example.com", "password": "abc", "first_name": "A", "last_name": "B"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "password must be at least 6 characters",
+			fields:  map[string]string{"password": "too_short"},
+		},
+		{
+			name:    "reset password longer than bcrypt reads",
+			target:  func() interface{} { return &models.ResetPasswordRequest{} },
+			body:    `{"token": "abc", "new_password": "` + strings.Repeat("a", 73) + `"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "new_password must be at most 72 characters",
+			fields:  map[string]string{"new_password": "too_long"},
+		},
+		{
+			name:    "wrong type",
+			target:  func() interface{} { return &models.CreateEventRequest{} },
+			body:    `{"club_id": 1, "title": "Hackathon", "capacity": "ten"}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "capacity must be an integer",
+			fields:  map[string]string{"capacity": "invalid_type"},
+		},
+		{
+			name:    "number for text",
+			target:  func() interface{} { return &models.CreateNewsRequest{} },
+			body:    `{"club_id": 1, "title": 5}`,
+			status:  http.StatusUnprocessableEntity,
+			message: "title must be a string",
+			fields:  map[string]string{"title": "invalid_type"},
+		},
+		{
+			name:    "time that isn't RFC 3339",
+			target:  func() interface{} { return &models.CreateEventRequest{} },
+			body:    `{"club_id": 1, "start_datetime": "tomorrow"}`,
+			status:  http.StatusBadRequest,
+			code:    "bad_request",
+			message: "Invalid request body: times must be in RFC 3339 format",
+		},
+		{
+			name:    "empty body",
+			target:  func() interface{} { return &models.CreateNewsRequest{} },
+			body:    ``,
+			status:  http.StatusBadRequest,
+			code:    "bad_request",
+			message: "Request body is empty",
+		},
+		{
+			name:    "malformed JSON",
+			target:  func() interface{} { return &models.CreateNewsRequest{} },
+			body:    `{"club_id": 1,`,
+			status:  http.StatusBadRequest,
+			code:    "bad_request",
+			message: "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.JSON.BindBody([]byte(tt.body), tt.target())
+			if err == nil {
+				t.Fatal("body was accepted")
+			}
+
+			got := FromBind(err)
+			if got.Status != tt.status {
+				t.Errorf("got status %d, want %d", got.Status, tt.status)
+			}
+			code := tt.code
+			if code == "" {
+				code = "validation_failed"
+			}
+			if got.Code != code {
+				t.Errorf("got code %s, want %s", got.Code, code)
+			}
+			if tt.message != "" && got.Message != tt.message {
+				t.Errorf("got message %q, want %q", got.Message, tt.message)
+			}
+
+			if len(got.Details) != len(tt.fields) {
+				t.Fatalf("got details %+v, want %v", got.Details, tt.fields)
+			}
+			for _, detail := range got.Details {
+				if want, ok := tt.fields[detail.Field]; !ok || detail.Code != want {
+					t.Errorf("got %s %s, want %v", detail.Field, detail.Code, tt.fields)
+				}
+				if !strings.HasPrefix(detail.Message, detail.Field+" ") {
+					t.Errorf("message %q doesn't name %s", detail.Message, detail.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidBodies(t *testing.T) {
+	tests := []struct {
+		name   string
+		target interface{}
+		body   string
+	}{
+		{"event", &models.CreateEventRequest{},
+			`{` + event + `, "end_datetime": "2026-05-01T12:00:00Z", "registration_deadline": "2026-05-01T10:00:00Z"}`},
+		{"club", &models.CreateClubRequest{}, `{"club_name": "CSE Club", "club_code": "CSE-CLUB", "email": "cse@example.com"}`},
+		{"club code with digits and underscores", &models.CreateClubRequest{}, `{"club_name": "CSE Club", "club_code": "CSE_2026"}`},
+		{"news", &models.CreateNewsRequest{}, `{"club_id": 1, "title": "Results", "content": "We won", "category": "achievement"}`},
+		{"news without a category", &models.CreateNewsRequest{}, `{"club_id": 1, "title": "Results", "content": "We won"}`},
+		{"news edit", &models.UpdateNewsRequest{}, `{"category": "recruitment"}`},
+		{"password reset", &models.ResetPasswordRequest{}, `{"token": "abc", "new_password": "` + strings.Repeat("a", 72) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := binding.JSON.BindBody([]byte(tt.body), tt.target); err != nil {
+				t.Errorf("body was rejected: %v", FromBind(err))
+			}
+		})
+	}
+}
+
+func TestOtherField(t *testing.T) {
+	tests := []struct {
+		param string
+		want  string
+	}{
+		{"StartDatetime", "start_datetime"},
+		{"RegistrationDeadline", "registration_deadline"},
+		{"Capacity", "capacity"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			if got := otherField(fieldErrorWithParam(tt.param)); got != tt.want {
+				t.Errorf("otherField(%q) = %q, want %q", tt.param, got, tt.want)
+			}
+		})
+	}
+}
+
+// paramError is a validator.FieldError with only a parameter
+type paramError struct {
+	validator.FieldError
+	param string
+}
+
+func (e paramError) Param() string { return e.param }
+
+func fieldErrorWithParam(param string) validator.FieldError {
+	return paramError{param: param}
+}